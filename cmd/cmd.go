@@ -24,9 +24,12 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"syscall"
 	"text/template"
 	"time"
 
@@ -83,6 +86,7 @@ type options struct {
 	prompt              bool
 	podQuery            string
 	noFollow            bool
+	previous            bool
 	resource            string
 	verbosity           int
 	onlyLogLines        bool
@@ -94,14 +98,123 @@ type options struct {
 	diffContainer       bool
 	podColors           []string
 	containerColors     []string
+	containerColorOver  map[string]string
+	severityColors      map[string]string
+	diagnosticsRate     int
+	noMatchInterval     time.Duration
+	maxResumeLines      int
+	stripTimestamps     int
+	repeatWindow        time.Duration
 
 	// OpenTelemetry options
-	otelEndpoint      string
-	otelProtocol      string
-	otelInsecure      bool
-	otelBatchSize     int
-	otelExportTimeout time.Duration
-	otelHeaders       map[string]string
+	otelEndpoint        string
+	otelProtocol        string
+	otelInsecure        bool
+	otelBatchSize       int
+	otelMaxQueueSize    int
+	otelExportInterval  time.Duration
+	otelMaxBatchBytes   int
+	otelCompression     string
+	otelExportTimeout   time.Duration
+	otelShutdownTimeout time.Duration
+	otelPreflight       time.Duration
+	otelRetryAfter      bool
+	otelRetry           bool
+	otelRetryInitial    time.Duration
+	otelRetryMax        time.Duration
+	otelRetryElapsed    time.Duration
+	otelDeadlineBudget  time.Duration
+	otelFallbackFile    string
+	otelReplayBuffer    int
+	otelFile            string
+	otelDryRun          bool
+	otelTokenFile       string
+	otelTokenRefresh    time.Duration
+	otelHeaders         map[string]string
+	otelHeadersFile     string
+	otelCACert          string
+	otelClientCert      string
+	otelClientKey       string
+	otelAggregateWindow time.Duration
+	otelAggregateBy     []string
+	otelDedupWindow     time.Duration
+	otelDedupNormalize  string
+	otelSampleRate      float64
+	otelSampleKeep      string
+	otelRecordID        bool
+	otelTaggedLogs      bool
+	otelKlog            bool
+	otelTagNames        []string
+	otelIncludeMatches  bool
+	otelPodOrderWindow  time.Duration
+	otelHeartbeat       time.Duration
+	otelQuiet           bool
+	otelMultiStart      string
+	otelMultiContinue   string
+	otelMultiTimeout    time.Duration
+	otelMultiKeepNL     bool
+	otelBestEffortRes   bool
+	otelResourceHost    bool
+	otelResourceAttrs   string
+	otelClusterUID      bool
+	otelMonotonic       bool
+	otelLineNumbers     bool
+	otelMaxJSONDepth    int
+	otelKafkaBrokers    []string
+	otelKafkaTopic      string
+	otelKafkaKey        string
+	otelKafkaEncoding   string
+	otelGELFTransport   string
+	otelGELFChunkSize   int
+	otelLabelAttributes map[string]string
+	otelAnnotAttributes map[string]string
+	otelIncludeLabels   []string
+	otelExcludeLabels   []string
+	otelIncludeAnnots   []string
+	otelExcludeAnnots   []string
+	otelNoLabels        bool
+	otelNoAnnotations   bool
+	otelRecordCluster   bool
+	otelLabelPrefix     string
+	otelAnnotPrefix     string
+	otelRedactKeys      []string
+	otelRedactSensitive bool
+	otelRedactPatterns  []string
+	otelRedactDrop      bool
+	otelBodyTimestamp   bool
+	otelTimestampFields []string
+	otelKeepTimestamp   bool
+	otelSeverityFloor   string
+	otelMinSeverity     string
+	otelDropUnleveled   bool
+	otelSeverityRules   []string
+	otelDefaultRules    []string
+	otelContainerSev    map[string]string
+	otelDefaultSev      string
+	otelEmbeddedJSON    string
+	otelIdentity        string
+	otelFieldObjects    []string
+	otelDurationFields  []string
+	otelCoerceTypes     bool
+	otelRenderMessage   string
+	otelContainerFQN    bool
+	otelContainerFQNSep string
+	otelLoggerField     string
+	otelLoggerAttribute string
+	otelLoggerService   bool
+	otelComposeService  bool
+	otelPodOrdinal      bool
+	otelContainerStatus bool
+	otelBodyHash        string
+	otelKeepRaw         bool
+	otelURLFields       []string
+	otelDropFields      []string
+	otelDropNoise       bool
+	otelMessageKeys     []string
+	otelSeverityKeys    []string
+	otelFlattenDepth    int
+	otelKeepNulls       bool
+	otelNumericLevels   string
 
 	client       kubernetes.Interface
 	clientConfig clientcmd.ClientConfig
@@ -134,12 +247,35 @@ func NewOptions(streams genericclioptions.IOStreams) *options {
 		maxLogRequests:      -1,
 		configFilePath:      defaultConfigFilePath,
 
-		otelEndpoint:      "localhost:4317",
-		otelProtocol:      "grpc",
-		otelInsecure:      true,
-		otelBatchSize:     512,
-		otelExportTimeout: 30 * time.Second,
-		otelHeaders:       make(map[string]string),
+		otelEndpoint:        "localhost:4317",
+		otelProtocol:        "grpc",
+		otelInsecure:        true,
+		otelBatchSize:       512,
+		otelCompression:     otel.CompressionNone,
+		otelExportTimeout:   30 * time.Second,
+		otelShutdownTimeout: 30 * time.Second,
+		otelRetryAfter:      true,
+		otelRetry:           true,
+		otelRetryInitial:    5 * time.Second,
+		otelRetryMax:        30 * time.Second,
+		otelRetryElapsed:    time.Minute,
+		otelTokenRefresh:    time.Minute,
+		otelMultiTimeout:    time.Second,
+		otelResourceHost:    true,
+		otelContainerFQNSep: "/",
+		otelLoggerAttribute: otel.DefaultLoggerAttribute,
+		otelLabelPrefix:     otel.DefaultLabelPrefix,
+		otelAnnotPrefix:     otel.DefaultAnnotationPrefix,
+		otelHeaders:         make(map[string]string),
+		otelMaxJSONDepth:    otel.DefaultMaxJSONDepth,
+		otelTimestampFields: otel.DefaultTimestampFields,
+		otelNumericLevels:   otel.NumericSeverityBunyan,
+		otelSampleKeep:      strings.ToLower(otel.DefaultSampleKeepSeverity),
+		otelKafkaKey:        "pod",
+		otelKafkaEncoding:   otel.KafkaEncodingOTLPJSON,
+		otelGELFTransport:   otel.GELFTransportUDP,
+		otelGELFChunkSize:   otel.DefaultGELFChunkSize,
+		otelAggregateBy:     []string{otel.AggregateByPod, otel.AggregateBySeverity},
 	}
 }
 
@@ -190,6 +326,9 @@ func (o *options) Validate() error {
 	if o.condition != "" && o.tail != 0 && !o.noFollow {
 		return errors.New("--condition is currently only supported with --tail=0 or --no-follow")
 	}
+	if o.otelMultiStart != "" && o.otelMultiContinue != "" {
+		return errors.New("--otel-multiline-start and --otel-multiline-continue cannot be set at the same time")
+	}
 
 	return nil
 }
@@ -207,6 +346,14 @@ func (o *options) Run(cmd *cobra.Command) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Stop tailing on SIGTERM, e.g. when running as a pod, so that the
+	// pending OTel records are flushed within the termination grace period
+	if config.OTelEnabled {
+		var stop context.CancelFunc
+		ctx, stop = signal.NotifyContext(ctx, syscall.SIGTERM)
+		defer stop()
+	}
+
 	if o.prompt {
 		if err := promptHandler(ctx, o.client, config, o.Out); err != nil {
 			return err
@@ -297,6 +444,11 @@ func (o *options) sternConfig() (*stern.Config, error) {
 		return nil, errors.New("color should be one of 'always', 'never', or 'auto'")
 	}
 
+	severityColors, err := stern.ParseSeverityColors(o.severityColors)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse severity colors")
+	}
+
 	template, err := o.generateTemplate()
 	if err != nil {
 		return nil, err
@@ -336,20 +488,90 @@ func (o *options) sternConfig() (*stern.Config, error) {
 	if otelEnabled {
 		ctx := context.Background()
 
+		resourceAttrs, err := otel.ParseResourceAttributes(o.otelResourceAttrs)
+		if err != nil {
+			return nil, err
+		}
+
 		// Create resource with cluster information
-		resource, err := otel.NewResource(ctx, o.clientConfig)
+		resource, err := otel.NewResourceWithConfig(ctx, o.clientConfig, &otel.ResourceConfig{
+			BestEffortDetectors: o.otelBestEffortRes,
+			DisableHostDetector: !o.otelResourceHost,
+			Client:              o.client,
+			ClusterUID:          o.otelClusterUID,
+			ServiceVersion:      version,
+			Attributes:          resourceAttrs,
+			OnDetectorError: func(err error) {
+				fmt.Fprintf(o.ErrOut, "failed to detect OTel resource attributes, skipping: %v\n", err)
+			},
+		})
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to create OTel resource")
 		}
 
+		transform, err := o.otelTransformConfig()
+		if err != nil {
+			return nil, err
+		}
+		if o.otelRecordCluster {
+			transform.ClusterName = otel.ResourceClusterName(resource)
+		}
+
 		// Create exporter configuration
 		exporterConfig := &otel.ExporterConfig{
-			Endpoint:      o.otelEndpoint,
-			Protocol:      o.otelProtocol,
-			Insecure:      o.otelInsecure,
-			BatchSize:     o.otelBatchSize,
-			ExportTimeout: o.otelExportTimeout,
-			Headers:       o.otelHeaders,
+			Endpoint:          o.otelEndpoint,
+			Protocol:          o.otelProtocol,
+			Insecure:          o.otelInsecure,
+			BatchSize:         o.otelBatchSize,
+			MaxQueueSize:      o.otelMaxQueueSize,
+			ExportInterval:    o.otelExportInterval,
+			MaxBatchBytes:     o.otelMaxBatchBytes,
+			Compression:       o.otelCompression,
+			ExportTimeout:     o.otelExportTimeout,
+			ShutdownTimeout:   o.otelShutdownTimeout,
+			PreflightTimeout:  o.otelPreflight,
+			Headers:           o.otelHeaders,
+			HeadersFile:       o.otelHeadersFile,
+			CACertFile:        o.otelCACert,
+			ClientCertFile:    o.otelClientCert,
+			ClientKeyFile:     o.otelClientKey,
+			RespectRetryAfter: o.otelRetryAfter,
+			DeadlineBudget:    o.otelDeadlineBudget,
+			FallbackFile:      o.otelFallbackFile,
+			ReplayBuffer:      o.otelReplayBuffer,
+			FilePath:          o.otelFile,
+			DryRun:            o.otelDryRun,
+			TokenFile:         o.otelTokenFile,
+			TokenRefresh:      o.otelTokenRefresh,
+			Retry: otel.RetryConfig{
+				Disabled:        !o.otelRetry,
+				InitialInterval: o.otelRetryInitial,
+				MaxInterval:     o.otelRetryMax,
+				MaxElapsedTime:  o.otelRetryElapsed,
+			},
+			Aggregate: otel.AggregateConfig{
+				Window:  o.otelAggregateWindow,
+				GroupBy: makeUnique(o.otelAggregateBy),
+			},
+			Sample: otel.SampleConfig{
+				Rate:         o.otelSampleRate,
+				KeepSeverity: o.otelSampleKeep,
+			},
+			Dedup: otel.DedupConfig{
+				Window:    o.otelDedupWindow,
+				Normalize: o.otelDedupNormalize,
+			},
+			Transform: *transform,
+			Kafka: otel.KafkaConfig{
+				Brokers:  o.otelKafkaBrokers,
+				Topic:    o.otelKafkaTopic,
+				Key:      o.otelKafkaKey,
+				Encoding: o.otelKafkaEncoding,
+			},
+			GELF: otel.GELFConfig{
+				Transport: o.otelGELFTransport,
+				ChunkSize: o.otelGELFChunkSize,
+			},
 		}
 
 		// Create the exporter
@@ -359,6 +581,33 @@ func (o *options) sternConfig() (*stern.Config, error) {
 		}
 	}
 
+	// Print the lines in the shape of their OTel records if output is "oteljson"
+	var otelJSON *otel.JSONEncoder
+	if o.output == "oteljson" {
+		transform, err := o.otelTransformConfig()
+		if err != nil {
+			return nil, err
+		}
+		otelJSON, err = otel.NewJSONEncoder(transform)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create OTel JSON encoder")
+		}
+	}
+
+	var multilineStart, multilineContinue *regexp.Regexp
+	if o.otelMultiStart != "" {
+		multilineStart, err = regexp.Compile(o.otelMultiStart)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compile regular expression for --otel-multiline-start")
+		}
+	}
+	if o.otelMultiContinue != "" {
+		multilineContinue, err = regexp.Compile(o.otelMultiContinue)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compile regular expression for --otel-multiline-continue")
+		}
+	}
+
 	return &stern.Config{
 		Namespaces:            namespaces,
 		PodQuery:              pod,
@@ -382,20 +631,137 @@ func (o *options) sternConfig() (*stern.Config, error) {
 		TailLines:             tailLines,
 		Template:              template,
 		Follow:                !o.noFollow,
+		Previous:              o.previous,
 		Resource:              o.resource,
 		OnlyLogLines:          o.onlyLogLines,
 		MaxLogRequests:        maxLogRequests,
 		Stdin:                 o.stdin,
 		DiffContainer:         o.diffContainer,
-
-		OTelEnabled:  otelEnabled,
-		OTelExporter: otelExporter,
+		SeverityColors:        severityColors,
+		DiagnosticsRate:       o.diagnosticsRate,
+		NoMatchInterval:       o.noMatchInterval,
+		MaxResumeLines:        o.maxResumeLines,
+		StripTimestamps:       o.stripTimestamps,
+		RepeatWindow:          o.repeatWindow,
+
+		OTelEnabled:     otelEnabled,
+		OTelExporter:    otelExporter,
+		OTelEmitMatches: otelEnabled && o.otelIncludeMatches,
+		OTelMonotonic:   otelEnabled && o.otelMonotonic,
+		OTelLineNumbers: otelEnabled && o.otelLineNumbers,
+		OTelOrderWindow: o.otelPodOrderWindow,
+		OTelHeartbeat:   o.otelHeartbeat,
+		OTelQuiet:       o.otelQuiet,
+		OTelJSON:        otelJSON,
+
+		OTelMultilineStart:           multilineStart,
+		OTelMultilineContinue:        multilineContinue,
+		OTelMultilineTimeout:         o.otelMultiTimeout,
+		OTelMultilinePreserveNewline: o.otelMultiKeepNL,
 
 		Out:    o.Out,
 		ErrOut: o.ErrOut,
 	}, nil
 }
 
+// otelTransformConfig returns the configuration shaping the OTel records
+func (o *options) otelTransformConfig() (*otel.TransformConfig, error) {
+	identity, err := otel.ResolveIdentity(o.otelIdentity, o.clientConfig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve OTel identity")
+	}
+
+	severityRules, err := otel.ParseSeverityRules(o.otelSeverityRules)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultSeverityRules, err := otel.ParseSeverityRules(o.otelDefaultRules)
+	if err != nil {
+		return nil, err
+	}
+
+	redactPatterns, err := compileREs(o.otelRedactPatterns)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compile regular expression for redaction")
+	}
+
+	redactKeys := o.otelRedactKeys
+	if o.otelRedactSensitive {
+		redactKeys = append(slices.Clip(redactKeys), otel.SensitiveKeys...)
+	}
+
+	var timestampFields []string
+	if o.otelBodyTimestamp {
+		timestampFields = o.otelTimestampFields
+	}
+
+	dropFields := o.otelDropFields
+	if o.otelDropNoise {
+		dropFields = append(slices.Clip(dropFields), otel.NoiseFields...)
+	}
+
+	var containerFQNSep string
+	if o.otelContainerFQN {
+		if o.otelContainerFQNSep == "" {
+			return nil, errors.New("--otel-container-fqn-separator must not be empty")
+		}
+		containerFQNSep = o.otelContainerFQNSep
+	}
+
+	return &otel.TransformConfig{
+		RecordID:              o.otelRecordID,
+		TaggedLogs:            o.otelTaggedLogs,
+		TagNames:              o.otelTagNames,
+		Klog:                  o.otelKlog,
+		MaxJSONDepth:          o.otelMaxJSONDepth,
+		LabelAttributes:       o.otelLabelAttributes,
+		AnnotationAttributes:  o.otelAnnotAttributes,
+		IncludeLabels:         o.otelIncludeLabels,
+		ExcludeLabels:         o.otelExcludeLabels,
+		IncludeAnnotations:    o.otelIncludeAnnots,
+		ExcludeAnnotations:    o.otelExcludeAnnots,
+		NoLabels:              o.otelNoLabels,
+		NoAnnotations:         o.otelNoAnnotations,
+		LabelPrefix:           &o.otelLabelPrefix,
+		AnnotationPrefix:      &o.otelAnnotPrefix,
+		RedactKeys:            redactKeys,
+		RedactPatterns:        redactPatterns,
+		RedactDrop:            o.otelRedactDrop,
+		TimestampFields:       timestampFields,
+		KeepTimestampField:    o.otelKeepTimestamp,
+		SeverityFloor:         o.otelSeverityFloor,
+		MinSeverity:           o.otelMinSeverity,
+		DropUnleveled:         o.otelDropUnleveled,
+		Identity:              identity,
+		FieldObjects:          o.otelFieldObjects,
+		SeverityRules:         severityRules,
+		DefaultSeverityRules:  defaultSeverityRules,
+		ContainerSeverities:   o.otelContainerSev,
+		DefaultSeverity:       o.otelDefaultSev,
+		EmbeddedJSON:          o.otelEmbeddedJSON,
+		DurationFields:        o.otelDurationFields,
+		CoerceTypes:           o.otelCoerceTypes,
+		RenderMessage:         o.otelRenderMessage,
+		ContainerFQNSeparator: containerFQNSep,
+		LoggerField:           o.otelLoggerField,
+		LoggerAttribute:       o.otelLoggerAttribute,
+		LoggerServiceName:     o.otelLoggerService,
+		ComposeServiceName:    o.otelComposeService,
+		StatefulSetOrdinal:    o.otelPodOrdinal,
+		ContainerStatus:       o.otelContainerStatus,
+		BodyHash:              o.otelBodyHash,
+		KeepRaw:               o.otelKeepRaw,
+		URLFields:             o.otelURLFields,
+		DropFields:            dropFields,
+		MessageKeys:           o.otelMessageKeys,
+		SeverityKeys:          o.otelSeverityKeys,
+		FlattenDepth:          o.otelFlattenDepth,
+		KeepNulls:             o.otelKeepNulls,
+		NumericSeverity:       o.otelNumericLevels,
+	}, nil
+}
+
 // setVerbosity sets the log level verbosity
 func (o *options) setVerbosity() error {
 	// Initialize klog flags
@@ -420,7 +786,12 @@ func (o *options) setVerbosity() error {
 
 func (o *options) setColorList() error {
 	if len(o.podColors) > 0 || len(o.containerColors) > 0 {
-		return stern.SetColorList(o.podColors, o.containerColors)
+		if err := stern.SetColorList(o.podColors, o.containerColors); err != nil {
+			return err
+		}
+	}
+	if len(o.containerColorOver) > 0 {
+		return stern.SetContainerColorOverrides(o.containerColorOver)
 	}
 	return nil
 }
@@ -501,6 +872,7 @@ func (o *options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringArrayVar(&o.excludePod, "exclude-pod", o.excludePod, "Pod name to exclude. (regular expression)")
 	fs.StringVar(&o.condition, "condition", o.condition, "The condition to filter on: [condition-name[=condition-value]. The default condition-value is true. Match is case-insensitive. Currently only supported with --tail=0 or --no-follow.")
 	fs.BoolVar(&o.noFollow, "no-follow", o.noFollow, "Exit when all logs have been shown.")
+	fs.BoolVar(&o.previous, "previous", o.previous, "Print the logs of the previous instance of the containers, e.g. to debug a crash loop.")
 	fs.StringArrayVarP(&o.include, "include", "i", o.include, "Log lines to include. (regular expression)")
 	fs.StringArrayVarP(&o.highlight, "highlight", "H", o.highlight, "Log lines to highlight. (regular expression)")
 	fs.BoolVar(&o.initContainers, "init-containers", o.initContainers, "Include or exclude init containers.")
@@ -508,7 +880,7 @@ func (o *options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringSliceVarP(&o.namespaces, "namespace", "n", o.namespaces, "Kubernetes namespace to use. Default to namespace configured in kubernetes context. To specify multiple namespaces, repeat this or set comma-separated value.")
 	fs.StringVar(&o.node, "node", o.node, "Node name to filter on.")
 	fs.IntVar(&o.maxLogRequests, "max-log-requests", o.maxLogRequests, "Maximum number of concurrent logs to request. Defaults to 50, but 5 when specifying --no-follow")
-	fs.StringVarP(&o.output, "output", "o", o.output, "Specify predefined template. Currently support: [default, raw, json, extjson, ppextjson, otel]")
+	fs.StringVarP(&o.output, "output", "o", o.output, "Specify predefined template. Currently support: [default, raw, json, extjson, ppextjson, otel, oteljson]")
 	fs.BoolVarP(&o.prompt, "prompt", "p", o.prompt, "Toggle interactive prompt for selecting 'app.kubernetes.io/instance' label values.")
 	fs.StringVarP(&o.selector, "selector", "l", o.selector, "Selector (label query) to filter on. If present, default to \".*\" for the pod-query.")
 	fs.StringVar(&o.fieldSelector, "field-selector", o.fieldSelector, "Selector (field query) to filter on. If present, default to \".*\" for the pod-query.")
@@ -517,23 +889,132 @@ func (o *options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.template, "template", o.template, "Template to use for log lines, leave empty to use --output flag.")
 	fs.StringVarP(&o.templateFile, "template-file", "T", o.templateFile, "Path to template to use for log lines, leave empty to use --output flag. It overrides --template option.")
 	fs.StringVarP(&o.timestamps, "timestamps", "t", o.timestamps, "Print timestamps with the specified format. One of 'default' or 'short' in the form '--timestamps=format' ('=' cannot be omitted). If specified but without value, 'default' is used.")
-	fs.StringVar(&o.timezone, "timezone", o.timezone, "Set timestamps to specific timezone.")
+	fs.StringVar(&o.timezone, "timezone", o.timezone, "Set timestamps to specific timezone, including the ones of the OTel records.")
 	fs.BoolVar(&o.onlyLogLines, "only-log-lines", o.onlyLogLines, "Print only log lines")
 	fs.StringVar(&o.configFilePath, "config", o.configFilePath, "Path to the stern config file")
 	fs.IntVar(&o.verbosity, "verbosity", o.verbosity, "Number of the log level verbosity")
 	fs.BoolVarP(&o.version, "version", "v", o.version, "Print the version and exit.")
 	fs.BoolVar(&o.showHiddenOptions, "show-hidden-options", o.showHiddenOptions, "Print a list of hidden options.")
 	fs.BoolVar(&o.stdin, "stdin", o.stdin, "Parse logs from stdin. All Kubernetes related flags are ignored when it is set.")
+	fs.IntVar(&o.stripTimestamps, "strip-timestamps", o.stripTimestamps, "Number of RFC3339 timestamps to remove from the start of the log lines after the kubelet one, e.g. 1 for a sidecar prepending its own. A token is only removed when it parses as a timestamp.")
+	fs.DurationVar(&o.repeatWindow, "repeat-window", o.repeatWindow, "Collapse the repeats of a line of a container within this window after its first occurrence into a \"(last message repeated N times)\" line, or an OTel record with the log.repeat_count attribute. 0 disables it.")
+	fs.IntVar(&o.maxResumeLines, "max-resume-lines", o.maxResumeLines, "Maximum number of lines of the same second counted to skip the lines already seen when resuming a tail. Beyond it, the resume is keyed by the nanosecond timestamp of the last line. 0 means no limit.")
+	fs.DurationVar(&o.noMatchInterval, "no-match-interval", o.noMatchInterval, "Report to stderr when lines were read but none matched the filters during this interval, to tell a broken filter from a silent application. 0 disables the report.")
+	fs.IntVar(&o.diagnosticsRate, "diagnostics-rate", o.diagnosticsRate, "Maximum number of diagnostic messages, such as template errors, written to stderr per second. Suppressed messages are counted in a summary. 0 means unlimited.")
 	fs.BoolVarP(&o.diffContainer, "diff-container", "d", o.diffContainer, "Display different colors for different containers.")
 	fs.StringSliceVar(&o.podColors, "pod-colors", o.podColors, "Specifies the colors used to highlight pod names. Provide colors as a comma-separated list using SGR (Select Graphic Rendition) sequences, e.g., \"91,92,93,94,95,96\".")
+	fs.StringToStringVar(&o.severityColors, "severity-colors", o.severityColors, "Colors whole log lines by the level of structured logs. Provide level=SGR sequence pairs, e.g., \"error=31,warn=33\". Levels: trace, debug, info, warn, error, fatal.")
+	fs.StringToStringVar(&o.containerColorOver, "container-color-overrides", o.containerColorOver, "Colors containers whose name matches a regular expression with a fixed color, overriding the hash-based colors. Provide pattern=SGR sequence pairs, e.g., \"istio-proxy=2\" to dim a sidecar.")
 	fs.StringSliceVar(&o.containerColors, "container-colors", o.containerColors, "Specifies the colors used to highlight container names. Use the same format as --pod-colors. Defaults to the values of --pod-colors if omitted, and must match its length.")
 
 	// OpenTelemetry flags (used when --output=otel)
-	fs.StringVar(&o.otelEndpoint, "otel-endpoint", o.otelEndpoint, "OpenTelemetry collector endpoint (e.g., localhost:4317 for gRPC, localhost:4318 for HTTP). Used with --output=otel")
-	fs.StringVar(&o.otelProtocol, "otel-protocol", o.otelProtocol, "OpenTelemetry protocol to use: 'grpc' or 'http'. Used with --output=otel")
+	fs.StringVar(&o.otelEndpoint, "otel-endpoint", o.otelEndpoint, "OpenTelemetry collector endpoint (e.g., localhost:4317 for gRPC, localhost:4318 for HTTP), or its URL, e.g. https://otlp.example.com:4318/v1/logs, whose scheme overrides --otel-insecure. Used with --output=otel")
+	fs.StringVar(&o.otelProtocol, "otel-protocol", o.otelProtocol, "OpenTelemetry protocol to use: 'grpc', 'http', 'kafka', 'gelf' to send GELF messages, e.g. to Graylog, 'file' to append the records to --otel-file, or 'stdout' to print the records to stderr instead of exporting them. Used with --output=otel")
+	fs.BoolVar(&o.otelDryRun, "otel-dry-run", o.otelDryRun, "Print the OpenTelemetry records to stderr as indented OTLP/JSON, after the resource attributes, instead of exporting them. The endpoint is never contacted, whatever the protocol. Used with --output=otel")
+	fs.StringVar(&o.otelFile, "otel-file", o.otelFile, "File the records are appended to as newline-delimited OTLP/JSON, e.g. to upload them later from an air-gapped cluster. Used with --otel-protocol=file")
+	fs.StringSliceVar(&o.otelKafkaBrokers, "otel-kafka-brokers", o.otelKafkaBrokers, "Kafka bootstrap brokers. Defaults to --otel-endpoint. Used with --otel-protocol=kafka")
+	fs.StringVar(&o.otelKafkaTopic, "otel-kafka-topic", o.otelKafkaTopic, "Kafka topic receiving one message per log record. Used with --otel-protocol=kafka")
+	fs.StringVar(&o.otelKafkaKey, "otel-kafka-key", o.otelKafkaKey, "Record attribute used as the Kafka message (partition) key: namespace, pod, container, or any attribute name. Used with --otel-protocol=kafka")
+	fs.StringVar(&o.otelKafkaEncoding, "otel-kafka-encoding", o.otelKafkaEncoding, "Kafka message encoding: 'otlp_json' or 'raw' (the log body only). Used with --otel-protocol=kafka")
+	fs.StringVar(&o.otelGELFTransport, "otel-gelf-transport", o.otelGELFTransport, "GELF transport: 'udp', chunking the large messages, or 'tcp'. Used with --otel-protocol=gelf")
+	fs.IntVar(&o.otelGELFChunkSize, "otel-gelf-chunk-size", o.otelGELFChunkSize, "Size of the UDP datagrams beyond which GELF messages are chunked. Used with --otel-protocol=gelf and --otel-gelf-transport=udp")
 	fs.BoolVar(&o.otelInsecure, "otel-insecure", o.otelInsecure, "Use insecure connection to OpenTelemetry collector (no TLS). Used with --output=otel")
+	fs.StringVar(&o.otelCACert, "otel-ca-cert", o.otelCACert, "PEM file of the certificate authorities verifying the OpenTelemetry collector. Enables TLS regardless of --otel-insecure. Used with --output=otel")
+	fs.StringVar(&o.otelClientCert, "otel-client-cert", o.otelClientCert, "PEM file of the client certificate authenticating to an OpenTelemetry collector requiring mutual TLS. Enables TLS regardless of --otel-insecure. Used with --otel-client-key")
+	fs.StringVar(&o.otelClientKey, "otel-client-key", o.otelClientKey, "PEM file of the key of --otel-client-cert. Used with --otel-client-cert")
 	fs.IntVar(&o.otelBatchSize, "otel-batch-size", o.otelBatchSize, "Maximum batch size for OpenTelemetry log export. Used with --output=otel")
+	fs.IntVar(&o.otelMaxQueueSize, "otel-max-queue-size", o.otelMaxQueueSize, "Maximum number of records waiting to be exported, beyond which new records are dropped. 0 means twice --otel-batch-size. Used with --output=otel")
+	fs.DurationVar(&o.otelExportInterval, "otel-export-interval", o.otelExportInterval, "Delay after which a partial batch is exported. 0 means the OpenTelemetry SDK default of 1s. Used with --output=otel")
+	fs.StringVar(&o.otelCompression, "otel-compression", o.otelCompression, "Compression of the exported OpenTelemetry payloads: 'gzip' or 'none'. Used with --output=otel")
+	fs.IntVar(&o.otelMaxBatchBytes, "otel-max-batch-bytes", o.otelMaxBatchBytes, "Split OpenTelemetry export batches larger than this many bytes, e.g. to stay under the payload limit of the collector. 0 disables the limit. Used with --output=otel")
 	fs.DurationVar(&o.otelExportTimeout, "otel-export-timeout", o.otelExportTimeout, "Timeout for OpenTelemetry export operations. Used with --output=otel")
+	fs.DurationVar(&o.otelPreflight, "otel-preflight-timeout", o.otelPreflight, "Maximum time to wait for the OpenTelemetry endpoint to accept a connection at startup, failing fast when it is unreachable. The endpoint is dialed directly, ignoring HTTPS_PROXY. Zero, the default, skips the check. Used with --output=otel and the grpc or http protocols")
+	fs.DurationVar(&o.otelShutdownTimeout, "otel-shutdown-timeout", o.otelShutdownTimeout, "Maximum time to wait for pending OpenTelemetry logs to be flushed on exit. Used with --output=otel")
+	fs.DurationVar(&o.otelDeadlineBudget, "otel-deadline-budget", o.otelDeadlineBudget, "Give each export only until its oldest record has waited this long since it was read, so that stale batches fail fast instead of waiting for --otel-export-timeout. 0 disables the per-batch deadline. Used with --output=otel")
+	fs.StringVar(&o.otelHeadersFile, "otel-headers-file", o.otelHeadersFile, "File of \"key: value\" headers of the exports, one per line, e.g. credentials kept out of the process arguments. Keep it readable by its owner only (0600). Used with --otel-protocol=grpc or http")
+	fs.StringVar(&o.otelTokenFile, "otel-token-file", o.otelTokenFile, "File holding the bearer token of the Authorization header of the exports, e.g. a projected service account token, read again every --otel-token-refresh. Used with --otel-protocol=grpc or http")
+	fs.DurationVar(&o.otelTokenRefresh, "otel-token-refresh", o.otelTokenRefresh, "Interval after which --otel-token-file is read again, to pick up rotated tokens. Used with --otel-token-file")
+	fs.StringVar(&o.otelFallbackFile, "otel-fallback-file", o.otelFallbackFile, "Append the records the collector fails to export to this file as newline-delimited OTLP/JSON. Used with --output=otel")
+	fs.IntVar(&o.otelReplayBuffer, "otel-replay-buffer", o.otelReplayBuffer, "Keep up to this many records that failed to export in memory and replay them once the collector recovers. 0 disables the buffer. Used with --output=otel")
+	fs.BoolVar(&o.otelRetry, "otel-retry", o.otelRetry, "Retry the OpenTelemetry exports failing with a transient error, e.g. while the collector restarts. Used with --otel-protocol=grpc or http")
+	fs.DurationVar(&o.otelRetryInitial, "otel-retry-initial-interval", o.otelRetryInitial, "Wait before the first retry of a failed export, doubled after each attempt. Used with --otel-retry")
+	fs.DurationVar(&o.otelRetryMax, "otel-retry-max-interval", o.otelRetryMax, "Maximum wait between the retries of a failed export. Used with --otel-retry")
+	fs.DurationVar(&o.otelRetryElapsed, "otel-retry-max-elapsed-time", o.otelRetryElapsed, "Time after which a failed export is no longer retried and its records are dropped. Used with --otel-retry")
+	fs.BoolVar(&o.otelRetryAfter, "otel-respect-retry-after", o.otelRetryAfter, "Wait for the delay of the Retry-After header when the collector throttles HTTP exports, instead of the exponential backoff. Used with --otel-protocol=http")
+	fs.DurationVar(&o.otelAggregateWindow, "otel-aggregate-window", o.otelAggregateWindow, "Emit a summary record with the number of lines per group every window instead of individual records. 0 disables aggregation. Used with --output=otel")
+	fs.StringSliceVar(&o.otelAggregateBy, "otel-aggregate-by", o.otelAggregateBy, "Properties to group aggregated lines by: namespace, pod, container, severity, or prefix (message prefix). Used with --otel-aggregate-window")
+	fs.Float64Var(&o.otelSampleRate, "otel-sample-rate", o.otelSampleRate, "Fraction of the records below --otel-sample-keep-severity to emit, e.g. 0.1, while all the others are kept. Records without a level are sampled too. 0 or 1 disables sampling. Used with --output=otel")
+	fs.StringVar(&o.otelSampleKeep, "otel-sample-keep-severity", o.otelSampleKeep, "Lowest severity never sampled, compared to the severity of the record after --otel-severity-keys, --otel-severity-floor and --otel-severity-rule. Used with --otel-sample-rate")
+	fs.DurationVar(&o.otelDedupWindow, "otel-dedup-window", o.otelDedupWindow, "Emit only the first occurrence of each error signature per window, followed by a record counting the suppressed repeats. 0 disables deduplication. Used with --output=otel")
+	fs.StringVar(&o.otelDedupNormalize, "otel-dedup-normalize", o.otelDedupNormalize, "Regular expression matching the variable tokens removed from error messages to compute their signature. Defaults to timestamps, UUIDs, hex identifiers and numbers. Used with --otel-dedup-window")
+	fs.StringVar(&o.otelEmbeddedJSON, "otel-embedded-json", o.otelEmbeddedJSON, "Parse a JSON object ending a text line, e.g. 'handler: {\"user\":\"alice\"}', into attributes. The body is the text prefix with 'prefix', or the message of the object with 'message'. Used with --output=otel")
+	fs.BoolVar(&o.otelTaggedLogs, "otel-tagged-logs", o.otelTaggedLogs, "Parse leading bracket tags of non-JSON lines, e.g. Rails' \"[request-id] [tenant] message\", into attributes. Used with --output=otel")
+	fs.StringSliceVar(&o.otelTagNames, "otel-tag-names", o.otelTagNames, "Attribute names for the bracket tags by position, e.g. \"request_id,tenant\". Unnamed tags use tag.<index>. Used with --otel-tagged-logs")
+	fs.BoolVar(&o.otelKlog, "otel-klog", o.otelKlog, "Parse the klog lines of non-JSON and non-logfmt logs, e.g. \"I0101 12:00:00.000000 1 server.go:10] message\" from the Kubernetes components, into their severity, caller and message. Used with --output=otel")
+	fs.StringVar(&o.otelLoggerField, "otel-logger-field", o.otelLoggerField, "Field of JSON logs holding the logger name, e.g. \"logger\", moved to the --otel-logger-attribute attribute. Used with --output=otel")
+	fs.StringVar(&o.otelLoggerAttribute, "otel-logger-attribute", o.otelLoggerAttribute, "Attribute of the logger name, e.g. \"code.namespace\". Used with --otel-logger-field")
+	fs.BoolVar(&o.otelLoggerService, "otel-logger-service-name", o.otelLoggerService, "Use the last segment of the logger name as service.name, e.g. \"boho-api\" for \"statler.server.boho-api\". Used with --otel-logger-field")
+	fs.BoolVar(&o.otelComposeService, "otel-compose-service-name", o.otelComposeService, "Name the service after the app.kubernetes.io/name (or instance) and component labels, e.g. \"postgresql/primary\", and add service.namespace and service.instance.id from the namespace and pod. Used with --output=otel")
+	fs.StringSliceVar(&o.otelFieldObjects, "otel-field-objects", o.otelFieldObjects, "Sub-objects of JSON logs whose fields are emitted as top-level attributes instead of one JSON attribute, e.g. \"extra,attributes\" for structlog. Used with --output=otel")
+	fs.StringVar(&o.otelRenderMessage, "otel-render-message", o.otelRenderMessage, "Render a message field of JSON logs that is not a string to the body instead of sending the whole JSON: 'json', or 'template' to fill the placeholders of {\"template\":...,\"args\":[...]}. Used with --output=otel")
+	fs.StringSliceVar(&o.otelMessageKeys, "otel-message-keys", o.otelMessageKeys, "Fields of structured logs holding the message, e.g. \"event,@message\", tried in order before "+strings.Join(otel.DefaultMessageKeys, ", ")+". Used with --output=otel")
+	fs.StringSliceVar(&o.otelSeverityKeys, "otel-severity-keys", o.otelSeverityKeys, "Fields of structured logs holding the level, e.g. \"log.level\", tried in order before "+strings.Join(otel.DefaultSeverityKeys, ", ")+". Used with --output=otel")
+	fs.StringVar(&o.otelNumericLevels, "otel-numeric-severity", o.otelNumericLevels, "Scale of numeric levels of structured logs: 'bunyan' (10 trace to 60 fatal, also used by Pino) or 'syslog' (0 emergency to 7 debug). Used with --output=otel")
+	fs.StringSliceVar(&o.otelRedactKeys, "otel-redact-keys", o.otelRedactKeys, "Fields of structured logs, compared ignoring case, whose values are replaced by *** before export, e.g. \"password,email\". Used with --output=otel")
+	fs.BoolVar(&o.otelRedactSensitive, "otel-redact-sensitive-fields", o.otelRedactSensitive, "Redact the common fields holding credentials: "+strings.Join(otel.SensitiveKeys, ", ")+". Used with --output=otel")
+	fs.StringArrayVar(&o.otelRedactPatterns, "otel-redact-pattern", o.otelRedactPatterns, "Regular expression of the text replaced by *** in the body and the fields before export, e.g. email addresses. Can be repeated. Used with --output=otel")
+	fs.BoolVar(&o.otelRedactDrop, "otel-redact-drop", o.otelRedactDrop, "Drop the fields of --otel-redact-keys instead of replacing their values. Used with --output=otel")
+	fs.BoolVar(&o.otelBodyTimestamp, "otel-body-timestamp", o.otelBodyTimestamp, "Use the time of structured logs as the record timestamp instead of the container time, which becomes the observed timestamp. RFC 3339 strings and epoch seconds or milliseconds are supported. Used with --output=otel")
+	fs.StringSliceVar(&o.otelTimestampFields, "otel-timestamp-fields", o.otelTimestampFields, "Fields of structured logs holding the time, tried in order. Used with --otel-body-timestamp")
+	fs.BoolVar(&o.otelKeepTimestamp, "otel-keep-timestamp-field", o.otelKeepTimestamp, "Keep the field of the record timestamp in the attributes instead of removing it. Used with --otel-body-timestamp")
+	fs.StringSliceVar(&o.otelDropFields, "otel-drop-fields", o.otelDropFields, "Fields of JSON logs left out of the attributes, e.g. \"pid,hostname\". Used with --output=otel")
+	fs.BoolVar(&o.otelDropNoise, "otel-drop-noise-fields", o.otelDropNoise, "Leave out the fields of common loggers redundant with the Kubernetes attributes: "+strings.Join(otel.NoiseFields, ", ")+". Used with --output=otel")
+	fs.StringSliceVar(&o.otelURLFields, "otel-url-fields", o.otelURLFields, "Fields of JSON logs holding a URL, e.g. \"url,request_uri\", whose query parameters are added as query.<name> attributes. Used with --output=otel")
+	fs.StringSliceVar(&o.otelDurationFields, "otel-duration-fields", o.otelDurationFields, "Fields of JSON logs holding a duration with a unit, e.g. \"duration,latency\", normalized to a duration.ms attribute in milliseconds. Values such as 15ms, 1.2s or 1500000ns are supported. Used with --output=otel")
+	fs.BoolVar(&o.otelCoerceTypes, "otel-coerce-types", o.otelCoerceTypes, "Add a numeric attribute next to the fields of JSON logs holding a Go duration or an RFC3339 timestamp, e.g. latency_ns=1200000000 for \"latency\":\"1.2s\" and deadline_epoch_ms for \"deadline\":\"2025-01-01T00:00:00Z\". Used with --output=otel")
+	fs.BoolVar(&o.otelKeepNulls, "otel-keep-nulls", o.otelKeepNulls, "Add the null fields of JSON logs as empty attribute values instead of leaving them out. Used with --output=otel")
+	fs.IntVar(&o.otelFlattenDepth, "otel-flatten-depth", o.otelFlattenDepth, "Flatten up to this many levels of the objects and arrays of JSON logs into attributes with dotted keys, e.g. resource.service.name or tags.0, instead of JSON strings. 0 disables flattening. Used with --output=otel")
+	fs.IntVar(&o.otelMaxJSONDepth, "otel-max-json-depth", o.otelMaxJSONDepth, "Maximum nesting depth of JSON logs. Deeper lines are sent as a plain body marked with stern.parse_depth_exceeded. Used with --output=otel")
+	fs.StringSliceVar(&o.otelIncludeLabels, "otel-include-labels", o.otelIncludeLabels, "Patterns of the pod labels added as attributes, e.g. \"app.kubernetes.io/*,tier\", where * matches any text. Defaults to all labels. Used with --output=otel")
+	fs.StringSliceVar(&o.otelExcludeLabels, "otel-exclude-labels", o.otelExcludeLabels, "Patterns of the pod labels left out of the attributes, e.g. \"*-hash,argocd*\". Used with --output=otel")
+	fs.StringSliceVar(&o.otelIncludeAnnots, "otel-include-annotations", o.otelIncludeAnnots, "Patterns of the pod annotations added as attributes, e.g. \"prometheus.io/*\". Defaults to all annotations. Used with --output=otel")
+	fs.StringSliceVar(&o.otelExcludeAnnots, "otel-exclude-annotations", o.otelExcludeAnnots, "Patterns of the pod annotations left out of the attributes, e.g. \"kubectl.kubernetes.io/*\". Used with --output=otel")
+	fs.BoolVar(&o.otelNoLabels, "otel-no-labels", o.otelNoLabels, "Leave all the pod labels out of the attributes, but the ones of --otel-label-attributes. Used with --output=otel")
+	fs.BoolVar(&o.otelNoAnnotations, "otel-no-annotations", o.otelNoAnnotations, "Leave all the pod annotations out of the attributes, e.g. large last-applied configurations inflating every record, but the ones of --otel-annotation-attributes. Used with --output=otel")
+	fs.StringVar(&o.otelLabelPrefix, "otel-label-prefix", o.otelLabelPrefix, "Prefix of the pod label attributes, e.g. \"kube.label.\". An empty prefix keeps the raw label keys. Used with --output=otel")
+	fs.StringVar(&o.otelAnnotPrefix, "otel-annotation-prefix", o.otelAnnotPrefix, "Prefix of the pod annotation attributes, e.g. \"kube.annotation.\". An empty prefix keeps the raw annotation keys. Used with --output=otel")
+	fs.StringToStringVar(&o.otelLabelAttributes, "otel-label-attributes", o.otelLabelAttributes, "Promote pod labels to attributes with the given names instead of k8s.pod.label.<key>, e.g. \"team=service.team\". Used with --output=otel")
+	fs.StringToStringVar(&o.otelAnnotAttributes, "otel-annotation-attributes", o.otelAnnotAttributes, "Promote pod annotations to attributes with the given names instead of k8s.pod.annotation.<key>, e.g. \"example.com/tenant=tenant.id\" for the collector to route the records. Used with --output=otel")
+	fs.StringVar(&o.otelSeverityFloor, "otel-severity-floor", o.otelSeverityFloor, "Raise the severity of structured logs below this level to it instead of dropping them, e.g. \"info\" reports debug and trace logs as info. Used with --output=otel")
+	fs.StringVar(&o.otelMinSeverity, "otel-min-severity", o.otelMinSeverity, "Export only the records of this severity or above, e.g. \"warn\", after --otel-severity-rule and --otel-severity-floor. Records without a severity are kept unless --otel-drop-unleveled is set. Used with --output=otel")
+	fs.BoolVar(&o.otelDropUnleveled, "otel-drop-unleveled", o.otelDropUnleveled, "Skip the records without a severity, e.g. plain text, when --otel-min-severity is set. Used with --output=otel")
+	fs.StringArrayVar(&o.otelSeverityRules, "otel-severity-rule", o.otelSeverityRules, "Override the severity of the log lines matching a regular expression, e.g. \"panic=fatal\". Can be repeated; the first matching rule wins. Used with --output=otel")
+	fs.StringArrayVar(&o.otelDefaultRules, "otel-default-severity-rule", o.otelDefaultRules, "Set the severity of the log lines without a level matching a regular expression, e.g. \"ERROR=error\". Can be repeated; the first matching rule wins. Used with --output=otel")
+	fs.StringToStringVar(&o.otelContainerSev, "otel-container-severity", o.otelContainerSev, "Severity of the log lines without a level of the given containers, e.g. \"legacy=info\". Used with --output=otel")
+	fs.StringVar(&o.otelDefaultSev, "otel-default-severity", o.otelDefaultSev, "Severity of the log lines without a level of the other containers. Used with --output=otel")
+	fs.StringVar(&o.otelIdentity, "otel-identity", o.otelIdentity, "Set the stern.identity attribute recording who configured the tail: a literal value, \"env:<NAME>\" to read an environment variable, or \"kubeconfig\" for the user of the current context. Used with --output=otel")
+	fs.BoolVar(&o.otelContainerFQN, "otel-container-fqn", o.otelContainerFQN, "Add a k8s.container.fqn attribute joining the namespace, pod and container, e.g. as a single key for joins. Used with --output=otel")
+	fs.StringVar(&o.otelContainerFQNSep, "otel-container-fqn-separator", o.otelContainerFQNSep, "Separator of the parts of k8s.container.fqn. Used with --otel-container-fqn")
+	fs.BoolVar(&o.otelPodOrdinal, "otel-statefulset-ordinal", o.otelPodOrdinal, "Add the ordinal of the pods of StatefulSets, e.g. 1 for web-1, as the k8s.statefulset.pod_ordinal attribute. Used with --output=otel")
+	fs.BoolVar(&o.otelContainerStatus, "otel-container-status", o.otelContainerStatus, "Add the image and the restart count of the container as the container.image.name, container.image.tags, container.image.id and k8s.container.restart_count attributes. Used with --output=otel")
+	fs.BoolVar(&o.otelIncludeMatches, "otel-include-matches", o.otelIncludeMatches, "Attach the substrings matched by --include as the stern.matches attribute. Used with --output=otel")
+	fs.BoolVar(&o.otelBestEffortRes, "otel-best-effort-resource", o.otelBestEffortRes, "Skip host and runtime resource detectors that fail instead of exiting, e.g. in restricted environments. Used with --output=otel")
+	fs.StringVar(&o.otelResourceAttrs, "otel-resource-attributes", o.otelResourceAttrs, "Resource attributes added to every exported record in the format of OTEL_RESOURCE_ATTRIBUTES, e.g. \"deployment.environment=staging,team=payments\". They take precedence over the attributes set by stern. Used with --output=otel")
+	fs.BoolVar(&o.otelClusterUID, "otel-cluster-uid", o.otelClusterUID, "Set the resource k8s.cluster.uid to the UID of the kube-system namespace, a cluster identifier that does not depend on the kubeconfig. Left out when the namespace cannot be read. Used with --output=otel")
+	fs.BoolVar(&o.otelRecordCluster, "otel-record-cluster-name", o.otelRecordCluster, "Also set the k8s.cluster.name attribute of the resource on every record, for the backends that do not propagate the resource attributes to the records. Used with --output=otel")
+	fs.BoolVar(&o.otelResourceHost, "otel-resource-host", o.otelResourceHost, "Add the host of the stern process as the resource host.name. Disable it when tailing across nodes so that the only host.name is the node of each record. Used with --output=otel")
+	fs.BoolVar(&o.otelMonotonic, "otel-monotonic-timestamps", o.otelMonotonic, "Drop OpenTelemetry records whose timestamp goes backward for their container, e.g. after a resume, with a warning. Used with --output=otel")
+	fs.BoolVar(&o.otelLineNumbers, "otel-debug-line-numbers", o.otelLineNumbers, "Print the log lines on stdout too, prefixed by their number in the tail, and emit the number as the stern.line_no attribute to correlate both streams when debugging. Used with --output=otel")
+	fs.BoolVar(&o.otelQuiet, "otel-quiet", o.otelQuiet, "Print nothing on stderr for the containers tailed, instead of a line for each container and a final count. Used with --output=otel")
+	fs.StringVar(&o.otelMultiStart, "otel-multiline-start", o.otelMultiStart, "Regular expression matching the lines starting a record. The other lines, e.g. the frames of a stack trace, are joined into the body of the record. Used with --output=otel")
+	fs.StringVar(&o.otelMultiContinue, "otel-multiline-continue", o.otelMultiContinue, "Regular expression matching the lines continuing the previous record, e.g. '^\\s' for indented stack frames. Used with --output=otel")
+	fs.DurationVar(&o.otelMultiTimeout, "otel-multiline-timeout", o.otelMultiTimeout, "Emit a joined record when no line continued it for this duration. 0 waits for the next record. Used with --otel-multiline-start or --otel-multiline-continue")
+	fs.BoolVar(&o.otelMultiKeepNL, "otel-multiline-preserve-newline", o.otelMultiKeepNL, "Keep the trailing newlines of joined records, which are trimmed by default. Used with --otel-multiline-start or --otel-multiline-continue")
+	fs.DurationVar(&o.otelHeartbeat, "otel-heartbeat-interval", o.otelHeartbeat, "Emit a stern.heartbeat record with the time of the last line when a container has been silent for this interval, e.g. to detect hung containers. 0 disables heartbeats. Used with --output=otel")
+	fs.DurationVar(&o.otelPodOrderWindow, "otel-pod-order-window", o.otelPodOrderWindow, "Merge the records of the containers of a pod in timestamp order, holding each record for up to this window. 0 disables reordering. Used with --output=otel")
+	fs.BoolVar(&o.otelKeepRaw, "otel-keep-raw", o.otelKeepRaw, "Add the original line of structured logs as a log.record.original attribute, e.g. to debug the field extraction. Used with --output=otel")
+	fs.StringVar(&o.otelBodyHash, "otel-body-hash", o.otelBodyHash, "Add a log.body.hash attribute fingerprinting the body, 'fnv' or 'sha256', e.g. for deduplication by the backend. Used with --output=otel")
+	fs.BoolVar(&o.otelRecordID, "otel-record-id", o.otelRecordID, "Add a deterministic log.record.id attribute computed from the pod, container, timestamp, and line for idempotent ingestion. Used with --output=otel")
 
 	fs.Lookup("timestamps").NoOptDefVal = "default"
 }
@@ -598,12 +1079,12 @@ func (o *options) generateTemplate() (*template.Template, error) {
 				t = fmt.Sprintf("  \"namespace\": \"{{color .PodColor .Namespace}}\",\n%s", t)
 			}
 			t = fmt.Sprintf("{\n%s\n}", t)
-		case "otel":
+		case "otel", "oteljson":
 			// For OpenTelemetry output, we don't need a template since logs are exported directly
 			// Set a minimal template to avoid errors, but it won't be used
 			t = ""
 		default:
-			return nil, errors.New("output should be one of 'default', 'raw', 'json', 'extjson', 'ppextjson', and 'otel'")
+			return nil, errors.New("output should be one of 'default', 'raw', 'json', 'extjson', 'ppextjson', 'otel', and 'oteljson'")
 		}
 		t += "\n"
 	}
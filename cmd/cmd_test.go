@@ -156,6 +156,18 @@ func TestOptionsValidate(t *testing.T) {
 			}(),
 			"--condition is currently only supported with --tail=0 or --no-follow",
 		},
+		{
+			"Specify both --otel-multiline-start and --otel-multiline-continue",
+			func() *options {
+				o := NewOptions(streams)
+				o.podQuery = "."
+				o.otelMultiStart = "^\\S"
+				o.otelMultiContinue = "^\\s"
+
+				return o
+			}(),
+			"--otel-multiline-start and --otel-multiline-continue cannot be set at the same time",
+		},
 		{
 			"Use prompt",
 			func() *options {
@@ -575,6 +587,8 @@ func TestOptionsSternConfig(t *testing.T) {
 			OnlyLogLines:          false,
 			MaxLogRequests:        50,
 
+			OTelMultilineTimeout: time.Second,
+
 			Out:    streams.Out,
 			ErrOut: streams.ErrOut,
 		}
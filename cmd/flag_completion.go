@@ -32,7 +32,7 @@ var flagChoices = map[string][]string{
 	"color":           {"always", "never", "auto"},
 	"completion":      {"bash", "zsh", "fish"},
 	"container-state": {stern.RUNNING, stern.WAITING, stern.TERMINATED, stern.ALL_STATES},
-	"output":          {"default", "raw", "json", "extjson", "ppextjson"},
+	"output":          {"default", "raw", "json", "extjson", "ppextjson", "otel", "oteljson"},
 	"timestamps":      {"default", "short"},
 }
 
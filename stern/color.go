@@ -2,10 +2,14 @@ package stern
 
 import (
 	"errors"
+	"fmt"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/fatih/color"
+	"go.opentelemetry.io/otel/log"
 )
 
 var colorList = [][2]*color.Color{
@@ -17,6 +21,51 @@ var colorList = [][2]*color.Color{
 	{color.New(color.FgHiRed), color.New(color.FgRed)},
 }
 
+// containerColorOverride colors the containers whose name matches pattern
+type containerColorOverride struct {
+	pattern *regexp.Regexp
+	color   *color.Color
+}
+
+// containerColorOverrides take precedence over the hash-based container colors
+var containerColorOverrides []containerColorOverride
+
+// SetContainerColorOverrides sets explicit colors for containers by name,
+// e.g. {"istio-proxy": "2"} to dim a sidecar. The keys are regular
+// expressions; when several match, the first in sorted order wins.
+func SetContainerColorOverrides(colors map[string]string) error {
+	patterns := make([]string, 0, len(colors))
+	for pattern := range colors {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	overrides := make([]containerColorOverride, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return err
+		}
+		c, err := sgrSequenceToColor(colors[pattern])
+		if err != nil {
+			return err
+		}
+		overrides = append(overrides, containerColorOverride{pattern: re, color: c})
+	}
+	containerColorOverrides = overrides
+	return nil
+}
+
+// containerColorOverrideOf returns the explicit color of the container, or nil
+func containerColorOverrideOf(containerName string) *color.Color {
+	for _, override := range containerColorOverrides {
+		if override.pattern.MatchString(containerName) {
+			return override.color
+		}
+	}
+	return nil
+}
+
 func SetColorList(podColors, containerColors []string) error {
 	colors, err := parseColors(podColors, containerColors)
 	if err != nil {
@@ -72,3 +121,47 @@ func sgrSequenceToColor(s string) (*color.Color, error) {
 	}
 	return color.New(attrs...), nil
 }
+
+// severityLevels are the level names accepted by ParseSeverityColors, in
+// the order of the OTel severity ranges
+var severityLevels = []string{"trace", "debug", "info", "warn", "error", "fatal"}
+
+// ParseSeverityColors converts a map of level names to SGR sequences, e.g.
+// {"error": "31", "warn": "33"}, into colors applied to log lines by severity.
+func ParseSeverityColors(colors map[string]string) (map[string]*color.Color, error) {
+	if len(colors) == 0 {
+		return nil, nil
+	}
+	severityColors := make(map[string]*color.Color, len(colors))
+	for level, sgr := range colors {
+		level = strings.ToLower(level)
+		if severityLevel(level) == "" {
+			return nil, fmt.Errorf("unsupported severity %q (must be one of %s)", level, strings.Join(severityLevels, ", "))
+		}
+		c, err := sgrSequenceToColor(sgr)
+		if err != nil {
+			return nil, err
+		}
+		severityColors[level] = c
+	}
+	return severityColors, nil
+}
+
+// severityLevel returns the level name matching name, or an empty string
+func severityLevel(name string) string {
+	for _, level := range severityLevels {
+		if name == level {
+			return level
+		}
+	}
+	return ""
+}
+
+// severityLevelOf returns the level name of an OTel severity, or an empty
+// string for log.SeverityUndefined
+func severityLevelOf(severity log.Severity) string {
+	if severity < log.SeverityTrace1 || severity > log.SeverityFatal4 {
+		return ""
+	}
+	return severityLevels[(severity-log.SeverityTrace1)/4]
+}
@@ -104,3 +104,37 @@ func TestParseColors(t *testing.T) {
 		})
 	}
 }
+
+func TestContainerColorOverrides(t *testing.T) {
+	defer func() { containerColorOverrides = nil }()
+
+	if err := SetContainerColorOverrides(map[string]string{"istio-proxy": "2;37", "^linkerd-": "90"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, diffContainer := range []bool{false, true} {
+		_, got := determineColor("stern", "istio-proxy", diffContainer)
+		if want := color.New(color.Faint, color.FgWhite); !got.Equals(want) {
+			t.Errorf("diffContainer=%v: expected the configured color for istio-proxy, got %v", diffContainer, got)
+		}
+		_, got = determineColor("stern", "linkerd-proxy", diffContainer)
+		if want := color.New(color.FgHiBlack); !got.Equals(want) {
+			t.Errorf("diffContainer=%v: expected the configured color for linkerd-proxy, got %v", diffContainer, got)
+		}
+	}
+
+	// Other containers keep the hash-based colors
+	if _, got := determineColor("stern", "app", true); got != colorList[colorIndex("app")][1] {
+		t.Errorf("expected the hash-based color for app, got %v", got)
+	}
+	if _, got := determineColor("stern", "app", false); got != colorList[colorIndex("stern")][1] {
+		t.Errorf("expected the pod's container color for app, got %v", got)
+	}
+
+	if err := SetContainerColorOverrides(map[string]string{"(": "2"}); err == nil {
+		t.Error("expected error for an invalid pattern")
+	}
+	if err := SetContainerColorOverrides(map[string]string{"app": "dim"}); err == nil {
+		t.Error("expected error for an invalid color")
+	}
+}
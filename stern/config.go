@@ -23,6 +23,7 @@ import (
 	"text/template"
 	"time"
 
+	"github.com/fatih/color"
 	"github.com/stern/stern/stern/otel"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
@@ -52,15 +53,35 @@ type Config struct {
 	TailLines             *int64
 	Template              *template.Template
 	Follow                bool
+	Previous              bool
 	Resource              string
 	OnlyLogLines          bool
 	MaxLogRequests        int
 	Stdin                 bool
 	DiffContainer         bool
+	SeverityColors        map[string]*color.Color
+	DiagnosticsRate       int
+	NoMatchInterval       time.Duration
+	MaxResumeLines        int
+	StripTimestamps       int
+	RepeatWindow          time.Duration
 
 	// OpenTelemetry configuration
-	OTelEnabled  bool
-	OTelExporter *otel.Exporter
+	OTelEnabled     bool
+	OTelExporter    *otel.Exporter
+	OTelEmitMatches bool
+	OTelMonotonic   bool
+	OTelLineNumbers bool
+	OTelOrderWindow time.Duration
+	OTelHeartbeat   time.Duration
+	OTelQuiet       bool
+	OTelJSON        *otel.JSONEncoder
+
+	// Multiline joining of OTel records
+	OTelMultilineStart           *regexp.Regexp
+	OTelMultilineContinue        *regexp.Regexp
+	OTelMultilineTimeout         time.Duration
+	OTelMultilinePreserveNewline bool
 
 	Out    io.Writer
 	ErrOut io.Writer
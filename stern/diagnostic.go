@@ -0,0 +1,99 @@
+package stern
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// rateLimitedWriter drops the diagnostic messages written beyond the rate
+// and reports how many were suppressed once writing is allowed again
+type rateLimitedWriter struct {
+	w       io.Writer
+	limiter *rate.Limiter
+
+	mu         sync.Mutex
+	suppressed int
+}
+
+// newRateLimitedWriter allows perSecond messages per second to w
+func newRateLimitedWriter(w io.Writer, perSecond int) *rateLimitedWriter {
+	return &rateLimitedWriter{
+		w:       w,
+		limiter: rate.NewLimiter(rate.Limit(perSecond), perSecond),
+	}
+}
+
+// Write writes one diagnostic message unless the rate is exceeded
+func (r *rateLimitedWriter) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.limiter.Allow() {
+		r.suppressed++
+		return len(p), nil
+	}
+	r.flushLocked()
+	return r.w.Write(p)
+}
+
+// Flush reports the messages suppressed since the last report
+func (r *rateLimitedWriter) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.flushLocked()
+}
+
+func (r *rateLimitedWriter) flushLocked() {
+	if r.suppressed > 0 {
+		fmt.Fprintf(r.w, "suppressed %d diagnostic messages\n", r.suppressed)
+		r.suppressed = 0
+	}
+}
+
+// filterStats counts the lines read and the lines matching the filters so
+// that a broken filter can be told apart from a silent application
+type filterStats struct {
+	read    atomic.Int64
+	matched atomic.Int64
+}
+
+// record counts a line read and whether it matched the filters
+func (s *filterStats) record(matched bool) {
+	if s == nil {
+		return
+	}
+	s.read.Add(1)
+	if matched {
+		s.matched.Add(1)
+	}
+}
+
+// report writes a diagnostic when lines were read but none matched since the
+// last report, and starts counting anew
+func (s *filterStats) report(w io.Writer, interval time.Duration) {
+	read, matched := s.read.Swap(0), s.matched.Swap(0)
+	if read > 0 && matched == 0 {
+		fmt.Fprintf(w, "%d lines read, 0 matched filters in the last %s\n", read, interval)
+	}
+}
+
+// start reports every interval until ctx is done
+func (s *filterStats) start(ctx context.Context, w io.Writer, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.report(w, interval)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,87 @@
+package stern
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRateLimitedDiagnostics(t *testing.T) {
+	var lines []string
+	for i := 0; i < 100; i++ {
+		lines = append(lines, "2023-02-13T21:20:30.000000001Z line")
+	}
+	// The template fails for every line
+	tmpl := template.Must(template.New("").Parse(`{{.Message.Missing}}`))
+
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	diagOut := newRateLimitedWriter(errOut, 5)
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, out, io.Discard, &TailOptions{}, false, nil, false)
+	tail.diagOut = diagOut
+	if err := tail.ConsumeRequest(context.TODO(), &responseWrapperMock{data: bytes.NewBufferString(strings.Join(lines, "\n"))}); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	diagOut.Flush()
+
+	diagnostics := strings.Split(strings.TrimSuffix(errOut.String(), "\n"), "\n")
+	if len(diagnostics) != 6 {
+		t.Fatalf("expected 5 diagnostics and a summary, got %d lines: %q", len(diagnostics), errOut.String())
+	}
+	for _, d := range diagnostics[:5] {
+		if !strings.Contains(d, "expanding template failed") {
+			t.Errorf("expected a template error, got %q", d)
+		}
+	}
+	if expected := "suppressed 95 diagnostic messages"; diagnostics[5] != expected {
+		t.Errorf("expected %q, got %q", expected, diagnostics[5])
+	}
+	if out.Len() != 0 {
+		t.Errorf("expected no log output, got %q", out.String())
+	}
+}
+
+func TestNoMatchDiagnostic(t *testing.T) {
+	logLines := `2023-02-13T21:20:30.000000001Z GET /healthz
+2023-02-13T21:20:30.000000002Z GET /readyz
+2023-02-13T21:20:31.000000001Z GET /healthz`
+	tmpl := template.Must(template.New("").Parse(`{{printf "%s\n" .Message}}`))
+
+	out := new(bytes.Buffer)
+	errOut := new(bytes.Buffer)
+	stats := &filterStats{}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	options := &TailOptions{Include: []*regexp.Regexp{regexp.MustCompile(`POST`)}}
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, out, io.Discard, options, false, nil, false)
+	tail.filterStats = stats
+	if err := tail.ConsumeRequest(context.TODO(), &responseWrapperMock{data: bytes.NewBufferString(logLines)}); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	stats.report(errOut, time.Minute)
+	if expected := "3 lines read, 0 matched filters in the last 1m0s\n"; errOut.String() != expected {
+		t.Errorf("expected %q, but actual %q", expected, errOut.String())
+	}
+
+	// Nothing is reported for a silent window or once lines match
+	errOut.Reset()
+	stats.report(errOut, time.Minute)
+	tail.consumeLine(context.Background(), "2023-02-13T21:20:32.000000001Z POST /orders")
+	tail.consumeLine(context.Background(), "2023-02-13T21:20:32.000000002Z GET /healthz")
+	stats.report(errOut, time.Minute)
+	if errOut.Len() != 0 {
+		t.Errorf("expected no diagnostic, got %q", errOut.String())
+	}
+}
@@ -17,6 +17,9 @@ type FileTail struct {
 	in      io.Reader
 	out     io.Writer
 	errOut  io.Writer
+	diagOut io.Writer // diagnostic messages, possibly rate-limited
+
+	filterStats *filterStats
 }
 
 // NewFileTail returns a new tail of the input reader
@@ -27,6 +30,7 @@ func NewFileTail(tmpl *template.Template, in io.Reader, out, errOut io.Writer, o
 		in:      in,
 		out:     out,
 		errOut:  errOut,
+		diagOut: errOut,
 	}
 }
 
@@ -77,20 +81,25 @@ func (t *FileTail) sprint(msg string) (string, error) {
 
 // Print prints a color coded log message
 func (t *FileTail) Print(msg string) {
+	t.printColored(msg, nil)
+}
+
+// printColored prints a log message like Print, coloring the whole line with c
+func (t *FileTail) printColored(msg string, c *color.Color) {
 	buf, err := t.sprint(msg)
 	if err != nil {
-		fmt.Fprintf(t.errOut, "%s\n", err)
+		fmt.Fprintf(t.diagOut, "%s\n", err)
 		return
 	}
 
-	fmt.Fprint(t.out, t.Options.HighlightMatchedString(buf))
+	fmt.Fprint(t.out, colorLine(t.Options.HighlightMatchedString(buf), c))
 }
 
 // PrintWithoutHighlight prints a log message without applying any highlight.
 func (t *FileTail) PrintWithoutHighlight(msg string) {
 	buf, err := t.sprint(msg)
 	if err != nil {
-		fmt.Fprintf(t.errOut, "%s\n", err)
+		fmt.Fprintf(t.diagOut, "%s\n", err)
 		return
 	}
 
@@ -100,9 +109,11 @@ func (t *FileTail) PrintWithoutHighlight(msg string) {
 func (t *FileTail) consumeLine(line string) {
 	content := line
 
-	if t.Options.IsExclude(content) || !t.Options.IsInclude(content) {
+	matched := !t.Options.IsExclude(content) && t.Options.IsInclude(content)
+	t.filterStats.record(matched)
+	if !matched {
 		return
 	}
 
-	t.Print(content)
+	t.printColored(content, t.Options.SeverityColor(content))
 }
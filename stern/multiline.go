@@ -0,0 +1,117 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package stern
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stern/stern/stern/otel"
+)
+
+// multilineJoiner joins the lines of a multiline log, e.g. a stack trace,
+// into the body of a single OTel record. A line continues the buffered
+// record when it matches cont, or when it does not match start. The record
+// is emitted on the next line starting a record, after timeout without a
+// line continuing it, and on close. A record emitted on the next line gets
+// the context of that line, the others outlive the tail and get none.
+type multilineJoiner struct {
+	start           *regexp.Regexp
+	cont            *regexp.Regexp
+	timeout         time.Duration
+	preserveNewline bool // keep the trailing newlines of the joined body
+	emit            func(ctx context.Context, record *otel.LogRecord)
+	// locker, when set, is held around the emit after timeout, which runs
+	// on the goroutine of the timer, e.g. the lock serializing the output of
+	// the tail. Callers of add, flush and close hold it already.
+	locker sync.Locker
+
+	mu     sync.Mutex
+	record *otel.LogRecord // the record of the first line, nil when empty
+	lines  []string
+	timer  *time.Timer
+}
+
+// add buffers the record of a line, emitting the buffered record first
+// unless the line continues it
+func (j *multilineJoiner) add(ctx context.Context, record *otel.LogRecord) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.record == nil || !j.continues(record.Body) {
+		j.flushLocked(ctx)
+		j.record = record
+	}
+	j.lines = append(j.lines, record.Body)
+
+	if j.timeout <= 0 {
+		return
+	}
+	if j.timer == nil {
+		j.timer = time.AfterFunc(j.timeout, j.expire)
+	} else {
+		j.timer.Reset(j.timeout)
+	}
+}
+
+// continues reports whether line continues the buffered record
+func (j *multilineJoiner) continues(line string) bool {
+	if j.cont != nil {
+		return j.cont.MatchString(line)
+	}
+	return !j.start.MatchString(line)
+}
+
+// flush emits the buffered record
+func (j *multilineJoiner) flush() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.flushLocked(context.Background())
+}
+
+// expire emits the buffered record once timeout has passed
+func (j *multilineJoiner) expire() {
+	if j.locker != nil {
+		j.locker.Lock()
+		defer j.locker.Unlock()
+	}
+	j.flush()
+}
+
+// close emits the buffered record and stops the timeout
+func (j *multilineJoiner) close() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.flushLocked(context.Background())
+	if j.timer != nil {
+		j.timer.Stop()
+	}
+}
+
+func (j *multilineJoiner) flushLocked(ctx context.Context) {
+	if j.record == nil {
+		return
+	}
+	record := j.record
+	record.Body = strings.Join(j.lines, "\n")
+	if !j.preserveNewline {
+		record.Body = strings.TrimRight(record.Body, "\r\n")
+	}
+	j.record, j.lines = nil, nil
+	j.emit(ctx, record)
+}
@@ -0,0 +1,177 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package stern
+
+import (
+	"context"
+	"io"
+	"regexp"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stern/stern/stern/otel"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestMultilineJoiner(t *testing.T) {
+	tests := []struct {
+		name     string
+		joiner   *multilineJoiner
+		lines    []string
+		expected []string
+	}{
+		{
+			name:     "continue",
+			joiner:   &multilineJoiner{cont: regexp.MustCompile(`^\s`)},
+			lines:    []string{"Exception in thread", "\tat A", "\tat B", "next", "last"},
+			expected: []string{"Exception in thread\n\tat A\n\tat B", "next", "last"},
+		},
+		{
+			name:     "start",
+			joiner:   &multilineJoiner{start: regexp.MustCompile(`^\d{4}-`)},
+			lines:    []string{"2025-01-01 error", "Traceback:", "  File x", "2025-01-01 ok"},
+			expected: []string{"2025-01-01 error\nTraceback:\n  File x", "2025-01-01 ok"},
+		},
+		{
+			name:     "continuation without a start",
+			joiner:   &multilineJoiner{cont: regexp.MustCompile(`^\s`)},
+			lines:    []string{"\tat A", "\tat B", "next"},
+			expected: []string{"\tat A\n\tat B", "next"},
+		},
+		{
+			name:     "trailing newline trimmed",
+			joiner:   &multilineJoiner{cont: regexp.MustCompile(`^(\s|$)`)},
+			lines:    []string{"Traceback:", "  File x", ""},
+			expected: []string{"Traceback:\n  File x"},
+		},
+		{
+			name:     "trailing newline preserved",
+			joiner:   &multilineJoiner{cont: regexp.MustCompile(`^(\s|$)`), preserveNewline: true},
+			lines:    []string{"Traceback:", "  File x", ""},
+			expected: []string{"Traceback:\n  File x\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var bodies []string
+			tt.joiner.emit = func(ctx context.Context, record *otel.LogRecord) {
+				bodies = append(bodies, record.Body)
+			}
+			for _, line := range tt.lines {
+				tt.joiner.add(context.Background(), &otel.LogRecord{Body: line})
+			}
+			tt.joiner.close()
+
+			if len(bodies) != len(tt.expected) {
+				t.Fatalf("expected %q, got %q", tt.expected, bodies)
+			}
+			for i := range bodies {
+				if bodies[i] != tt.expected[i] {
+					t.Errorf("%d: expected %q, got %q", i, tt.expected[i], bodies[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMultilineJoinerTimeout(t *testing.T) {
+	emitted := make(chan string, 1)
+	joiner := &multilineJoiner{
+		cont:    regexp.MustCompile(`^\s`),
+		timeout: 10 * time.Millisecond,
+		emit: func(ctx context.Context, record *otel.LogRecord) {
+			emitted <- record.Body
+		},
+	}
+	defer joiner.close()
+
+	joiner.add(context.Background(), &otel.LogRecord{Body: "panic: boom"})
+	joiner.add(context.Background(), &otel.LogRecord{Body: "\tmain.go:12"})
+
+	select {
+	case body := <-emitted:
+		if body != "panic: boom\n\tmain.go:12" {
+			t.Errorf("unexpected body %q", body)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the record to be emitted after the timeout")
+	}
+}
+
+func TestMultilineJoinerContext(t *testing.T) {
+	type key struct{}
+	var contexts []context.Context
+	joiner := &multilineJoiner{
+		cont: regexp.MustCompile(`^\s`),
+		emit: func(ctx context.Context, record *otel.LogRecord) {
+			contexts = append(contexts, ctx)
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), key{}, "tail"))
+	joiner.add(ctx, &otel.LogRecord{Body: "panic: boom"})
+	joiner.add(ctx, &otel.LogRecord{Body: "started"})
+	cancel()
+	joiner.close()
+
+	if len(contexts) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(contexts))
+	}
+	// The record emitted on the next line gets its context
+	if contexts[0].Value(key{}) != "tail" {
+		t.Error("expected the context of the line")
+	}
+	// The record emitted on close is not cancelled with the tail
+	if contexts[1].Err() != nil {
+		t.Errorf("expected the record emitted on close not to be cancelled, got %v", contexts[1].Err())
+	}
+}
+
+func TestTailMultiline(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	options := &TailOptions{MultilineContinue: regexp.MustCompile(`^\s`), LineNumbers: true}
+	tmpl := template.Must(template.New("").Parse("{{.Message}}\n"))
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, io.Discard, io.Discard, options, false, &otel.Exporter{}, true)
+	var emitted []*otel.LogRecord
+	// a zero window emits the records as they arrive
+	tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {
+		emitted = append(emitted, record)
+	})
+	tail.consumeLine(context.Background(), "2023-02-13T21:20:30.000000001Z java.lang.IllegalStateException")
+	tail.consumeLine(context.Background(), "2023-02-13T21:20:30.000000002Z \tat com.example.Main.run(Main.java:12)")
+	tail.consumeLine(context.Background(), "2023-02-13T21:20:31.000000001Z started")
+	if len(emitted) != 1 {
+		t.Fatalf("expected the stack trace to be emitted, got %d records", len(emitted))
+	}
+	tail.Close()
+	if len(emitted) != 2 {
+		t.Fatalf("expected the last record to be emitted on close, got %d records", len(emitted))
+	}
+
+	trace := emitted[0]
+	if trace.Body != "java.lang.IllegalStateException\n\tat com.example.Main.run(Main.java:12)" {
+		t.Errorf("unexpected body %q", trace.Body)
+	}
+	if want := time.Date(2023, 2, 13, 21, 20, 30, 1, time.UTC); !trace.Timestamp.Equal(want) {
+		t.Errorf("expected the timestamp of the first line, got %s", trace.Timestamp)
+	}
+	if trace.LineNumber != 1 || emitted[1].LineNumber != 3 {
+		t.Errorf("expected the line numbers of the first lines, got %d and %d", trace.LineNumber, emitted[1].LineNumber)
+	}
+}
@@ -0,0 +1,245 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+)
+
+// Supported values for AggregateConfig.GroupBy
+const (
+	AggregateByNamespace = "namespace"
+	AggregateByPod       = "pod"
+	AggregateByContainer = "container"
+	AggregateBySeverity  = "severity"
+	AggregateByPrefix    = "prefix"
+)
+
+// defaultAggregatePrefixLength is used by the "prefix" grouping when no length is configured
+const defaultAggregatePrefixLength = 32
+
+// AggregateConfig configures the emission of per-window counts instead of individual records
+type AggregateConfig struct {
+	// Window is the aggregation interval. Aggregation is disabled when zero.
+	Window time.Duration
+	// GroupBy lists the record properties used to group records
+	// ("namespace", "pod", "container", "severity" or "prefix")
+	GroupBy []string
+	// PrefixLength is the number of message characters used by the "prefix" grouping
+	PrefixLength int
+}
+
+// Enabled reports whether aggregation is turned on
+func (c AggregateConfig) Enabled() bool {
+	return c.Window > 0
+}
+
+// validate checks that all groupings are supported
+func (c AggregateConfig) validate() error {
+	for _, by := range c.GroupBy {
+		switch by {
+		case AggregateByNamespace, AggregateByPod, AggregateByContainer, AggregateBySeverity, AggregateByPrefix:
+		default:
+			return fmt.Errorf("unsupported aggregation grouping: %s (must be one of 'namespace', 'pod', 'container', 'severity' or 'prefix')", by)
+		}
+	}
+	return nil
+}
+
+// aggregateKey identifies a group of records; properties that are not part
+// of the grouping are left empty
+type aggregateKey struct {
+	namespace string
+	pod       string
+	container string
+	severity  string
+	prefix    string
+}
+
+// aggregator counts records per group and periodically emits summary records
+type aggregator struct {
+	config AggregateConfig
+	logger log.Logger
+
+	mu          sync.Mutex
+	counts      map[aggregateKey]int64
+	order       []aggregateKey
+	windowStart time.Time
+
+	started  bool
+	stopOnce sync.Once
+	done     chan struct{}
+	stopped  chan struct{}
+}
+
+// newAggregator returns an aggregator emitting summaries to logger
+func newAggregator(logger log.Logger, config AggregateConfig) *aggregator {
+	if config.PrefixLength <= 0 {
+		config.PrefixLength = defaultAggregatePrefixLength
+	}
+	return &aggregator{
+		config:      config,
+		logger:      logger,
+		counts:      make(map[aggregateKey]int64),
+		windowStart: time.Now(),
+		done:        make(chan struct{}),
+		stopped:     make(chan struct{}),
+	}
+}
+
+// start emits summaries every window until stop is called
+func (a *aggregator) start() {
+	a.started = true
+	go func() {
+		defer close(a.stopped)
+		ticker := time.NewTicker(a.config.Window)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				a.flush(context.Background())
+			case <-a.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop terminates the periodic emission and emits the pending summaries
+func (a *aggregator) stop(ctx context.Context) {
+	a.stopOnce.Do(func() {
+		close(a.done)
+		if a.started {
+			select {
+			case <-a.stopped:
+			case <-ctx.Done():
+			}
+		}
+		a.flush(ctx)
+	})
+}
+
+// add counts the record in its group
+func (a *aggregator) add(record *LogRecord) {
+	key := a.keyFor(record)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.counts[key]; !ok {
+		a.order = append(a.order, key)
+	}
+	a.counts[key]++
+}
+
+// keyFor computes the group of the record according to the configured grouping
+func (a *aggregator) keyFor(record *LogRecord) aggregateKey {
+	var key aggregateKey
+	var message, severity string
+	var parsed bool
+	parse := func() {
+		if !parsed {
+			message, severity, _, _ = parseStructuredLog(record.Body)
+			parsed = true
+		}
+	}
+
+	for _, by := range a.config.GroupBy {
+		switch by {
+		case AggregateByNamespace:
+			key.namespace = record.Namespace
+		case AggregateByPod:
+			key.namespace = record.Namespace
+			key.pod = record.PodName
+		case AggregateByContainer:
+			key.namespace = record.Namespace
+			key.pod = record.PodName
+			key.container = record.ContainerName
+		case AggregateBySeverity:
+			parse()
+			key.severity = severity
+		case AggregateByPrefix:
+			parse()
+			key.prefix = truncateRunes(message, a.config.PrefixLength)
+		}
+	}
+	return key
+}
+
+// flush emits one summary record per group seen during the current window
+// and starts a new window
+func (a *aggregator) flush(ctx context.Context) {
+	a.mu.Lock()
+	counts, order, windowStart := a.counts, a.order, a.windowStart
+	a.counts = make(map[aggregateKey]int64)
+	a.order = nil
+	a.windowStart = time.Now()
+	a.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range order {
+		a.logger.Emit(ctx, summaryRecord(key, counts[key], windowStart, now))
+	}
+}
+
+// summaryRecord builds the record reporting count lines for the group
+func summaryRecord(key aggregateKey, count int64, windowStart, windowEnd time.Time) log.Record {
+	window := windowEnd.Sub(windowStart).Round(time.Millisecond)
+
+	attrs := []log.KeyValue{
+		log.Int64("count", count),
+		log.String("stern.aggregate.window", window.String()),
+	}
+	if key.namespace != "" {
+		attrs = append(attrs, log.String("k8s.namespace.name", key.namespace))
+	}
+	if key.pod != "" {
+		attrs = append(attrs, log.String("k8s.pod.name", key.pod))
+	}
+	if key.container != "" {
+		attrs = append(attrs, log.String("k8s.container.name", key.container))
+	}
+	if key.prefix != "" {
+		attrs = append(attrs, log.String("stern.aggregate.prefix", key.prefix))
+	}
+
+	body := fmt.Sprintf("%d lines in the last %s", count, window)
+	if key.prefix != "" {
+		body = fmt.Sprintf("%d lines matching %q in the last %s", count, key.prefix, window)
+	}
+
+	logRecord := log.Record{}
+	logRecord.SetTimestamp(windowEnd)
+	logRecord.SetObservedTimestamp(windowEnd)
+	logRecord.SetBody(log.StringValue(body))
+	if key.severity != "" {
+		logRecord.SetSeverity(mapSeverityToOTel(key.severity))
+	}
+	logRecord.AddAttributes(attrs...)
+	return logRecord
+}
+
+// truncateRunes returns at most n runes of s
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
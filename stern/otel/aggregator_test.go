@@ -0,0 +1,142 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestAggregatorSummary(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	agg := newAggregator(logger, AggregateConfig{
+		Window:  10 * time.Second,
+		GroupBy: []string{AggregateByPod, AggregateBySeverity},
+	})
+
+	newRecord := func(pod, body string) *LogRecord {
+		return &LogRecord{
+			Timestamp:     time.Now(),
+			Body:          body,
+			Namespace:     "default",
+			PodName:       pod,
+			ContainerName: "app",
+		}
+	}
+	for i := 0; i < 5; i++ {
+		agg.add(newRecord("pod-a", `{"level":"debug","msg":"polling"}`))
+	}
+	for i := 0; i < 2; i++ {
+		agg.add(newRecord("pod-a", `{"level":"error","msg":"failed"}`))
+	}
+	agg.add(newRecord("pod-b", `{"level":"debug","msg":"polling"}`))
+
+	agg.flush(context.Background())
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 3 {
+		t.Fatalf("expected 3 summary records, got %d", len(mockExporter.records))
+	}
+
+	type summary struct {
+		pod      string
+		severity log.Severity
+		count    int64
+	}
+	expected := []summary{
+		{"pod-a", log.SeverityDebug, 5},
+		{"pod-a", log.SeverityError, 2},
+		{"pod-b", log.SeverityDebug, 1},
+	}
+	for i, want := range expected {
+		record := mockExporter.records[i]
+		got := summary{severity: record.Severity(), count: -1}
+		record.WalkAttributes(func(kv log.KeyValue) bool {
+			switch kv.Key {
+			case "k8s.pod.name":
+				got.pod = kv.Value.AsString()
+			case "count":
+				got.count = kv.Value.AsInt64()
+			}
+			return true
+		})
+		if got != want {
+			t.Errorf("%d: expected %+v, got %+v", i, want, got)
+		}
+	}
+
+	// A new window starts empty
+	mockExporter.records = nil
+	agg.flush(context.Background())
+	provider.ForceFlush(context.Background())
+	if len(mockExporter.records) != 0 {
+		t.Errorf("expected no summary records for an empty window, got %d", len(mockExporter.records))
+	}
+}
+
+func TestAggregatorPrefix(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	agg := newAggregator(logger, AggregateConfig{
+		Window:       time.Second,
+		GroupBy:      []string{AggregateByPrefix},
+		PrefixLength: 7,
+	})
+	agg.add(&LogRecord{Body: "polling job 1"})
+	agg.add(&LogRecord{Body: "polling job 2"})
+	agg.add(&LogRecord{Body: "request served"})
+
+	agg.stop(context.Background())
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 summary records, got %d", len(mockExporter.records))
+	}
+
+	var prefix string
+	var count int64
+	mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		switch kv.Key {
+		case "stern.aggregate.prefix":
+			prefix = kv.Value.AsString()
+		case "count":
+			count = kv.Value.AsInt64()
+		}
+		return true
+	})
+	if prefix != "polling" || count != 2 {
+		t.Errorf("expected prefix 'polling' with count 2, got %q with count %d", prefix, count)
+	}
+}
+
+func TestAggregateConfigValidate(t *testing.T) {
+	if err := (AggregateConfig{GroupBy: []string{"pod", "severity", "prefix"}}).validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (AggregateConfig{GroupBy: []string{"node"}}).validate(); err == nil {
+		t.Error("expected error for unsupported grouping")
+	}
+}
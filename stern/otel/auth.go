@@ -0,0 +1,235 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// TokenFunc returns the bearer token authenticating the exports
+type TokenFunc func(ctx context.Context) (string, error)
+
+// defaultTokenRefresh is how long a token read from ExporterConfig.TokenFile
+// is used before the file is read again
+const defaultTokenRefresh = time.Minute
+
+// tokenProvider returns the provider of the bearer token, nil when the
+// exports are not authenticated by a token
+func (c *ExporterConfig) tokenProvider() TokenFunc {
+	if c.TokenProvider != nil {
+		return c.TokenProvider
+	}
+	if c.TokenFile != "" {
+		refresh := c.TokenRefresh
+		if refresh <= 0 {
+			refresh = defaultTokenRefresh
+		}
+		return newFileToken(c.TokenFile, refresh).get
+	}
+	return nil
+}
+
+// resolveHeaders returns a copy of the configuration whose Headers include
+// the ones of HeadersFile, which override the inline headers of the same
+// name, or the configuration itself without a file
+func (c *ExporterConfig) resolveHeaders() (*ExporterConfig, error) {
+	if c.HeadersFile == "" {
+		return c, nil
+	}
+	fileHeaders, err := readHeadersFile(c.HeadersFile)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := *c
+	resolved.Headers = make(map[string]string, len(c.Headers)+len(fileHeaders))
+	maps.Copy(resolved.Headers, c.Headers)
+	for key, value := range fileHeaders {
+		// Header names are case-insensitive
+		for existing := range resolved.Headers {
+			if strings.EqualFold(existing, key) {
+				delete(resolved.Headers, existing)
+			}
+		}
+		resolved.Headers[key] = value
+	}
+	return &resolved, nil
+}
+
+// readHeadersFile reads the "key: value" lines of a headers file, skipping
+// blank lines and "#" comments. The lines are not quoted in the errors, as
+// they likely hold credentials.
+func readHeadersFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OTel headers file: %w", err)
+	}
+	defer file.Close()
+
+	headers := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid header on line %d of %s, expected key: value", lineNo, path)
+		}
+		headers[key] = strings.TrimSpace(value)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read OTel headers file: %w", err)
+	}
+	return headers, nil
+}
+
+// fileToken reads a token from a file, e.g. a projected service account
+// token, again once refresh has passed so that rotations are picked up
+type fileToken struct {
+	path    string
+	refresh time.Duration
+
+	mu    sync.Mutex
+	token string
+	read  time.Time
+}
+
+func newFileToken(path string, refresh time.Duration) *fileToken {
+	return &fileToken{path: path, refresh: refresh}
+}
+
+// get returns the token, reading the file when the last read is stale
+func (f *fileToken) get(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.token != "" && time.Since(f.read) < f.refresh {
+		return f.token, nil
+	}
+	data, err := os.ReadFile(f.path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read token: %w", err)
+	}
+	token := strings.TrimSpace(string(data))
+	if token == "" {
+		return "", fmt.Errorf("token file %s is empty", f.path)
+	}
+	f.token, f.read = token, time.Now()
+	return f.token, nil
+}
+
+// tokenCredentials authenticates each gRPC call with the current token
+type tokenCredentials struct {
+	token TokenFunc
+}
+
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	token, err := c.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+// RequireTransportSecurity lets the token be sent to insecure collectors,
+// e.g. a local agent, like the static headers
+func (c tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// tokenExporter authenticates the exports of an HTTP exporter with the
+// current token. The OTLP HTTP exporter only takes static headers, so it is
+// created again with the new token whenever the token changes.
+type tokenExporter struct {
+	token  TokenFunc
+	create func(ctx context.Context, headers map[string]string) (sdklog.Exporter, error)
+	static map[string]string // the headers sent along with the token
+
+	mu       sync.Mutex
+	current  sdklog.Exporter
+	bearer   string
+	shutdown bool // whether Shutdown was called, keeping the current exporter
+}
+
+// Export exports the records with the exporter of the current token
+func (e *tokenExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	exporter, err := e.exporter(ctx)
+	if err != nil {
+		return err
+	}
+	return exporter.Export(ctx, records)
+}
+
+// exporter returns the exporter of the current token, replacing the
+// exporter of the previous one
+func (e *tokenExporter) exporter(ctx context.Context) (sdklog.Exporter, error) {
+	token, err := e.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.current != nil && (token == e.bearer || e.shutdown) {
+		return e.current, nil
+	}
+	headers := maps.Clone(e.static)
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	headers["Authorization"] = "Bearer " + token
+	exporter, err := e.create(ctx, headers)
+	if err != nil {
+		return nil, err
+	}
+	if e.current != nil {
+		// The exports in progress with the previous token still complete
+		if err := e.current.Shutdown(ctx); err != nil {
+			return nil, err
+		}
+	}
+	e.current, e.bearer = exporter, token
+	return e.current, nil
+}
+
+func (e *tokenExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.shutdown = true
+	if e.current == nil {
+		return nil
+	}
+	return e.current.Shutdown(ctx)
+}
+
+func (e *tokenExporter) ForceFlush(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.current == nil {
+		return nil
+	}
+	return e.current.ForceFlush(ctx)
+}
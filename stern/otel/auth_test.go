@@ -0,0 +1,156 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestFileToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte("first\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cached := newFileToken(path, time.Hour)
+	refreshed := newFileToken(path, time.Nanosecond)
+	for _, token := range []*fileToken{cached, refreshed} {
+		if got, err := token.get(context.Background()); err != nil || got != "first" {
+			t.Fatalf("expected token %q, got %q (%v)", "first", got, err)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("second"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := cached.get(context.Background()); got != "first" {
+		t.Errorf("expected the token to be cached until the refresh, got %q", got)
+	}
+	if got, _ := refreshed.get(context.Background()); got != "second" {
+		t.Errorf("expected the token to be read again after the refresh, got %q", got)
+	}
+
+	if _, err := newFileToken(filepath.Join(t.TempDir(), "missing"), time.Minute).get(context.Background()); err == nil {
+		t.Error("expected an error for a missing token file")
+	}
+}
+
+func TestTokenCredentials(t *testing.T) {
+	creds := tokenCredentials{token: func(ctx context.Context) (string, error) { return "secret", nil }}
+	md, err := creds.GetRequestMetadata(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := map[string]string{"authorization": "Bearer secret"}; !reflect.DeepEqual(md, expected) {
+		t.Errorf("expected %v, got %v", expected, md)
+	}
+}
+
+func TestHTTPExporterToken(t *testing.T) {
+	var mu sync.Mutex
+	var authorizations, tenants []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		tenants = append(tenants, r.Header.Get("X-Tenant"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tokens := []string{"one", "one", "two"}
+	config := &ExporterConfig{
+		Endpoint: strings.TrimPrefix(server.URL, "http://"),
+		Insecure: true,
+		Headers:  map[string]string{"X-Tenant": "shop", "Authorization": "Bearer static"},
+		TokenProvider: func(ctx context.Context) (string, error) {
+			token := tokens[0]
+			tokens = tokens[1:]
+			return token, nil
+		},
+	}
+	exporter, err := newHTTPExporter(context.Background(), config, &exportStats{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer exporter.Shutdown(context.Background())
+
+	var record sdklog.Record
+	record.SetBody(log.StringValue("authenticated"))
+	for i := 0; i < 3; i++ {
+		if err := exporter.Export(context.Background(), []sdklog.Record{record}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if expected := []string{"Bearer one", "Bearer one", "Bearer two"}; !reflect.DeepEqual(authorizations, expected) {
+		t.Errorf("expected Authorization %v, got %v", expected, authorizations)
+	}
+	if expected := []string{"shop", "shop", "shop"}; !reflect.DeepEqual(tenants, expected) {
+		t.Errorf("expected the static headers to be kept, got %v", tenants)
+	}
+}
+
+func TestResolveHeaders(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "headers")
+	content := "# tenant credentials\nauthorization: Bearer secret\n\nX-Scope-OrgID:  shop \n"
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &ExporterConfig{
+		Headers:     map[string]string{"Authorization": "Bearer inline", "X-Region": "eu"},
+		HeadersFile: path,
+	}
+	resolved, err := config.resolveHeaders()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]string{"authorization": "Bearer secret", "X-Scope-OrgID": "shop", "X-Region": "eu"}
+	if !reflect.DeepEqual(resolved.Headers, expected) {
+		t.Errorf("expected %v, got %v", expected, resolved.Headers)
+	}
+	if config.Headers["Authorization"] != "Bearer inline" {
+		t.Errorf("expected the configuration to be left unchanged, got %v", config.Headers)
+	}
+
+	// the line holding a credential is not quoted in the error
+	invalid := filepath.Join(dir, "invalid")
+	if err := os.WriteFile(invalid, []byte("Bearer secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = (&ExporterConfig{HeadersFile: invalid}).resolveHeaders()
+	if err == nil || strings.Contains(err.Error(), "secret") {
+		t.Errorf("expected an error without the line, got %v", err)
+	}
+
+	if _, err := (&ExporterConfig{HeadersFile: filepath.Join(dir, "missing")}).resolveHeaders(); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
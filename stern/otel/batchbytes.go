@@ -0,0 +1,59 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// batchBytesExporter splits the batches whose records add up to more than
+// maxBytes so that each export stays under the payload limit of the collector
+type batchBytesExporter struct {
+	sdklog.Exporter
+	maxBytes int
+}
+
+// Export exports the records in as few batches of at most maxBytes as
+// possible. A record larger than maxBytes is exported on its own.
+func (e *batchBytesExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	var errs []error
+	start, size := 0, 0
+	for i := range records {
+		n := recordSize(&records[i])
+		if i > start && size+n > e.maxBytes {
+			errs = append(errs, e.Exporter.Export(ctx, records[start:i]))
+			start, size = i, 0
+		}
+		size += n
+	}
+	if start < len(records) {
+		errs = append(errs, e.Exporter.Export(ctx, records[start:]))
+	}
+	return errors.Join(errs...)
+}
+
+// recordSize estimates the serialized size of a record by its OTLP/JSON
+// encoding, which is larger than the protobuf one sent by the exporters
+func recordSize(record *sdklog.Record) int {
+	data, err := json.Marshal(otlpRecord(record))
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
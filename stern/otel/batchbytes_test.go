@@ -0,0 +1,100 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// batchRecordingExporter records the size of each exported batch
+type batchRecordingExporter struct {
+	mockLogRecordExporter
+	batches []int
+}
+
+func (b *batchRecordingExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	size := 0
+	for i := range records {
+		size += recordSize(&records[i])
+	}
+	b.batches = append(b.batches, size)
+	return b.mockLogRecordExporter.Export(ctx, records)
+}
+
+func TestBatchBytesExporter(t *testing.T) {
+	recorder := &batchRecordingExporter{}
+	exporter := &batchBytesExporter{Exporter: recorder, maxBytes: 4096}
+
+	records := make([]sdklog.Record, 10)
+	for i := range records {
+		records[i].SetTimestamp(time.Now())
+		records[i].SetBody(log.StringValue(strings.Repeat("x", 1000)))
+	}
+	if err := exporter.Export(context.Background(), records); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(recorder.records) != len(records) {
+		t.Fatalf("expected %d records exported, got %d", len(records), len(recorder.records))
+	}
+	if len(recorder.batches) < 3 {
+		t.Errorf("expected the batch to be split, got %d exports", len(recorder.batches))
+	}
+	for i, size := range recorder.batches {
+		if size > exporter.maxBytes {
+			t.Errorf("batch %d: expected at most %d bytes, got %d", i, exporter.maxBytes, size)
+		}
+	}
+
+	// A record larger than the limit is exported on its own
+	recorder.batches = nil
+	large := make([]sdklog.Record, 2)
+	large[0].SetBody(log.StringValue(strings.Repeat("x", 5000)))
+	large[1].SetBody(log.StringValue("small"))
+	if err := exporter.Export(context.Background(), large); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.batches) != 2 {
+		t.Errorf("expected the oversized record to be exported alone, got %d exports", len(recorder.batches))
+	}
+}
+
+func TestNewExporterMaxBatchBytes(t *testing.T) {
+	recorder := &batchRecordingExporter{}
+	exporter := newExporter(&ExporterConfig{BatchSize: 512, ExportTimeout: time.Minute, MaxBatchBytes: 4096}, resource.Empty(), recorder)
+
+	for i := 0; i < 20; i++ {
+		exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: strings.Repeat("x", 1000), PodName: "my-pod"})
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(recorder.records) != 20 {
+		t.Fatalf("expected 20 records exported, got %d", len(recorder.records))
+	}
+	for i, size := range recorder.batches {
+		if size > 4096 {
+			t.Errorf("batch %d: expected at most 4096 bytes, got %d", i, size)
+		}
+	}
+}
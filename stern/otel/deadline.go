@@ -0,0 +1,91 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// deadlineExporter bounds each export by the age of its oldest record so
+// that stale batches fail fast instead of waiting for the export timeout
+type deadlineExporter struct {
+	sdklog.Exporter
+	// budget is the time a record may wait between being read and exported
+	budget time.Duration
+
+	// reads holds the times the queued records were read, in queue order,
+	// since the observed timestamp may be the kubelet time of the line
+	mu    sync.Mutex
+	reads []time.Time
+}
+
+// read records the time a record entered the queue
+func (e *deadlineExporter) read(t time.Time) {
+	e.mu.Lock()
+	e.reads = append(e.reads, t)
+	e.mu.Unlock()
+}
+
+// popReads removes the read times of the next n queued records, returning
+// the oldest. It is zero when no read times were recorded.
+func (e *deadlineExporter) popReads(n int) time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	n = min(n, len(e.reads))
+	var oldest time.Time
+	for _, read := range e.reads[:n] {
+		if oldest.IsZero() || read.Before(oldest) {
+			oldest = read
+		}
+	}
+	e.reads = e.reads[n:]
+	return oldest
+}
+
+// Export exports the records before the oldest one exceeds the budget
+func (e *deadlineExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	if deadline, ok := e.deadline(records); ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, deadline)
+		defer cancel()
+	}
+	return e.Exporter.Export(ctx, records)
+}
+
+// deadline returns the time the oldest record of the batch exceeds the
+// budget. The age is counted from when stern read the line, or from the
+// record timestamps of records exported without going through the queue.
+func (e *deadlineExporter) deadline(records []sdklog.Record) (time.Time, bool) {
+	oldest := e.popReads(len(records))
+	if oldest.IsZero() {
+		for i := range records {
+			read := records[i].ObservedTimestamp()
+			if read.IsZero() {
+				read = records[i].Timestamp()
+			}
+			if !read.IsZero() && (oldest.IsZero() || read.Before(oldest)) {
+				oldest = read
+			}
+		}
+	}
+	if oldest.IsZero() {
+		return time.Time{}, false
+	}
+	return oldest.Add(e.budget), true
+}
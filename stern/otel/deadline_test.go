@@ -0,0 +1,112 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// deadlineRecordingExporter records the deadline of each export
+type deadlineRecordingExporter struct {
+	mockLogRecordExporter
+	deadlines []time.Time
+}
+
+func (d *deadlineRecordingExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	deadline, _ := ctx.Deadline()
+	d.deadlines = append(d.deadlines, deadline)
+	return ctx.Err()
+}
+
+func TestDeadlineExporter(t *testing.T) {
+	recorder := &deadlineRecordingExporter{}
+	exporter := &deadlineExporter{Exporter: recorder, budget: 10 * time.Second}
+
+	newBatch := func(ages ...time.Duration) []sdklog.Record {
+		records := make([]sdklog.Record, len(ages))
+		for i, age := range ages {
+			records[i].SetObservedTimestamp(time.Now().Add(-age))
+		}
+		return records
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	if err := exporter.Export(ctx, newBatch(time.Second, 0)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := exporter.Export(ctx, newBatch(0, 6*time.Second, time.Second)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(recorder.deadlines) != 2 {
+		t.Fatalf("expected 2 exports, got %d", len(recorder.deadlines))
+	}
+
+	fresh, old := recorder.deadlines[0].Sub(start), recorder.deadlines[1].Sub(start)
+	if fresh < 8500*time.Millisecond || fresh > 9500*time.Millisecond {
+		t.Errorf("expected a deadline about 9s away for a batch read 1s ago, got %v", fresh)
+	}
+	if old < 3500*time.Millisecond || old > 4500*time.Millisecond {
+		t.Errorf("expected a deadline about 4s away for a batch read 6s ago, got %v", old)
+	}
+
+	// A batch older than the budget fails without waiting
+	if err := exporter.Export(ctx, newBatch(20*time.Second)); err != context.DeadlineExceeded {
+		t.Errorf("expected the stale batch to fail fast, got %v", err)
+	}
+
+	// Records without timestamps keep the export timeout
+	if err := exporter.Export(ctx, make([]sdklog.Record, 1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if deadline, _ := ctx.Deadline(); !recorder.deadlines[3].Equal(deadline) {
+		t.Errorf("expected the export timeout deadline, got %v", recorder.deadlines[3])
+	}
+}
+
+func TestNewExporterDeadlineFromReadTime(t *testing.T) {
+	recorder := &deadlineRecordingExporter{}
+	config := &ExporterConfig{
+		BatchSize:      512,
+		ExportTimeout:  time.Minute,
+		DeadlineBudget: 10 * time.Second,
+		Transform:      TransformConfig{TimestampFields: DefaultTimestampFields},
+	}
+	exporter := newExporter(config, resource.Empty(), recorder)
+
+	// A line backfilled with --since: both the body and the kubelet times,
+	// which becomes the observed timestamp, are an hour old
+	kubelet := time.Now().Add(-time.Hour)
+	body := `{"ts":"` + kubelet.Add(-time.Second).Format(time.RFC3339Nano) + `","msg":"started"}`
+	start := time.Now()
+	exporter.Emit(context.Background(), &LogRecord{Timestamp: kubelet, Body: body})
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(recorder.deadlines) != 1 {
+		t.Fatalf("expected 1 export, got %d", len(recorder.deadlines))
+	}
+	if left := recorder.deadlines[0].Sub(start); left < 9500*time.Millisecond || left > 10500*time.Millisecond {
+		t.Errorf("expected a deadline about 10s after the line was read, got %v", left)
+	}
+}
@@ -0,0 +1,208 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+)
+
+// DefaultDedupNormalize matches the variable tokens of error messages:
+// timestamps, UUIDs, hexadecimal identifiers and numbers
+const DefaultDedupNormalize = `\d{4}-\d{2}-\d{2}[T ][0-9:.]+(Z|[+-]\d{2}:?\d{2})?` +
+	`|[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}` +
+	`|0x[0-9a-fA-F]+|\b[0-9a-fA-F]{16,}\b|\d+`
+
+// DedupConfig configures the emission of only the first occurrence of each
+// error signature per window
+type DedupConfig struct {
+	// Window is the suppression interval. Deduplication is disabled when zero.
+	Window time.Duration
+	// Normalize is a regular expression matching the variable tokens removed
+	// from error messages to compute their signature. Defaults to DefaultDedupNormalize.
+	Normalize string
+}
+
+// Enabled reports whether deduplication is turned on
+func (c DedupConfig) Enabled() bool {
+	return c.Window > 0
+}
+
+// validate checks the normalization expression
+func (c DedupConfig) validate() error {
+	if _, err := regexp.Compile(c.Normalize); err != nil {
+		return fmt.Errorf("invalid dedup normalization expression: %w", err)
+	}
+	return nil
+}
+
+// dedupKey identifies an error signature of a container
+type dedupKey struct {
+	namespace string
+	pod       string
+	container string
+	signature string
+}
+
+// dedupEntry tracks a signature seen during the current window
+type dedupEntry struct {
+	message      string
+	severityText string
+	severity     log.Severity
+	suppressed   int64
+}
+
+// deduper passes the first error record of each signature and counts the
+// repeats, periodically emitting the counts. The severity is the one of the
+// emitted record, honoring the field keys, the floor, the rules and the
+// default severities of the transform.
+type deduper struct {
+	config    DedupConfig
+	normalize *regexp.Regexp
+	logger    log.Logger
+	transform TransformConfig
+
+	mu      sync.Mutex
+	entries map[dedupKey]*dedupEntry
+	order   []dedupKey
+
+	started  bool
+	stopOnce sync.Once
+	done     chan struct{}
+	stopped  chan struct{}
+}
+
+// newDeduper returns a deduper emitting the counts to logger. The config
+// must have been validated.
+func newDeduper(logger log.Logger, config DedupConfig, transform TransformConfig) *deduper {
+	if config.Normalize == "" {
+		config.Normalize = DefaultDedupNormalize
+	}
+	return &deduper{
+		config:    config,
+		normalize: regexp.MustCompile(config.Normalize),
+		logger:    logger,
+		transform: transform,
+		entries:   make(map[dedupKey]*dedupEntry),
+		done:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// start emits the counts every window until stop is called
+func (d *deduper) start() {
+	d.started = true
+	go func() {
+		defer close(d.stopped)
+		ticker := time.NewTicker(d.config.Window)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.flush(context.Background())
+			case <-d.done:
+				return
+			}
+		}
+	}()
+}
+
+// stop terminates the periodic emission and emits the pending counts
+func (d *deduper) stop(ctx context.Context) {
+	d.stopOnce.Do(func() {
+		close(d.done)
+		if d.started {
+			select {
+			case <-d.stopped:
+			case <-ctx.Done():
+			}
+		}
+		d.flush(ctx)
+	})
+}
+
+// admit reports whether the record must be emitted. Records below ERROR
+// are always emitted; errors are emitted once per signature and window.
+func (d *deduper) admit(record *LogRecord) bool {
+	message, severityText, severity := d.transform.messageAndSeverityOf(record)
+	if severity < log.SeverityError1 {
+		return true
+	}
+
+	key := dedupKey{
+		namespace: record.Namespace,
+		pod:       record.PodName,
+		container: record.ContainerName,
+		signature: d.normalize.ReplaceAllString(message, "#"),
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if entry, ok := d.entries[key]; ok {
+		entry.suppressed++
+		return false
+	}
+	d.entries[key] = &dedupEntry{message: message, severityText: severityText, severity: severity}
+	d.order = append(d.order, key)
+	return true
+}
+
+// flush emits a count record per signature with suppressed repeats and
+// starts a new window
+func (d *deduper) flush(ctx context.Context) {
+	d.mu.Lock()
+	entries, order := d.entries, d.order
+	d.entries = make(map[dedupKey]*dedupEntry)
+	d.order = nil
+	d.mu.Unlock()
+
+	now := time.Now()
+	for _, key := range order {
+		if entry := entries[key]; entry.suppressed > 0 {
+			d.logger.Emit(ctx, dedupRecord(key, entry, now))
+		}
+	}
+}
+
+// dedupRecord builds the record reporting the suppressed repeats of a signature
+func dedupRecord(key dedupKey, entry *dedupEntry, now time.Time) log.Record {
+	attrs := []log.KeyValue{
+		log.Int64("stern.dedup.count", entry.suppressed),
+		log.String("stern.dedup.signature", key.signature),
+	}
+	if key.namespace != "" {
+		attrs = append(attrs, log.String("k8s.namespace.name", key.namespace))
+	}
+	if key.pod != "" {
+		attrs = append(attrs, log.String("k8s.pod.name", key.pod))
+	}
+	if key.container != "" {
+		attrs = append(attrs, log.String("k8s.container.name", key.container))
+	}
+
+	logRecord := log.Record{}
+	logRecord.SetTimestamp(now)
+	logRecord.SetObservedTimestamp(now)
+	logRecord.SetBody(log.StringValue(fmt.Sprintf("suppressed %d repeats of %q", entry.suppressed, entry.message)))
+	logRecord.SetSeverity(entry.severity)
+	logRecord.SetSeverityText(entry.severityText)
+	logRecord.AddAttributes(attrs...)
+	return logRecord
+}
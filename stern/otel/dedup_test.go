@@ -0,0 +1,159 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestDeduperSuppressesRepeatedErrors(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	d := newDeduper(logger, DedupConfig{Window: 10 * time.Second}, TransformConfig{})
+
+	newRecord := func(body string) *LogRecord {
+		return &LogRecord{
+			Timestamp:     time.Now(),
+			Body:          body,
+			Namespace:     "default",
+			PodName:       "web",
+			ContainerName: "app",
+		}
+	}
+
+	tests := []struct {
+		body     string
+		expected bool
+	}{
+		{`{"level":"error","msg":"request 1234 failed at 2025-01-01T00:00:00Z"}`, true},
+		{`{"level":"error","msg":"request 5678 failed at 2025-01-01T00:00:05Z"}`, false},
+		{`{"level":"error","msg":"request 9012 failed at 2025-01-01T00:00:10Z"}`, false},
+		{`{"level":"error","msg":"connection to 550e8400-e29b-41d4-a716-446655440000 lost"}`, true},
+		{`{"level":"info","msg":"request 1234 served"}`, true},
+		{`{"level":"info","msg":"request 1234 served"}`, true},
+		{"plain text", true},
+	}
+	for i, tt := range tests {
+		if got := d.admit(newRecord(tt.body)); got != tt.expected {
+			t.Errorf("%d: expected admit=%v for %s, got %v", i, tt.expected, tt.body, got)
+		}
+	}
+
+	d.flush(context.Background())
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 1 {
+		t.Fatalf("expected 1 count record, got %d", len(mockExporter.records))
+	}
+	record := mockExporter.records[0]
+	if record.Severity() != log.SeverityError1 {
+		t.Errorf("expected the severity of the error, got %v", record.Severity())
+	}
+	var count int64
+	var signature string
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		switch kv.Key {
+		case "stern.dedup.count":
+			count = kv.Value.AsInt64()
+		case "stern.dedup.signature":
+			signature = kv.Value.AsString()
+		}
+		return true
+	})
+	if count != 2 {
+		t.Errorf("expected 2 suppressed repeats, got %d", count)
+	}
+	if signature != "request # failed at #" {
+		t.Errorf("expected the normalized signature, got %q", signature)
+	}
+
+	// A new window emits the first occurrence again
+	if !d.admit(newRecord(`{"level":"error","msg":"request 3456 failed at 2025-01-01T00:00:20Z"}`)) {
+		t.Error("expected the first occurrence of a new window to be emitted")
+	}
+}
+
+func TestDeduperCustomNormalize(t *testing.T) {
+	config := DedupConfig{Window: time.Minute, Normalize: `user=\w+`}
+	if err := config.validate(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := newDeduper(nil, config, TransformConfig{})
+
+	if !d.admit(&LogRecord{Body: `{"level":"error","msg":"denied user=alice"}`}) {
+		t.Error("expected the first occurrence to be emitted")
+	}
+	if d.admit(&LogRecord{Body: `{"level":"error","msg":"denied user=bob"}`}) {
+		t.Error("expected a repeat with another user to be suppressed")
+	}
+
+	if err := (DedupConfig{Normalize: "("}).validate(); err == nil {
+		t.Error("expected error for an invalid expression")
+	}
+}
+
+func TestDeduperSeverityOfTransform(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+
+	rules, err := ParseSeverityRules([]string{"panic=fatal"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transform := TransformConfig{
+		SeverityKeys:  []string{"lvl"},
+		SeverityRules: rules,
+	}
+	d := newDeduper(provider.Logger("test"), DedupConfig{Window: time.Minute}, transform)
+
+	tests := []struct {
+		body     string
+		expected bool
+	}{
+		{"panic: request 1234 failed", true},
+		{"panic: request 5678 failed", false},
+		{`{"lvl":"error","msg":"request 1234 failed"}`, true},
+		{`{"lvl":"error","msg":"request 5678 failed"}`, false},
+		{`{"lvl":"info","msg":"request 1234 served"}`, true},
+		{`{"lvl":"info","msg":"request 5678 served"}`, true},
+	}
+	for i, tt := range tests {
+		if got := d.admit(&LogRecord{Body: tt.body}); got != tt.expected {
+			t.Errorf("%d: expected admit=%v for %s, got %v", i, tt.expected, tt.body, got)
+		}
+	}
+
+	d.flush(context.Background())
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 count records, got %d", len(mockExporter.records))
+	}
+	if record := mockExporter.records[0]; record.Severity() != log.SeverityFatal1 || record.SeverityText() != "fatal" {
+		t.Errorf("expected the severity of the rule, got %v %q", record.Severity(), record.SeverityText())
+	}
+	if record := mockExporter.records[1]; record.Severity() != log.SeverityError1 || record.SeverityText() != "error" {
+		t.Errorf("expected the severity of the lvl key, got %v %q", record.Severity(), record.SeverityText())
+	}
+}
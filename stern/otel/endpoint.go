@@ -0,0 +1,54 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// resolveEndpoint returns the configuration with an endpoint URL, e.g.
+// "https://otlp.example.com:4318/v1/logs" as copied from the docs of a
+// vendor, split into the host and port of Endpoint and the path of the HTTP
+// exporter. The scheme sets Insecure. A bare host and port is kept as is.
+func (c *ExporterConfig) resolveEndpoint() (*ExporterConfig, error) {
+	if !strings.Contains(c.Endpoint, "://") {
+		return c, nil
+	}
+
+	u, err := url.Parse(c.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid OTel endpoint %q: %w", c.Endpoint, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid OTel endpoint %q: missing host", c.Endpoint)
+	}
+
+	resolved := *c
+	switch u.Scheme {
+	case "http":
+		resolved.Insecure = true
+	case "https":
+		resolved.Insecure = false
+	default:
+		return nil, fmt.Errorf("unsupported OTel endpoint scheme: %s (must be 'http' or 'https')", u.Scheme)
+	}
+	resolved.Endpoint = u.Host
+	if u.Path != "" && u.Path != "/" {
+		resolved.urlPath = u.Path
+	}
+	return &resolved, nil
+}
@@ -0,0 +1,99 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestResolveEndpoint(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		insecure bool
+		host     string
+		path     string
+		secure   bool
+		wantErr  bool
+	}{
+		{endpoint: "localhost:4317", insecure: true, host: "localhost:4317"},
+		{endpoint: "collector:4317", host: "collector:4317", secure: true},
+		{endpoint: "https://otlp.example.com:4318/v1/logs", insecure: true, host: "otlp.example.com:4318", path: "/v1/logs", secure: true},
+		{endpoint: "http://localhost:4318", host: "localhost:4318"},
+		{endpoint: "http://localhost:4318/", host: "localhost:4318"},
+		{endpoint: "https://otlp.example.com", insecure: true, host: "otlp.example.com", secure: true},
+		{endpoint: "ftp://localhost:4318", wantErr: true},
+		{endpoint: "http:///v1/logs", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		resolved, err := (&ExporterConfig{Endpoint: tt.endpoint, Insecure: tt.insecure}).resolveEndpoint()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error", tt.endpoint)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.endpoint, err)
+			continue
+		}
+		if resolved.Endpoint != tt.host || resolved.urlPath != tt.path || resolved.Insecure == tt.secure {
+			t.Errorf("%s: expected %s%s (secure %v), got %s%s (insecure %v)", tt.endpoint, tt.host, tt.path, tt.secure, resolved.Endpoint, resolved.urlPath, resolved.Insecure)
+		}
+	}
+}
+
+func TestNewExporterEndpointURL(t *testing.T) {
+	paths := make(chan string, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths <- r.URL.Path
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	config := &ExporterConfig{
+		Endpoint:      server.URL + "/otlp/v1/logs",
+		Protocol:      "http",
+		BatchSize:     1,
+		ExportTimeout: time.Minute,
+	}
+	exporter, err := NewExporter(context.Background(), config, resource.Empty())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "hello"})
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case path := <-paths:
+		if path != "/otlp/v1/logs" {
+			t.Errorf("expected the path of the URL, got %s", path)
+		}
+	default:
+		t.Error("expected the record to be exported over plain HTTP")
+	}
+	if config.Endpoint != server.URL+"/otlp/v1/logs" {
+		t.Errorf("expected the configuration to be left unchanged, got %s", config.Endpoint)
+	}
+}
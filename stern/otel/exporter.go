@@ -17,6 +17,9 @@ package otel
 import (
 	"context"
 	"fmt"
+	"os"
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploggrpc"
@@ -25,78 +28,337 @@ import (
 	sdklog "go.opentelemetry.io/otel/sdk/log"
 	"go.opentelemetry.io/otel/sdk/resource"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
 )
 
 // ExporterConfig holds configuration for the OTel exporter
 type ExporterConfig struct {
+	// Endpoint is the host and port of the collector, or its URL, e.g.
+	// "https://otlp.example.com:4318/v1/logs", whose scheme sets Insecure
 	Endpoint      string
-	Protocol      string // "grpc" or "http"
+	Protocol      string // "grpc", "http", "kafka", "gelf", "file" or "stdout"
 	Insecure      bool
 	BatchSize     int
 	ExportTimeout time.Duration
-	Headers       map[string]string
+	// MaxQueueSize is the number of records waiting to be exported beyond
+	// which new records are dropped. Defaults to two batches.
+	MaxQueueSize int
+	// ExportInterval is the delay after which a partial batch is exported.
+	// Defaults to the one of the OTel SDK, a second.
+	ExportInterval time.Duration
+	// MaxBatchBytes splits the batches whose records add up to more than
+	// this many bytes. Zero disables the limit.
+	MaxBatchBytes int
+	// Compression is the compression of the exported payloads, one of the
+	// Compression* values. Empty leaves them uncompressed.
+	Compression string
+	// ShutdownTimeout bounds how long Shutdown waits for pending logs to be
+	// flushed, independent of ExportTimeout. Defaults to 30 seconds.
+	ShutdownTimeout time.Duration
+	Headers         map[string]string
+	// HeadersFile holds "key: value" headers of the gRPC and HTTP exports,
+	// e.g. credentials kept out of the process arguments, overriding the
+	// Headers of the same name
+	HeadersFile string
+	// CACertFile is a PEM file of the certificate authorities verifying the
+	// collector. Setting it enables TLS regardless of Insecure.
+	CACertFile string
+	// ClientCertFile and ClientKeyFile are the PEM files of the certificate
+	// authenticating stern to collectors requiring mutual TLS. Setting them
+	// enables TLS regardless of Insecure.
+	ClientCertFile string
+	ClientKeyFile  string
+	// DeadlineBudget bounds each export to the time left before its oldest
+	// record has waited this long since it was read. Zero disables it.
+	DeadlineBudget time.Duration
+	// RespectRetryAfter makes the HTTP exporter wait for the delay of the
+	// Retry-After header of throttled exports instead of its own backoff
+	RespectRetryAfter bool
+	// Retry configures the retry of the gRPC and HTTP exports
+	Retry RetryConfig
+	// TokenProvider returns the bearer token of the Authorization header of
+	// the gRPC and HTTP exports, before each export, e.g. fetched from an
+	// OIDC provider and cached until it expires. It takes precedence over
+	// TokenFile and the static Headers.
+	TokenProvider TokenFunc
+	// TokenFile holds the bearer token, e.g. a projected service account
+	// token, read again once TokenRefresh has passed, a minute by default
+	TokenFile    string
+	TokenRefresh time.Duration
+	// PreflightTimeout bounds a check that the gRPC or HTTP endpoint accepts
+	// connections, made by NewExporter. Zero skips it, e.g. when the
+	// endpoint is expected to come up later.
+	PreflightTimeout time.Duration
+	// DryRun prints the records to stderr as indented OTLP/JSON, after the
+	// attributes of the resource, instead of exporting them. The endpoint is
+	// never contacted, whatever the protocol.
+	DryRun bool
+	// FilePath is the file the "file" protocol appends the records to, as
+	// newline-delimited OTLP/JSON, e.g. to upload them from an air-gapped
+	// cluster later
+	FilePath string
+	// FallbackFile receives the batches the collector fails to export, as
+	// newline-delimited OTLP/JSON. Empty disables the fallback.
+	FallbackFile string
+	// ReplayBuffer is the number of records that failed to export kept in
+	// memory and replayed once the collector recovers, e.g. through a
+	// collector rollout. Zero disables the buffer.
+	ReplayBuffer int
+	Aggregate    AggregateConfig
+	Dedup        DedupConfig
+	Sample       SampleConfig
+	Transform    TransformConfig
+	Kafka        KafkaConfig
+	GELF         GELFConfig
+
+	// urlPath is the path of the endpoint URL, sent to by the HTTP exporter
+	urlPath string
+}
+
+// defaultShutdownTimeout bounds Shutdown when ExporterConfig.ShutdownTimeout
+// is not set, so that an unreachable collector cannot hang stern on exit
+const defaultShutdownTimeout = 30 * time.Second
+
+// shutdownTimeout returns the bound of the flush of the pending logs on exit
+func (c *ExporterConfig) shutdownTimeout() time.Duration {
+	if c == nil || c.ShutdownTimeout <= 0 {
+		return defaultShutdownTimeout
+	}
+	return c.ShutdownTimeout
 }
 
+// Compressions of the exported payloads
+const (
+	CompressionNone = "none"
+	CompressionGzip = "gzip"
+)
+
 // Exporter wraps the OTel SDK components
 type Exporter struct {
 	loggerProvider *sdklog.LoggerProvider
 	logger         log.Logger
 	config         *ExporterConfig
+	aggregator     *aggregator
+	deduper        *deduper
+	sampler        *sampler
+
+	emitted  atomic.Int64
+	exported *countingExporter
+	queue    *queueProcessor
+	stats    *exportStats
+	fallback *fallbackExporter
+	replay   *replayExporter
+	draining atomic.Bool
+	// late counts the records emitted once draining began, dropped
+	late atomic.Int64
+}
+
+// DrainResult reports the records flushed and dropped by Drain
+type DrainResult struct {
+	Flushed int64
+	Dropped int64
 }
 
 // NewExporter creates a new OTel exporter with the given configuration
 func NewExporter(ctx context.Context, config *ExporterConfig, res *resource.Resource) (*Exporter, error) {
-	if config.Endpoint == "" {
+	if err := config.Aggregate.validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Sample.validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Dedup.validate(); err != nil {
+		return nil, err
+	}
+	if err := config.Transform.validate(); err != nil {
+		return nil, err
+	}
+
+	// Print the records without contacting the endpoint
+	if config.DryRun {
+		logExporter, err := newDryRunExporter(os.Stderr, res)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTel log exporter: %w", err)
+		}
+		return newExporter(config, res, logExporter), nil
+	}
+
+	if config.Protocol == "file" && config.FilePath == "" {
+		return nil, fmt.Errorf("OTel file path is required")
+	}
+	if config.Endpoint == "" && config.Protocol != "stdout" && config.Protocol != "file" && !(config.Protocol == "kafka" && len(config.Kafka.Brokers) > 0) {
 		return nil, fmt.Errorf("OTel endpoint is required")
 	}
 
+	if err := config.Retry.validate(); err != nil {
+		return nil, err
+	}
+	if config.Protocol == "grpc" || config.Protocol == "http" {
+		resolved, err := config.resolveEndpoint()
+		if err != nil {
+			return nil, err
+		}
+		config = resolved
+
+		if resolved, err = config.resolveHeaders(); err != nil {
+			return nil, err
+		}
+		config = resolved
+	}
+	switch config.Compression {
+	case "", CompressionNone, CompressionGzip:
+	default:
+		return nil, fmt.Errorf("unsupported compression: %s (must be 'gzip' or 'none')", config.Compression)
+	}
+
+	if config.ReplayBuffer < 0 {
+		return nil, fmt.Errorf("OTel replay buffer must not be negative, got %d", config.ReplayBuffer)
+	}
+	if config.ReplayBuffer > 0 && config.FallbackFile != "" {
+		return nil, fmt.Errorf("OTel replay buffer and fallback file are mutually exclusive")
+	}
+
+	if err := preflight(ctx, config); err != nil {
+		return nil, err
+	}
+
 	var logExporter sdklog.Exporter
 	var err error
 
+	stats := &exportStats{}
 	switch config.Protocol {
 	case "grpc":
-		logExporter, err = newGRPCExporter(ctx, config)
+		logExporter, err = newGRPCExporter(ctx, config, stats)
 	case "http":
-		logExporter, err = newHTTPExporter(ctx, config)
+		logExporter, err = newHTTPExporter(ctx, config, stats)
+	case "kafka":
+		logExporter, err = newKafkaExporter(config)
+	case "gelf":
+		logExporter, err = newGELFExporter(config)
+	case "file":
+		logExporter, err = newFileExporter(config.FilePath)
+	case "stdout":
+		// Print to stderr, keeping stdout for the tailed lines
+		logExporter = newStdoutExporter(os.Stderr)
 	default:
-		return nil, fmt.Errorf("unsupported protocol: %s (must be 'grpc' or 'http')", config.Protocol)
+		return nil, fmt.Errorf("unsupported protocol: %s (must be 'grpc', 'http', 'kafka', 'gelf', 'file' or 'stdout')", config.Protocol)
 	}
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create OTel log exporter: %w", err)
 	}
 
-	// Create batch processor
-	batchProcessor := sdklog.NewBatchProcessor(
-		logExporter,
-		sdklog.WithMaxQueueSize(config.BatchSize*2),
+	// Keep the batches the collector fails to export in memory until it
+	// recovers
+	var replay *replayExporter
+	if config.ReplayBuffer > 0 {
+		replay = &replayExporter{Exporter: logExporter, max: config.ReplayBuffer, batchSize: config.BatchSize}
+		logExporter = replay
+	}
+
+	// Keep the batches the collector fails to export in a local file
+	var fallback *fallbackExporter
+	if config.FallbackFile != "" {
+		file, err := newFileExporter(config.FallbackFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create OTel fallback exporter: %w", err)
+		}
+		fallback = &fallbackExporter{primary: logExporter, fallback: file}
+		logExporter = fallback
+	}
+
+	exporter := newExporter(config, res, logExporter)
+	exporter.stats = stats
+	exporter.fallback = fallback
+	exporter.replay = replay
+	return exporter, nil
+}
+
+// newExporter wires the batch processor and logger provider around logExporter
+func newExporter(config *ExporterConfig, res *resource.Resource, logExporter sdklog.Exporter) *Exporter {
+	// Fail stale batches fast
+	var deadline *deadlineExporter
+	if config.DeadlineBudget > 0 {
+		deadline = &deadlineExporter{Exporter: logExporter, budget: config.DeadlineBudget}
+		logExporter = deadline
+	}
+	// Keep the batches under the payload limit of the collector
+	if config.MaxBatchBytes > 0 {
+		logExporter = &batchBytesExporter{Exporter: logExporter, maxBytes: config.MaxBatchBytes}
+	}
+	exported := &countingExporter{Exporter: logExporter}
+
+	// Count the records dropped on a full queue, which the batch processor
+	// only logs. The records dequeued but not yet handed to the exporter
+	// count as queued too, and the queue of the batch processor has room
+	// for two more batches, so that the batch processor itself never drops.
+	maxQueued := config.MaxQueueSize
+	if maxQueued <= 0 {
+		maxQueued = config.BatchSize * 2
+	}
+	options := []sdklog.BatchProcessorOption{
+		sdklog.WithMaxQueueSize(maxQueued + config.BatchSize*2),
 		sdklog.WithExportMaxBatchSize(config.BatchSize),
 		sdklog.WithExportTimeout(config.ExportTimeout),
-	)
+	}
+	if config.ExportInterval > 0 {
+		options = append(options, sdklog.WithExportInterval(config.ExportInterval))
+	}
+	batchProcessor := sdklog.NewBatchProcessor(exported, options...)
+	queue := &queueProcessor{Processor: batchProcessor, exported: exported, deadline: deadline, maxQueued: int64(maxQueued)}
 
 	// Create logger provider
 	loggerProvider := sdklog.NewLoggerProvider(
 		sdklog.WithResource(res),
-		sdklog.WithProcessor(batchProcessor),
+		sdklog.WithProcessor(queue),
 	)
 
-	logger := loggerProvider.Logger("stern")
-
-	return &Exporter{
+	exporter := &Exporter{
 		loggerProvider: loggerProvider,
-		logger:         logger,
 		config:         config,
-	}, nil
+		exported:       exported,
+		queue:          queue,
+		stats:          &exportStats{},
+	}
+	exporter.logger = &countingLogger{Logger: loggerProvider.Logger("stern"), emitted: &exporter.emitted}
+
+	// Emit per-window counts instead of individual records
+	if config.Aggregate.Enabled() {
+		exporter.aggregator = newAggregator(exporter.logger, config.Aggregate)
+		exporter.aggregator.start()
+	}
+
+	// Sample the records below the kept severity
+	if config.Sample.Enabled() {
+		exporter.sampler = newSampler(config.Sample, config.Transform)
+	}
+
+	// Emit the first occurrence of each error signature and count the repeats
+	if config.Dedup.Enabled() {
+		exporter.deduper = newDeduper(exporter.logger, config.Dedup, config.Transform)
+		exporter.deduper.start()
+	}
+
+	return exporter
 }
 
 // newGRPCExporter creates a gRPC OTLP log exporter
-func newGRPCExporter(ctx context.Context, config *ExporterConfig) (sdklog.Exporter, error) {
+func newGRPCExporter(ctx context.Context, config *ExporterConfig, stats *exportStats) (sdklog.Exporter, error) {
 	opts := []otlploggrpc.Option{
 		otlploggrpc.WithEndpoint(config.Endpoint),
+		otlploggrpc.WithDialOption(grpc.WithUnaryInterceptor(throttleInterceptor(stats))),
 	}
 
-	if config.Insecure {
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig != nil {
+		opts = append(opts, otlploggrpc.WithTLSCredentials(credentials.NewTLS(tlsConfig)))
+	} else if config.Insecure {
 		opts = append(opts, otlploggrpc.WithInsecure())
 		opts = append(opts, otlploggrpc.WithDialOption(grpc.WithTransportCredentials(insecure.NewCredentials())))
 	}
@@ -105,16 +367,42 @@ func newGRPCExporter(ctx context.Context, config *ExporterConfig) (sdklog.Export
 		opts = append(opts, otlploggrpc.WithHeaders(config.Headers))
 	}
 
+	if token := config.tokenProvider(); token != nil {
+		opts = append(opts, otlploggrpc.WithDialOption(grpc.WithPerRPCCredentials(tokenCredentials{token: token})))
+	}
+
+	if config.Compression == CompressionGzip {
+		opts = append(opts, otlploggrpc.WithCompressor(gzip.Name))
+	}
+
+	retry := config.Retry.withDefaults()
+	opts = append(opts, otlploggrpc.WithRetry(otlploggrpc.RetryConfig{
+		Enabled:         !retry.Disabled,
+		InitialInterval: retry.InitialInterval,
+		MaxInterval:     retry.MaxInterval,
+		MaxElapsedTime:  retry.MaxElapsedTime,
+	}))
+
 	return otlploggrpc.New(ctx, opts...)
 }
 
 // newHTTPExporter creates an HTTP OTLP log exporter
-func newHTTPExporter(ctx context.Context, config *ExporterConfig) (sdklog.Exporter, error) {
+func newHTTPExporter(ctx context.Context, config *ExporterConfig, stats *exportStats) (sdklog.Exporter, error) {
 	opts := []otlploghttp.Option{
 		otlploghttp.WithEndpoint(config.Endpoint),
 	}
+	if config.urlPath != "" {
+		opts = append(opts, otlploghttp.WithURLPath(config.urlPath))
+	}
+
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
 
-	if config.Insecure {
+	if tlsConfig != nil {
+		opts = append(opts, otlploghttp.WithTLSClientConfig(tlsConfig))
+	} else if config.Insecure {
 		opts = append(opts, otlploghttp.WithInsecure())
 	}
 
@@ -122,7 +410,44 @@ func newHTTPExporter(ctx context.Context, config *ExporterConfig) (sdklog.Export
 		opts = append(opts, otlploghttp.WithHeaders(config.Headers))
 	}
 
-	return otlploghttp.New(ctx, opts...)
+	if config.Compression == CompressionGzip {
+		opts = append(opts, otlploghttp.WithCompression(otlploghttp.GzipCompression))
+	}
+
+	retry := config.Retry.withDefaults()
+	if !config.RespectRetryAfter || retry.Disabled {
+		opts = append(opts, otlploghttp.WithRetry(otlploghttp.RetryConfig{
+			Enabled:         !retry.Disabled,
+			InitialInterval: retry.InitialInterval,
+			MaxInterval:     retry.MaxInterval,
+			MaxElapsedTime:  retry.MaxElapsedTime,
+		}))
+		return newAuthenticatedHTTPExporter(ctx, config, opts)
+	}
+
+	// Retry in retryAfterExporter, which honors the Retry-After header
+	opts = append(opts, otlploghttp.WithRetry(otlploghttp.RetryConfig{Enabled: false}))
+	exporter, err := newAuthenticatedHTTPExporter(ctx, config, opts)
+	if err != nil {
+		return nil, err
+	}
+	return newRetryAfterExporter(exporter, stats, retry), nil
+}
+
+// newAuthenticatedHTTPExporter creates the HTTP exporter, authenticating the
+// exports with the token of the configuration if any
+func newAuthenticatedHTTPExporter(ctx context.Context, config *ExporterConfig, opts []otlploghttp.Option) (sdklog.Exporter, error) {
+	token := config.tokenProvider()
+	if token == nil {
+		return otlploghttp.New(ctx, opts...)
+	}
+	return &tokenExporter{
+		token:  token,
+		static: config.Headers,
+		create: func(ctx context.Context, headers map[string]string) (sdklog.Exporter, error) {
+			return otlploghttp.New(ctx, append(slices.Clip(opts), otlploghttp.WithHeaders(headers))...)
+		},
+	}, nil
 }
 
 // Logger returns the OTel logger instance
@@ -130,14 +455,124 @@ func (e *Exporter) Logger() log.Logger {
 	return e.logger
 }
 
-// Shutdown gracefully shuts down the exporter, flushing any pending logs
+// Emit transforms the record and sends it to the OTel logger, or counts it
+// when aggregation is enabled. Low-severity records are sampled and repeated
+// errors suppressed when enabled. Heartbeats are never dropped or aggregated.
+// Records are dropped once Drain has been called.
+func (e *Exporter) Emit(ctx context.Context, record *LogRecord) {
+	if e.draining.Load() {
+		e.emitted.Add(1)
+		e.late.Add(1)
+		return
+	}
+	if e.sampler != nil && !record.Heartbeat && !e.sampler.admit(record) {
+		return
+	}
+	if e.deduper != nil && !record.Heartbeat && !e.deduper.admit(record) {
+		return
+	}
+	if e.aggregator != nil && !record.Heartbeat {
+		e.aggregator.add(record)
+		return
+	}
+	EmitLogWithConfig(ctx, e.logger, record, &e.config.Transform)
+}
+
+// ShutdownTimeout returns the bound of the flush of the pending logs on exit
+func (e *Exporter) ShutdownTimeout() time.Duration {
+	return e.config.shutdownTimeout()
+}
+
+// Shutdown gracefully shuts down the exporter, flushing any pending logs.
+// The flush is bounded by ShutdownTimeout.
 func (e *Exporter) Shutdown(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, e.config.shutdownTimeout())
+	defer cancel()
+	if e.aggregator != nil {
+		e.aggregator.stop(ctx)
+	}
+	if e.deduper != nil {
+		e.deduper.stop(ctx)
+	}
 	if e.loggerProvider != nil {
 		return e.loggerProvider.Shutdown(ctx)
 	}
 	return nil
 }
 
+// Drain stops accepting records, emits the pending aggregation and
+// deduplication counts and flushes the pending records, e.g. on SIGTERM
+// within the termination grace period. The flush is bounded by
+// ShutdownTimeout, so that the dropped records are reported even when the
+// collector is unreachable. Shutdown must still be called.
+func (e *Exporter) Drain(ctx context.Context) (DrainResult, error) {
+	e.draining.Store(true)
+	ctx, cancel := context.WithTimeout(ctx, e.config.shutdownTimeout())
+	defer cancel()
+	if e.aggregator != nil {
+		e.aggregator.stop(ctx)
+	}
+	if e.deduper != nil {
+		e.deduper.stop(ctx)
+	}
+
+	pending := e.Pending()
+	err := e.ForceFlush(ctx)
+	dropped := e.Pending()
+	return DrainResult{Flushed: pending - dropped, Dropped: dropped}, err
+}
+
+// Stats returns the export statistics
+func (e *Exporter) Stats() Stats {
+	var stats Stats
+	stats.Emitted = e.emitted.Load()
+	stats.Exported = e.exportedCount()
+	stats.Dropped = e.late.Load()
+	if e.queue != nil {
+		stats.Dropped += e.queue.dropped.Load()
+	}
+	if e.stats != nil {
+		stats.Throttled = e.stats.throttled.Load()
+	}
+	if e.fallback != nil {
+		stats.Fallback = e.fallback.count.Load()
+	}
+	if e.replay != nil {
+		stats.Replayed, stats.Buffered, stats.Overflowed = e.replay.snapshot()
+	}
+	return stats
+}
+
+// exportedCount returns the number of records the collector accepted, the
+// records replayed from the buffer included
+func (e *Exporter) exportedCount() int64 {
+	var exported int64
+	if e.exported != nil {
+		exported = e.exported.count.Load()
+	}
+	if e.replay != nil {
+		exported += e.replay.replayed.Load()
+	}
+	return exported
+}
+
+// Pending returns the number of records emitted but not yet exported, the
+// ones waiting in the replay buffer included, excluding the records dropped
+// on a full queue or replay buffer or once draining
+func (e *Exporter) Pending() int64 {
+	if e.exported == nil {
+		return 0
+	}
+	pending := e.emitted.Load() - e.exportedCount() - e.late.Load()
+	if e.queue != nil {
+		pending -= e.queue.dropped.Load()
+	}
+	if e.replay != nil {
+		pending -= e.replay.overflowed.Load()
+	}
+	return pending
+}
+
 // ForceFlush immediately exports all pending logs
 func (e *Exporter) ForceFlush(ctx context.Context) error {
 	if e.loggerProvider != nil {
@@ -145,3 +580,60 @@ func (e *Exporter) ForceFlush(ctx context.Context) error {
 	}
 	return nil
 }
+
+// countingLogger counts the records handed to the wrapped logger
+type countingLogger struct {
+	log.Logger
+	emitted *atomic.Int64
+}
+
+// Emit counts the record and forwards it to the wrapped logger
+func (l *countingLogger) Emit(ctx context.Context, record log.Record) {
+	l.emitted.Add(1)
+	l.Logger.Emit(ctx, record)
+}
+
+// countingExporter counts the records handed to the wrapped exporter and
+// the ones successfully exported
+type countingExporter struct {
+	sdklog.Exporter
+	received atomic.Int64
+	count    atomic.Int64
+}
+
+// Export forwards the records and counts them on success
+func (c *countingExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	c.received.Add(int64(len(records)))
+	if err := c.Exporter.Export(ctx, records); err != nil {
+		return err
+	}
+	c.count.Add(int64(len(records)))
+	return nil
+}
+
+// queueProcessor bounds the records not yet handed to the exporter, counting
+// the records it drops when the bound is reached. The bound is the queue
+// size of the batch processor, which therefore never drops records itself.
+// A zero bound disables the counting.
+type queueProcessor struct {
+	sdklog.Processor
+	exported *countingExporter
+	// deadline, if any, is told when each record was read
+	deadline  *deadlineExporter
+	maxQueued int64
+	accepted  atomic.Int64
+	dropped   atomic.Int64
+}
+
+// OnEmit forwards the record unless the queue is full
+func (p *queueProcessor) OnEmit(ctx context.Context, record *sdklog.Record) error {
+	if p.maxQueued > 0 && p.accepted.Add(1)-p.exported.received.Load() > p.maxQueued {
+		p.accepted.Add(-1)
+		p.dropped.Add(1)
+		return nil
+	}
+	if p.deadline != nil {
+		p.deadline.read(time.Now())
+	}
+	return p.Processor.OnEmit(ctx, record)
+}
@@ -0,0 +1,406 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// blockingLogRecordExporter blocks every export until the context is done
+type blockingLogRecordExporter struct {
+	mockLogRecordExporter
+}
+
+func (b *blockingLogRecordExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestExporterShutdownTimeout(t *testing.T) {
+	config := &ExporterConfig{
+		BatchSize:       512,
+		ExportTimeout:   time.Minute,
+		ShutdownTimeout: 100 * time.Millisecond,
+	}
+	exporter := newExporter(config, resource.Empty(), &blockingLogRecordExporter{})
+
+	for i := 0; i < 3; i++ {
+		exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "pending"})
+	}
+	if pending := exporter.Pending(); pending != 3 {
+		t.Fatalf("expected 3 pending records, got %d", pending)
+	}
+
+	start := time.Now()
+	err := exporter.Shutdown(context.Background())
+	elapsed := time.Since(start)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded error, got %v", err)
+	}
+	if elapsed > 5*time.Second {
+		t.Errorf("expected shutdown to respect the 100ms timeout, took %v", elapsed)
+	}
+	if pending := exporter.Pending(); pending != 3 {
+		t.Errorf("expected 3 records to remain pending, got %d", pending)
+	}
+}
+
+func TestExporterShutdownTimeoutDefault(t *testing.T) {
+	tests := []struct {
+		config *ExporterConfig
+		want   time.Duration
+	}{
+		{nil, defaultShutdownTimeout},
+		{&ExporterConfig{}, defaultShutdownTimeout},
+		{&ExporterConfig{ShutdownTimeout: -time.Second}, defaultShutdownTimeout},
+		{&ExporterConfig{ShutdownTimeout: time.Second}, time.Second},
+	}
+	for _, tt := range tests {
+		if got := tt.config.shutdownTimeout(); got != tt.want {
+			t.Errorf("expected shutdown timeout %v for %+v, got %v", tt.want, tt.config, got)
+		}
+	}
+}
+
+func TestExporterShutdownFlushes(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	config := &ExporterConfig{
+		BatchSize:       512,
+		ExportTimeout:   time.Minute,
+		ShutdownTimeout: 5 * time.Second,
+	}
+	exporter := newExporter(config, resource.Empty(), mockExporter)
+
+	for i := 0; i < 3; i++ {
+		exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "pending"})
+	}
+
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mockExporter.records) != 3 {
+		t.Errorf("expected 3 flushed records, got %d", len(mockExporter.records))
+	}
+	if pending := exporter.Pending(); pending != 0 {
+		t.Errorf("expected no pending records, got %d", pending)
+	}
+	if stats := exporter.Stats(); stats.Emitted != 3 || stats.Exported != 3 || stats.Dropped != 0 {
+		t.Errorf("expected 3 emitted and exported records, got %+v", stats)
+	}
+}
+
+func TestExporterStatsDropped(t *testing.T) {
+	config := &ExporterConfig{
+		BatchSize:       2,
+		ExportTimeout:   time.Minute,
+		ShutdownTimeout: 100 * time.Millisecond,
+	}
+	exporter := newExporter(config, resource.Empty(), &blockingLogRecordExporter{})
+	defer exporter.Shutdown(context.Background())
+
+	for i := 0; i < 20; i++ {
+		exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "burst"})
+	}
+
+	// 4 records are queued, plus the batch blocked in the exporter if any
+	stats := exporter.Stats()
+	if stats.Emitted != 20 || stats.Exported != 0 {
+		t.Errorf("expected 20 emitted and no exported records, got %+v", stats)
+	}
+	if stats.Dropped < 14 || stats.Dropped > 16 {
+		t.Errorf("expected 14 to 16 dropped records, got %d", stats.Dropped)
+	}
+	if pending := exporter.Pending(); pending != 20-stats.Dropped {
+		t.Errorf("expected the dropped records not to be pending, got %d", pending)
+	}
+}
+
+func TestExporterMaxQueueSize(t *testing.T) {
+	config := &ExporterConfig{
+		BatchSize:       2,
+		MaxQueueSize:    16,
+		ExportTimeout:   time.Minute,
+		ShutdownTimeout: 100 * time.Millisecond,
+	}
+	exporter := newExporter(config, resource.Empty(), &blockingLogRecordExporter{})
+	defer exporter.Shutdown(context.Background())
+
+	for i := 0; i < 20; i++ {
+		exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "burst"})
+	}
+
+	// 16 records are queued, plus the batch blocked in the exporter if any
+	if stats := exporter.Stats(); stats.Dropped < 2 || stats.Dropped > 4 {
+		t.Errorf("expected 2 to 4 dropped records, got %d", stats.Dropped)
+	}
+}
+
+func TestExporterExportInterval(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	config := &ExporterConfig{
+		BatchSize:      512,
+		ExportInterval: 10 * time.Millisecond,
+		ExportTimeout:  time.Minute,
+	}
+	exporter := newExporter(config, resource.Empty(), mockExporter)
+	defer exporter.Shutdown(context.Background())
+
+	exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "partial batch"})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for exporter.Stats().Exported == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the partial batch to be exported after the interval")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestExporterHeartbeatBypassesAggregation(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	config := &ExporterConfig{
+		BatchSize:     512,
+		ExportTimeout: time.Minute,
+		Aggregate:     AggregateConfig{Window: time.Hour},
+	}
+	exporter := newExporter(config, resource.Empty(), mockExporter)
+
+	lastSeen := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	exporter.Emit(context.Background(), &LogRecord{
+		Timestamp: lastSeen.Add(time.Minute),
+		Body:      "no logs for 1m0s",
+		Heartbeat: true,
+		LastSeen:  lastSeen,
+	})
+	if err := exporter.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(mockExporter.records) != 1 {
+		t.Fatalf("expected the heartbeat to bypass aggregation, got %d records", len(mockExporter.records))
+	}
+	attrs := map[string]log.Value{}
+	mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+	if !attrs["stern.heartbeat"].AsBool() {
+		t.Error("expected stern.heartbeat=true")
+	}
+	if got := attrs["stern.heartbeat.last_seen"].AsString(); got != "2025-01-01T00:00:00Z" {
+		t.Errorf("expected stern.heartbeat.last_seen=2025-01-01T00:00:00Z, got %q", got)
+	}
+}
+
+func TestExporterDrain(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	config := &ExporterConfig{
+		BatchSize:       512,
+		ExportTimeout:   time.Minute,
+		ShutdownTimeout: 5 * time.Second,
+	}
+	exporter := newExporter(config, resource.Empty(), mockExporter)
+
+	for i := 0; i < 3; i++ {
+		exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "before drain"})
+	}
+
+	result, err := exporter.Drain(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != (DrainResult{Flushed: 3}) {
+		t.Errorf("expected 3 flushed and no dropped records, got %+v", result)
+	}
+	if len(mockExporter.records) != 3 {
+		t.Errorf("expected 3 exported records, got %d", len(mockExporter.records))
+	}
+
+	// Records emitted after the drain are dropped
+	exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "after drain"})
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(mockExporter.records) != 3 {
+		t.Errorf("expected no record exported after the drain, got %d", len(mockExporter.records)-3)
+	}
+	if stats := exporter.Stats(); stats.Emitted != 4 || stats.Dropped != 1 {
+		t.Errorf("expected the record emitted after the drain counted as dropped, got %+v", stats)
+	}
+	if pending := exporter.Pending(); pending != 0 {
+		t.Errorf("expected no pending records, got %d", pending)
+	}
+}
+
+func TestExporterDrainReportsDropped(t *testing.T) {
+	config := &ExporterConfig{
+		BatchSize:       512,
+		ExportTimeout:   time.Minute,
+		ShutdownTimeout: 100 * time.Millisecond,
+		Aggregate:       AggregateConfig{Window: time.Hour},
+	}
+	exporter := newExporter(config, resource.Empty(), &blockingLogRecordExporter{})
+
+	for i := 0; i < 3; i++ {
+		exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "aggregated", PodName: "my-pod"})
+	}
+
+	result, err := exporter.Drain(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded error, got %v", err)
+	}
+	// the aggregate of the three lines is emitted but cannot be exported
+	if result != (DrainResult{Dropped: 1}) {
+		t.Errorf("expected the aggregate record to be dropped, got %+v", result)
+	}
+}
+
+func TestExporterExitDeadline(t *testing.T) {
+	config := &ExporterConfig{
+		BatchSize:       512,
+		ExportTimeout:   time.Minute,
+		ShutdownTimeout: 200 * time.Millisecond,
+	}
+	exporter := newExporter(config, resource.Empty(), &blockingLogRecordExporter{})
+	exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "pending"})
+
+	// The drain and the shutdown share the deadline of the exit
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), exporter.ShutdownTimeout())
+	defer cancel()
+	if _, err := exporter.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the drain to exceed the deadline, got %v", err)
+	}
+	if err := exporter.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the shutdown to exceed the deadline, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 350*time.Millisecond {
+		t.Errorf("expected the exit to take the shutdown timeout once, took %v", elapsed)
+	}
+}
+
+func TestHTTPExporterCompression(t *testing.T) {
+	for _, compression := range []string{"", CompressionNone, CompressionGzip} {
+		t.Run(compression, func(t *testing.T) {
+			var encoding string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				encoding = r.Header.Get("Content-Encoding")
+				w.Header().Set("Content-Type", "application/x-protobuf")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			config := &ExporterConfig{
+				Endpoint:    strings.TrimPrefix(server.URL, "http://"),
+				Insecure:    true,
+				Compression: compression,
+			}
+			exporter, err := newHTTPExporter(context.Background(), config, &exportStats{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer exporter.Shutdown(context.Background())
+
+			var record sdklog.Record
+			record.SetBody(log.StringValue("compressed"))
+			if err := exporter.Export(context.Background(), []sdklog.Record{record}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected := ""
+			if compression == CompressionGzip {
+				expected = "gzip"
+			}
+			if encoding != expected {
+				t.Errorf("expected Content-Encoding %q, got %q", expected, encoding)
+			}
+		})
+	}
+
+	config := &ExporterConfig{Endpoint: "localhost:4318", Protocol: "http", Compression: "zstd", BatchSize: 1}
+	if _, err := NewExporter(context.Background(), config, resource.Empty()); err == nil {
+		t.Error("expected error for unsupported compression")
+	}
+}
+
+func TestExporterFileProtocol(t *testing.T) {
+	ctx := context.Background()
+	if _, err := NewExporter(ctx, &ExporterConfig{Protocol: "file", BatchSize: 10}, resource.Empty()); err == nil {
+		t.Error("expected an error without a file path")
+	}
+
+	path := filepath.Join(t.TempDir(), "logs.ndjson")
+	config := &ExporterConfig{
+		Protocol:      "file",
+		FilePath:      path,
+		BatchSize:     10,
+		ExportTimeout: time.Minute,
+	}
+	exporter, err := NewExporter(ctx, config, resource.Empty())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	exporter.Emit(ctx, &LogRecord{Timestamp: time.Unix(1700000000, 0), Body: `{"level":"error","msg":"failed"}`, Namespace: "default", PodName: "web-0"})
+	exporter.Emit(ctx, &LogRecord{Timestamp: time.Unix(1700000001, 0), Body: "plain"})
+	if err := exporter.ForceFlush(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := exporter.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %s", len(lines), data)
+	}
+	var logs otlpLogsData
+	if err := json.Unmarshal([]byte(lines[0]), &logs); err != nil {
+		t.Fatalf("invalid JSON %s: %v", lines[0], err)
+	}
+	record := logs.ResourceLogs[0].ScopeLogs[0].LogRecords[0]
+	if record.TimeUnixNano != "1700000000000000000" || record.SeverityNumber != int(log.SeverityError) {
+		t.Errorf("unexpected record %+v", record)
+	}
+	if record.Body == nil || record.Body.StringValue == nil || *record.Body.StringValue != "failed" {
+		t.Errorf("expected body failed, got %+v", record.Body)
+	}
+	var pod string
+	for _, kv := range record.Attributes {
+		if kv.Key == "k8s.pod.name" && kv.Value.StringValue != nil {
+			pod = *kv.Value.StringValue
+		}
+	}
+	if pod != "web-0" {
+		t.Errorf("expected k8s.pod.name web-0, got %q", pod)
+	}
+}
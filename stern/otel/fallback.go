@@ -0,0 +1,60 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// fallbackExporter writes the batches the primary exporter fails to export
+// to the fallback exporter, so that a collector outage loses no records.
+// Each batch is tried on the primary first, so exports resume there as soon
+// as it recovers.
+type fallbackExporter struct {
+	primary  sdklog.Exporter
+	fallback sdklog.Exporter
+	// count is the number of records written to the fallback
+	count atomic.Int64
+}
+
+// Export exports the records to the primary, or to the fallback when the
+// primary fails. It only fails when both do.
+func (e *fallbackExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	err := e.primary.Export(ctx, records)
+	if err == nil {
+		return nil
+	}
+	// The primary may have failed because ctx expired, which must not keep
+	// the records from the fallback
+	if fallbackErr := e.fallback.Export(context.WithoutCancel(ctx), records); fallbackErr != nil {
+		return errors.Join(err, fallbackErr)
+	}
+	e.count.Add(int64(len(records)))
+	return nil
+}
+
+// Shutdown shuts down both exporters
+func (e *fallbackExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.primary.Shutdown(ctx), e.fallback.Shutdown(ctx))
+}
+
+// ForceFlush flushes both exporters
+func (e *fallbackExporter) ForceFlush(ctx context.Context) error {
+	return errors.Join(e.primary.ForceFlush(ctx), e.fallback.ForceFlush(ctx))
+}
@@ -0,0 +1,118 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// outageExporter fails the exports while the collector is down
+type outageExporter struct {
+	mockLogRecordExporter
+	down bool
+}
+
+func (o *outageExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	if o.down {
+		return errors.New("connection refused")
+	}
+	return o.mockLogRecordExporter.Export(ctx, records)
+}
+
+func TestFallbackExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fallback.ndjson")
+	file, err := newFileExporter(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	primary := &outageExporter{}
+	exporter := &fallbackExporter{primary: primary, fallback: file}
+
+	newBatch := func(bodies ...string) []sdklog.Record {
+		records := make([]sdklog.Record, len(bodies))
+		for i, body := range bodies {
+			records[i].SetBody(log.StringValue(body))
+		}
+		return records
+	}
+
+	ctx := context.Background()
+	if err := exporter.Export(ctx, newBatch("before")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	primary.down = true
+	if err := exporter.Export(ctx, newBatch("during 1", "during 2")); err != nil {
+		t.Fatalf("expected the fallback to absorb the failure, got %v", err)
+	}
+	primary.down = false
+	if err := exporter.Export(ctx, newBatch("after")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := exporter.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var exported []string
+	for _, record := range primary.records {
+		exported = append(exported, record.Body().AsString())
+	}
+	if len(exported) != 2 || exported[0] != "before" || exported[1] != "after" {
+		t.Errorf("expected the primary to export the records before and after the outage, got %v", exported)
+	}
+	if count := exporter.count.Load(); count != 2 {
+		t.Errorf("expected 2 records written to the fallback, got %d", count)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer f.Close()
+
+	var written []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var data otlpLogsData
+		if err := json.Unmarshal(scanner.Bytes(), &data); err != nil {
+			t.Fatalf("expected an OTLP/JSON line, got %q: %v", scanner.Text(), err)
+		}
+		written = append(written, *data.ResourceLogs[0].ScopeLogs[0].LogRecords[0].Body.StringValue)
+	}
+	if len(written) != 2 || written[0] != "during 1" || written[1] != "during 2" {
+		t.Errorf("expected the records of the outage in the fallback file, got %v", written)
+	}
+}
+
+func TestFallbackExporterBothFail(t *testing.T) {
+	primary := &outageExporter{down: true}
+	fallback := &outageExporter{down: true}
+	exporter := &fallbackExporter{primary: primary, fallback: fallback}
+
+	if err := exporter.Export(context.Background(), make([]sdklog.Record, 1)); err == nil {
+		t.Error("expected an error when both exporters fail")
+	}
+	if count := exporter.count.Load(); count != 0 {
+		t.Errorf("expected no records counted, got %d", count)
+	}
+}
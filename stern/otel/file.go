@@ -0,0 +1,77 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// fileExporter appends the records to a file as newline-delimited OTLP/JSON
+// LogsData documents, the format read by the collector's otlpjsonfile receiver
+type fileExporter struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// newFileExporter opens path for appending, creating it if needed
+func newFileExporter(path string) (*fileExporter, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return &fileExporter{file: file}, nil
+}
+
+// Export writes the batch to the file in a single write
+func (e *fileExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	var buf bytes.Buffer
+	for i := range records {
+		data, err := marshalOTLPJSON(&records[i])
+		if err != nil {
+			return fmt.Errorf("failed to encode record: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.file.Write(buf.Bytes())
+	return err
+}
+
+// Shutdown syncs and closes the file
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err := e.file.Sync(); err != nil {
+		e.file.Close()
+		return err
+	}
+	return e.file.Close()
+}
+
+// ForceFlush syncs the file to disk
+func (e *fileExporter) ForceFlush(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.file.Sync()
+}
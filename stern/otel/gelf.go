@@ -0,0 +1,389 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// Supported values for GELFConfig.Transport
+const (
+	GELFTransportUDP = "udp"
+	GELFTransportTCP = "tcp"
+)
+
+// DefaultGELFChunkSize is the size of the UDP datagrams beyond which GELF
+// messages are chunked, safe for WAN paths
+const DefaultGELFChunkSize = 1420
+
+// GELF chunking: each chunk starts with the magic bytes, an 8-byte message
+// ID, its sequence number and the number of chunks, up to 128
+const (
+	gelfChunkHeaderSize = 12
+	gelfMaxChunks       = 128
+)
+
+var gelfChunkMagic = []byte{0x1e, 0x0f}
+
+// GELFConfig configures the "gelf" protocol, sending the records to the
+// host:port of ExporterConfig.Endpoint, e.g. a Graylog input
+type GELFConfig struct {
+	// Transport is "udp" (default) or "tcp"
+	Transport string
+	// ChunkSize is the size of the UDP datagrams beyond which messages are
+	// chunked. Defaults to DefaultGELFChunkSize.
+	ChunkSize int
+}
+
+// validate checks the transport and the chunk size
+func (c GELFConfig) validate() error {
+	switch c.Transport {
+	case "", GELFTransportUDP, GELFTransportTCP:
+	default:
+		return fmt.Errorf("unsupported GELF transport: %s (must be 'udp' or 'tcp')", c.Transport)
+	}
+	if c.ChunkSize != 0 && c.ChunkSize <= gelfChunkHeaderSize {
+		return fmt.Errorf("GELF chunk size must be larger than %d bytes, got %d", gelfChunkHeaderSize, c.ChunkSize)
+	}
+	return nil
+}
+
+// gelfExporter is an sdklog.Exporter sending one GELF message per record
+type gelfExporter struct {
+	address   string
+	transport string
+	chunkSize int
+	compress  bool
+	// host is the host of the records without host.name or k8s.node.name
+	host string
+
+	mu   sync.Mutex
+	conn net.Conn // dialed on the first export, and again after a failure
+}
+
+// newGELFExporter creates a GELF log exporter
+func newGELFExporter(config *ExporterConfig) (sdklog.Exporter, error) {
+	gelfConfig := config.GELF
+	if err := gelfConfig.validate(); err != nil {
+		return nil, err
+	}
+
+	e := &gelfExporter{
+		address:   config.Endpoint,
+		transport: gelfConfig.Transport,
+		chunkSize: gelfConfig.ChunkSize,
+		compress:  config.Compression == CompressionGzip,
+		host:      "stern",
+	}
+	if e.transport == "" {
+		e.transport = GELFTransportUDP
+	}
+	if e.chunkSize == 0 {
+		e.chunkSize = DefaultGELFChunkSize
+	}
+	// Null-delimited TCP messages cannot be compressed
+	if e.compress && e.transport == GELFTransportTCP {
+		return nil, fmt.Errorf("GELF over TCP does not support compression")
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		e.host = hostname
+	}
+	return e, nil
+}
+
+// gelfTruncated marks the short message cut to fit in a UDP message
+const gelfTruncated = "…"
+
+// Export sends the records, one message each. A record too large for the
+// chunks of a UDP message even once truncated is skipped, and reported
+// after the rest of the batch is sent.
+func (e *gelfExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var skipped int
+	for i := range records {
+		message := gelfMessage(&records[i], e.host)
+		payload, err := e.encode(message)
+		if err != nil {
+			return fmt.Errorf("failed to encode record: %w", err)
+		}
+		if e.transport == GELFTransportUDP {
+			if payload, err = e.fit(message, payload); err != nil {
+				skipped++
+				continue
+			}
+		}
+		if err := e.send(ctx, payload); err != nil {
+			// Dial again on the next export, e.g. once Graylog restarted
+			if e.conn != nil {
+				e.conn.Close()
+				e.conn = nil
+			}
+			return err
+		}
+	}
+	if skipped > 0 {
+		return fmt.Errorf("skipped %d GELF messages exceeding %d chunks of %d bytes", skipped, gelfMaxChunks, e.chunkSize)
+	}
+	return nil
+}
+
+// encode returns the payload of the message, compressed when enabled
+func (e *gelfExporter) encode(message map[string]interface{}) ([]byte, error) {
+	payload, err := json.Marshal(message)
+	if err != nil || !e.compress {
+		return payload, err
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// fit truncates the short message of a message too large for the chunks of
+// a UDP message, e.g. a huge stack trace, so that the record is still sent.
+// It fails when the other fields alone do not fit.
+func (e *gelfExporter) fit(message map[string]interface{}, payload []byte) ([]byte, error) {
+	limit := gelfMaxChunks * (e.chunkSize - gelfChunkHeaderSize)
+	body, _ := message["short_message"].(string)
+	keep := len(body)
+	for attempt := 0; len(payload) > limit; attempt++ {
+		if keep == 0 {
+			return nil, fmt.Errorf("GELF message of %d bytes exceeds %d chunks of %d bytes", len(payload), gelfMaxChunks, e.chunkSize)
+		}
+		// Cut the excess, then halve the rest when compression or escaping
+		// defeated the estimate
+		cut := len(payload) - limit + len(gelfTruncated)
+		if attempt > 0 {
+			cut = max(cut, keep/2)
+		}
+		keep = max(keep-cut, 0)
+		for keep > 0 && !utf8.RuneStart(body[keep]) {
+			keep--
+		}
+		message["short_message"] = body[:keep] + gelfTruncated
+
+		var err error
+		if payload, err = e.encode(message); err != nil {
+			return nil, err
+		}
+	}
+	return payload, nil
+}
+
+// send writes the message on the connection, dialing it first if needed
+func (e *gelfExporter) send(ctx context.Context, payload []byte) error {
+	if e.conn == nil {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, e.transport, e.address)
+		if err != nil {
+			return fmt.Errorf("failed to connect to GELF endpoint %s: %w", e.address, err)
+		}
+		e.conn = conn
+	}
+	// Without a deadline, the zero time clears the one of the last export
+	deadline, _ := ctx.Deadline()
+	if err := e.conn.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+
+	if e.transport == GELFTransportTCP {
+		_, err := e.conn.Write(append(payload, 0))
+		return err
+	}
+
+	datagrams, err := gelfChunks(payload, e.chunkSize)
+	if err != nil {
+		return err
+	}
+	for _, datagram := range datagrams {
+		if _, err := e.conn.Write(datagram); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gelfChunks splits the payload into datagrams of at most chunkSize bytes,
+// chunked as GELF requires when it does not fit in one
+func gelfChunks(payload []byte, chunkSize int) ([][]byte, error) {
+	if len(payload) <= chunkSize {
+		return [][]byte{payload}, nil
+	}
+
+	dataSize := chunkSize - gelfChunkHeaderSize
+	count := (len(payload) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("GELF message of %d bytes exceeds %d chunks of %d bytes", len(payload), gelfMaxChunks, chunkSize)
+	}
+
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	datagrams := make([][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		data := payload[i*dataSize : min((i+1)*dataSize, len(payload))]
+		datagram := make([]byte, 0, gelfChunkHeaderSize+len(data))
+		datagram = append(datagram, gelfChunkMagic...)
+		datagram = append(datagram, id...)
+		datagram = append(datagram, byte(i), byte(count))
+		datagrams = append(datagrams, append(datagram, data...))
+	}
+	return datagrams, nil
+}
+
+// gelfMessage maps the record to a GELF 1.1 message: the body is the short
+// message, the severity the syslog level, and the attributes of the
+// resource and the record additional fields, e.g. _k8s_pod_name
+func gelfMessage(record *sdklog.Record, defaultHost string) map[string]interface{} {
+	message := map[string]interface{}{
+		"version":       "1.1",
+		"short_message": record.Body().String(),
+		"timestamp":     float64(record.Timestamp().UnixMicro()) / 1e6,
+	}
+	// short_message is required to be non-empty
+	if message["short_message"] == "" {
+		message["short_message"] = "-"
+	}
+	if level, ok := gelfLevel(record.Severity()); ok {
+		message["level"] = level
+	}
+
+	res := record.Resource()
+	for _, kv := range res.Attributes() {
+		message[gelfFieldName(string(kv.Key))] = gelfAttributeValue(kv.Value)
+	}
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		message[gelfFieldName(kv.Key)] = gelfValue(kv.Value)
+		return true
+	})
+	if traceID := record.TraceID(); traceID.IsValid() {
+		message["_trace_id"] = traceID.String()
+	}
+	if spanID := record.SpanID(); spanID.IsValid() {
+		message["_span_id"] = spanID.String()
+	}
+
+	host := defaultHost
+	for _, key := range []string{"_host_name", "_k8s_node_name"} {
+		if name, ok := message[key].(string); ok && name != "" {
+			host = name
+			break
+		}
+	}
+	message["host"] = host
+	return message
+}
+
+// gelfLevel maps the severity to its syslog level, if any
+func gelfLevel(severity log.Severity) (int, bool) {
+	switch {
+	case severity >= log.SeverityFatal1:
+		return 2, true // critical
+	case severity >= log.SeverityError1:
+		return 3, true
+	case severity >= log.SeverityWarn1:
+		return 4, true
+	case severity >= log.SeverityInfo1:
+		return 6, true
+	case severity >= log.SeverityTrace1:
+		return 7, true // debug
+	default:
+		return 0, false
+	}
+}
+
+// gelfFieldName returns the additional field of the attribute, prefixed by
+// an underscore and with the characters GELF does not allow, including the
+// dots, replaced by underscores. "_id" is reserved.
+func gelfFieldName(key string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, key)
+	if name == "id" {
+		name = "id_"
+	}
+	return "_" + name
+}
+
+// gelfValue converts the value to a string or a number, the only types of
+// GELF fields, encoding slices and maps as JSON
+func gelfValue(v log.Value) interface{} {
+	switch v.Kind() {
+	case log.KindString, log.KindInt64, log.KindFloat64:
+		return jsonLogValue(v)
+	case log.KindBool:
+		return v.String()
+	case log.KindEmpty:
+		return ""
+	default:
+		data, err := json.Marshal(jsonLogValue(v))
+		if err != nil {
+			return v.String()
+		}
+		return string(data)
+	}
+}
+
+// gelfAttributeValue converts a resource attribute as gelfValue does
+func gelfAttributeValue(v attribute.Value) interface{} {
+	switch v.Type() {
+	case attribute.STRING, attribute.INT64, attribute.FLOAT64:
+		return v.AsInterface()
+	default:
+		return v.Emit()
+	}
+}
+
+// Shutdown closes the connection
+func (e *gelfExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// ForceFlush is a no-op since Export writes synchronously
+func (e *gelfExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,320 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// gelfRecord returns the record EmitLog shapes from the line
+func gelfRecord(t *testing.T, body string) sdklog.Record {
+	t.Helper()
+	mockExporter := &mockLogRecordExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(mockExporter)))
+	EmitLog(context.Background(), provider.Logger("test"), &LogRecord{
+		Timestamp:     time.Date(2025, 1, 1, 12, 0, 0, 500000000, time.UTC),
+		Body:          body,
+		Namespace:     "shop",
+		PodName:       "payments-0",
+		ContainerName: "app",
+		NodeName:      "node-1",
+	})
+	provider.ForceFlush(context.Background())
+	if len(mockExporter.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+	}
+	return mockExporter.records[0]
+}
+
+func TestGELFMessage(t *testing.T) {
+	record := gelfRecord(t, `{"level":"error","msg":"payment failed","id":42,"retry":true,"tags":["a","b"]}`)
+	message := gelfMessage(&record, "stern")
+
+	for key, want := range map[string]interface{}{
+		"version":             "1.1",
+		"host":                "node-1",
+		"short_message":       "payment failed",
+		"timestamp":           1735732800.5,
+		"level":               3,
+		"_k8s_namespace_name": "shop",
+		"_k8s_pod_name":       "payments-0",
+		"_k8s_container_name": "app",
+		"_id_":                int64(42),
+		"_retry":              "true",
+		"_tags":               `["a","b"]`,
+	} {
+		if got := message[key]; !reflect.DeepEqual(want, got) {
+			t.Errorf("expected %s %v (%T), got %v (%T)", key, want, want, got, got)
+		}
+	}
+
+	plain := gelfRecord(t, "")
+	message = gelfMessage(&plain, "stern")
+	if message["short_message"] != "-" {
+		t.Errorf("expected a placeholder short message, got %v", message["short_message"])
+	}
+	if _, ok := message["level"]; ok {
+		t.Errorf("expected no level without a severity, got %v", message["level"])
+	}
+}
+
+func TestGELFLevel(t *testing.T) {
+	tests := []struct {
+		severity log.Severity
+		level    int
+		ok       bool
+	}{
+		{log.SeverityUndefined, 0, false},
+		{log.SeverityTrace1, 7, true},
+		{log.SeverityDebug4, 7, true},
+		{log.SeverityInfo1, 6, true},
+		{log.SeverityWarn2, 4, true},
+		{log.SeverityError1, 3, true},
+		{log.SeverityFatal1, 2, true},
+	}
+	for _, tt := range tests {
+		if level, ok := gelfLevel(tt.severity); level != tt.level || ok != tt.ok {
+			t.Errorf("%v: expected %d %v, got %d %v", tt.severity, tt.level, tt.ok, level, ok)
+		}
+	}
+}
+
+func TestGELFChunks(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 100)
+
+	datagrams, err := gelfChunks(payload, 200)
+	if err != nil || len(datagrams) != 1 || !bytes.Equal(datagrams[0], payload) {
+		t.Fatalf("expected the payload unchunked, got %d datagrams, %v", len(datagrams), err)
+	}
+
+	datagrams, err = gelfChunks(payload, 52)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(datagrams) != 3 {
+		t.Fatalf("expected 3 chunks, got %d", len(datagrams))
+	}
+	var joined []byte
+	for i, datagram := range datagrams {
+		if len(datagram) > 52 || !bytes.Equal(datagram[:2], gelfChunkMagic) {
+			t.Errorf("chunk %d: unexpected header % x", i, datagram[:min(len(datagram), 12)])
+		}
+		if !bytes.Equal(datagram[2:10], datagrams[0][2:10]) {
+			t.Errorf("chunk %d: expected the message ID of the first chunk", i)
+		}
+		if datagram[10] != byte(i) || datagram[11] != 3 {
+			t.Errorf("chunk %d: expected sequence %d/3, got %d/%d", i, i, datagram[10], datagram[11])
+		}
+		joined = append(joined, datagram[12:]...)
+	}
+	if !bytes.Equal(joined, payload) {
+		t.Errorf("expected the chunks to join into the payload, got %q", joined)
+	}
+
+	if _, err := gelfChunks(bytes.Repeat([]byte("x"), 129*10), 22); err == nil {
+		t.Error("expected an error beyond 128 chunks")
+	}
+}
+
+func TestGELFExporterUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	exporter, err := newGELFExporter(&ExporterConfig{
+		Endpoint:    conn.LocalAddr().String(),
+		Compression: CompressionGzip,
+		GELF:        GELFConfig{Transport: GELFTransportUDP},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer exporter.Shutdown(context.Background())
+
+	record := gelfRecord(t, `{"level":"warn","msg":"slow request"}`)
+	if err := exporter.Export(context.Background(), []sdklog.Record{record}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	buf := make([]byte, 65536)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(buf[:n]))
+	if err != nil {
+		t.Fatalf("expected a gzipped datagram: %v", err)
+	}
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var message map[string]interface{}
+	if err := json.Unmarshal(data, &message); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	if message["short_message"] != "slow request" || message["level"] != float64(4) {
+		t.Errorf("unexpected message %v", message)
+	}
+}
+
+func TestGELFExporterUDPOversized(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	// 128 chunks of 88 bytes hold 11264 bytes
+	exporter, err := newGELFExporter(&ExporterConfig{
+		Endpoint: conn.LocalAddr().String(),
+		GELF:     GELFConfig{Transport: GELFTransportUDP, ChunkSize: 100},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer exporter.Shutdown(context.Background())
+
+	huge := strings.Repeat("é", 20000)
+	records := []sdklog.Record{
+		gelfRecord(t, "first"),
+		gelfRecord(t, huge),
+		gelfRecord(t, `{"msg":"huge field","blob":"`+huge+`"}`),
+		gelfRecord(t, "last"),
+	}
+	err = exporter.Export(context.Background(), records)
+	if err == nil || !strings.Contains(err.Error(), "skipped 1 GELF messages") {
+		t.Errorf("expected the record with the huge field to be skipped, got %v", err)
+	}
+
+	// Join the chunks of each message, sent in order
+	var messages []map[string]interface{}
+	var chunks []byte
+	buf := make([]byte, 65536)
+	for len(messages) < 3 {
+		conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, _, err := conn.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("expected 3 messages, got %d: %v", len(messages), err)
+		}
+		data := buf[:n]
+		if bytes.HasPrefix(data, gelfChunkMagic) {
+			chunks = append(chunks, data[gelfChunkHeaderSize:]...)
+			if data[10] != data[11]-1 {
+				continue
+			}
+			data, chunks = chunks, nil
+		}
+		var message map[string]interface{}
+		if err := json.Unmarshal(data, &message); err != nil {
+			t.Fatalf("invalid JSON %s: %v", data, err)
+		}
+		messages = append(messages, message)
+	}
+
+	if messages[0]["short_message"] != "first" || messages[2]["short_message"] != "last" {
+		t.Errorf("expected the rest of the batch to be sent, got %v and %v", messages[0]["short_message"], messages[2]["short_message"])
+	}
+	truncated, _ := messages[1]["short_message"].(string)
+	if !strings.HasSuffix(truncated, gelfTruncated) || !strings.HasPrefix(huge, strings.TrimSuffix(truncated, gelfTruncated)) || len(truncated) < 10000 {
+		t.Errorf("expected the short message to be truncated to fit, got %d bytes", len(truncated))
+	}
+}
+
+func TestGELFExporterTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+		var messages []string
+		for len(messages) < 2 {
+			message, err := reader.ReadString(0)
+			if err != nil {
+				break
+			}
+			messages = append(messages, strings.TrimSuffix(message, "\x00"))
+		}
+		received <- messages
+	}()
+
+	exporter, err := newGELFExporter(&ExporterConfig{
+		Endpoint: listener.Addr().String(),
+		GELF:     GELFConfig{Transport: GELFTransportTCP},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer exporter.Shutdown(context.Background())
+
+	records := []sdklog.Record{gelfRecord(t, "first"), gelfRecord(t, "second")}
+	if err := exporter.Export(context.Background(), records); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case messages := <-received:
+		if len(messages) != 2 {
+			t.Fatalf("expected 2 null-delimited messages, got %q", messages)
+		}
+		for i, want := range []string{"first", "second"} {
+			var message map[string]interface{}
+			if err := json.Unmarshal([]byte(messages[i]), &message); err != nil {
+				t.Fatalf("invalid JSON %s: %v", messages[i], err)
+			}
+			if message["short_message"] != want {
+				t.Errorf("expected %q, got %v", want, message["short_message"])
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the messages")
+	}
+}
+
+func TestNewGELFExporterInvalidConfig(t *testing.T) {
+	for _, config := range []*ExporterConfig{
+		{Endpoint: "graylog:12201", GELF: GELFConfig{Transport: "http"}},
+		{Endpoint: "graylog:12201", GELF: GELFConfig{ChunkSize: 12}},
+		{Endpoint: "graylog:12201", Compression: CompressionGzip, GELF: GELFConfig{Transport: GELFTransportTCP}},
+	} {
+		if _, err := newGELFExporter(config); err == nil {
+			t.Errorf("expected an error for %+v", config)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Identity sources accepted by ResolveIdentity besides a literal value
+const (
+	// IdentityKubeconfig uses the user of the current kubeconfig context
+	IdentityKubeconfig = "kubeconfig"
+	// IdentityEnvPrefix reads the identity from the named environment variable
+	IdentityEnvPrefix = "env:"
+)
+
+// ResolveIdentity returns the value of the stern.identity attribute for
+// spec, which is "kubeconfig", "env:<NAME>" or a literal identity
+func ResolveIdentity(spec string, clientConfig clientcmd.ClientConfig) (string, error) {
+	switch {
+	case spec == IdentityKubeconfig:
+		if clientConfig == nil {
+			return "", fmt.Errorf("no kubeconfig to read the identity from")
+		}
+		rawConfig, err := clientConfig.RawConfig()
+		if err != nil {
+			return "", fmt.Errorf("failed to read the kubeconfig: %w", err)
+		}
+		kubeContext, ok := rawConfig.Contexts[rawConfig.CurrentContext]
+		if !ok || kubeContext.AuthInfo == "" {
+			return "", fmt.Errorf("the current kubeconfig context has no user")
+		}
+		return kubeContext.AuthInfo, nil
+	case strings.HasPrefix(spec, IdentityEnvPrefix):
+		name := strings.TrimPrefix(spec, IdentityEnvPrefix)
+		identity := os.Getenv(name)
+		if identity == "" {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return identity, nil
+	default:
+		return spec, nil
+	}
+}
@@ -0,0 +1,93 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestResolveIdentity(t *testing.T) {
+	t.Setenv("STERN_TEST_IDENTITY", "ci-pipeline")
+
+	kubeconfig := clientcmdapi.NewConfig()
+	kubeconfig.Contexts["prod"] = &clientcmdapi.Context{Cluster: "prod", AuthInfo: "alice"}
+	kubeconfig.CurrentContext = "prod"
+	clientConfig := clientcmd.NewDefaultClientConfig(*kubeconfig, nil)
+
+	tests := []struct {
+		spec     string
+		expected string
+		wantErr  bool
+	}{
+		{spec: "", expected: ""},
+		{spec: "log-forwarder", expected: "log-forwarder"},
+		{spec: "env:STERN_TEST_IDENTITY", expected: "ci-pipeline"},
+		{spec: "env:STERN_TEST_UNSET", wantErr: true},
+		{spec: "kubeconfig", expected: "alice"},
+	}
+	for _, tt := range tests {
+		identity, err := ResolveIdentity(tt.spec, clientConfig)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected error", tt.spec)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.spec, err)
+		}
+		if identity != tt.expected {
+			t.Errorf("%q: expected %q, got %q", tt.spec, tt.expected, identity)
+		}
+	}
+}
+
+func TestEmitLogWithIdentity(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: "configured"}, &TransformConfig{Identity: "alice"})
+	EmitLog(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: "unconfigured"})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(mockExporter.records))
+	}
+	identityOf := func(r sdklog.Record) string {
+		var identity string
+		r.WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == "stern.identity" {
+				identity = kv.Value.AsString()
+			}
+			return true
+		})
+		return identity
+	}
+	if got := identityOf(mockExporter.records[0]); got != "alice" {
+		t.Errorf("expected stern.identity=alice, got %q", got)
+	}
+	if got := identityOf(mockExporter.records[1]); got != "" {
+		t.Errorf("expected no stern.identity attribute, got %q", got)
+	}
+}
@@ -0,0 +1,140 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/log/embedded"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// JSONEncoder encodes records as JSON objects in the shape of the OTel
+// records emitted by EmitLogWithConfig, to inspect locally, e.g. with jq,
+// what would be exported
+type JSONEncoder struct {
+	config *TransformConfig
+}
+
+// jsonRecord is the JSON object of a record, with the attributes as a map
+type jsonRecord struct {
+	Timestamp         string                 `json:"timestamp,omitempty"`
+	ObservedTimestamp string                 `json:"observedTimestamp,omitempty"`
+	SeverityText      string                 `json:"severityText,omitempty"`
+	SeverityNumber    int                    `json:"severityNumber,omitempty"`
+	Body              interface{}            `json:"body,omitempty"`
+	Attributes        map[string]interface{} `json:"attributes,omitempty"`
+	TraceID           string                 `json:"traceId,omitempty"`
+	SpanID            string                 `json:"spanId,omitempty"`
+}
+
+// NewJSONEncoder creates an encoder shaping the records with config
+func NewJSONEncoder(config *TransformConfig) (*JSONEncoder, error) {
+	if config == nil {
+		config = &TransformConfig{}
+	}
+	if err := config.validate(); err != nil {
+		return nil, err
+	}
+	return &JSONEncoder{config: config}, nil
+}
+
+// Encode transforms the record as EmitLogWithConfig does and encodes it.
+// It returns nil when the record is skipped, e.g. below MinSeverity.
+func (e *JSONEncoder) Encode(record *LogRecord) ([]byte, error) {
+	logger := &recordingLogger{}
+	EmitLogWithConfig(context.Background(), logger, record, e.config)
+	if !logger.emitted {
+		return nil, nil
+	}
+
+	out := jsonRecord{
+		Timestamp:         formatTime(logger.record.Timestamp()),
+		ObservedTimestamp: formatTime(logger.record.ObservedTimestamp()),
+		SeverityText:      logger.record.SeverityText(),
+		SeverityNumber:    int(logger.record.Severity()),
+		Body:              jsonLogValue(logger.record.Body()),
+	}
+	logger.record.WalkAttributes(func(kv log.KeyValue) bool {
+		if out.Attributes == nil {
+			out.Attributes = map[string]interface{}{}
+		}
+		out.Attributes[kv.Key] = jsonLogValue(kv.Value)
+		return true
+	})
+	if spanContext := trace.SpanContextFromContext(logger.ctx); spanContext.IsValid() {
+		out.TraceID = spanContext.TraceID().String()
+		out.SpanID = spanContext.SpanID().String()
+	}
+	return json.Marshal(out)
+}
+
+// recordingLogger keeps the last record emitted, with its context
+type recordingLogger struct {
+	embedded.Logger
+	ctx     context.Context
+	record  log.Record
+	emitted bool
+}
+
+func (l *recordingLogger) Emit(ctx context.Context, record log.Record) {
+	l.ctx, l.record, l.emitted = ctx, record, true
+}
+
+func (l *recordingLogger) Enabled(ctx context.Context, param log.EnabledParameters) bool {
+	return true
+}
+
+// formatTime formats t in RFC 3339 with nanoseconds in its location, e.g.
+// the one of --timezone, or empty when unset
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+// jsonLogValue converts a log attribute or body value to its JSON value
+func jsonLogValue(v log.Value) interface{} {
+	switch v.Kind() {
+	case log.KindString:
+		return v.AsString()
+	case log.KindBool:
+		return v.AsBool()
+	case log.KindInt64:
+		return v.AsInt64()
+	case log.KindFloat64:
+		return v.AsFloat64()
+	case log.KindBytes:
+		return v.AsBytes()
+	case log.KindSlice:
+		values := []interface{}{}
+		for _, item := range v.AsSlice() {
+			values = append(values, jsonLogValue(item))
+		}
+		return values
+	case log.KindMap:
+		values := map[string]interface{}{}
+		for _, kv := range v.AsMap() {
+			values[kv.Key] = jsonLogValue(kv.Value)
+		}
+		return values
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,89 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestJSONEncoder(t *testing.T) {
+	encoder, err := NewJSONEncoder(&TransformConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := encoder.Encode(&LogRecord{
+		Timestamp:     time.Date(2025, 1, 1, 12, 0, 0, 1, time.UTC),
+		Body:          `{"level":"error","msg":"payment failed","order_id":42,"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"00f067aa0ba902b7"}`,
+		Namespace:     "shop",
+		PodName:       "payments-0",
+		ContainerName: "app",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	for key, want := range map[string]interface{}{
+		"timestamp":      "2025-01-01T12:00:00.000000001Z",
+		"severityText":   "error",
+		"severityNumber": float64(17),
+		"body":           "payment failed",
+		"traceId":        "4bf92f3577b34da6a3ce929d0e0e4736",
+		"spanId":         "00f067aa0ba902b7",
+	} {
+		if !reflect.DeepEqual(got[key], want) {
+			t.Errorf("expected %s %v, got %v", key, want, got[key])
+		}
+	}
+	attrs, _ := got["attributes"].(map[string]interface{})
+	for key, want := range map[string]interface{}{
+		"k8s.namespace.name": "shop",
+		"k8s.pod.name":       "payments-0",
+		"k8s.container.name": "app",
+		"order_id":           float64(42),
+	} {
+		if !reflect.DeepEqual(attrs[key], want) {
+			t.Errorf("expected attribute %s %v, got %v", key, want, attrs[key])
+		}
+	}
+}
+
+func TestNewJSONEncoderInvalidConfig(t *testing.T) {
+	if _, err := NewJSONEncoder(&TransformConfig{SeverityFloor: "loud"}); err == nil {
+		t.Error("expected an error for an unsupported severity floor")
+	}
+}
+
+func TestJSONEncoderSkippedRecord(t *testing.T) {
+	encoder, err := NewJSONEncoder(&TransformConfig{MinSeverity: "warn"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := encoder.Encode(&LogRecord{Timestamp: time.Now(), Body: `{"level":"info","msg":"request served"}`})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if data != nil {
+		t.Errorf("expected no data for a record below the minimum severity, got %s", data)
+	}
+}
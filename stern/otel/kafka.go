@@ -0,0 +1,171 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// Supported values for KafkaConfig.Encoding
+const (
+	KafkaEncodingOTLPJSON = "otlp_json"
+	KafkaEncodingRaw      = "raw"
+)
+
+// KafkaConfig configures the "kafka" protocol
+type KafkaConfig struct {
+	// Brokers lists the bootstrap brokers. Defaults to the comma-separated
+	// ExporterConfig.Endpoint.
+	Brokers []string
+	// Topic receives one message per record
+	Topic string
+	// Key is the record attribute used as the message key, and so the
+	// partition key. Shorthands "namespace", "pod" and "container" map to
+	// the k8s attributes. Defaults to "pod".
+	Key string
+	// Encoding is "otlp_json" (default) or "raw" for the plain body
+	Encoding string
+}
+
+// keyAttribute returns the attribute name of the configured key
+func (c KafkaConfig) keyAttribute() string {
+	switch c.Key {
+	case "", "pod":
+		return "k8s.pod.name"
+	case "namespace":
+		return "k8s.namespace.name"
+	case "container":
+		return "k8s.container.name"
+	default:
+		return c.Key
+	}
+}
+
+// validate checks the topic and the encoding
+func (c KafkaConfig) validate() error {
+	if c.Topic == "" {
+		return fmt.Errorf("Kafka topic is required")
+	}
+	switch c.Encoding {
+	case "", KafkaEncodingOTLPJSON, KafkaEncodingRaw:
+	default:
+		return fmt.Errorf("unsupported Kafka encoding: %s (must be 'otlp_json' or 'raw')", c.Encoding)
+	}
+	return nil
+}
+
+// kafkaWriter is the part of kafka.Writer used by the exporter
+type kafkaWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// kafkaExporter is an sdklog.Exporter producing one Kafka message per record
+type kafkaExporter struct {
+	writer kafkaWriter
+	config KafkaConfig
+}
+
+// newKafkaExporter creates a Kafka log exporter
+func newKafkaExporter(config *ExporterConfig) (sdklog.Exporter, error) {
+	kafkaConfig := config.Kafka
+	if err := kafkaConfig.validate(); err != nil {
+		return nil, err
+	}
+
+	brokers := kafkaConfig.Brokers
+	if len(brokers) == 0 {
+		brokers = strings.Split(config.Endpoint, ",")
+	}
+
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(brokers...),
+		Topic:        kafkaConfig.Topic,
+		Balancer:     &kafka.Hash{},
+		BatchSize:    config.BatchSize,
+		WriteTimeout: config.ExportTimeout,
+	}
+	if config.Compression == CompressionGzip {
+		writer.Compression = kafka.Gzip
+	}
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig == nil && !config.Insecure {
+		tlsConfig = &tls.Config{}
+	}
+	if tlsConfig != nil {
+		writer.Transport = &kafka.Transport{TLS: tlsConfig}
+	}
+
+	return &kafkaExporter{writer: writer, config: kafkaConfig}, nil
+}
+
+// Export produces the records to the topic
+func (e *kafkaExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	msgs := make([]kafka.Message, 0, len(records))
+	for i := range records {
+		msg, err := e.message(&records[i])
+		if err != nil {
+			return err
+		}
+		msgs = append(msgs, msg)
+	}
+	return e.writer.WriteMessages(ctx, msgs...)
+}
+
+// message builds the Kafka message of the record
+func (e *kafkaExporter) message(record *sdklog.Record) (kafka.Message, error) {
+	msg := kafka.Message{Time: record.Timestamp()}
+
+	keyAttribute := e.config.keyAttribute()
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		if kv.Key == keyAttribute {
+			msg.Key = []byte(kv.Value.String())
+			return false
+		}
+		return true
+	})
+
+	switch e.config.Encoding {
+	case KafkaEncodingRaw:
+		msg.Value = []byte(record.Body().String())
+	default:
+		value, err := marshalOTLPJSON(record)
+		if err != nil {
+			return kafka.Message{}, fmt.Errorf("failed to encode record: %w", err)
+		}
+		msg.Value = value
+	}
+	return msg, nil
+}
+
+// Shutdown flushes and closes the writer
+func (e *kafkaExporter) Shutdown(ctx context.Context) error {
+	return e.writer.Close()
+}
+
+// ForceFlush is a no-op since Export writes synchronously
+func (e *kafkaExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,129 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// mockKafkaWriter collects the produced messages
+type mockKafkaWriter struct {
+	messages []kafka.Message
+	closed   bool
+}
+
+func (m *mockKafkaWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	m.messages = append(m.messages, msgs...)
+	return nil
+}
+
+func (m *mockKafkaWriter) Close() error {
+	m.closed = true
+	return nil
+}
+
+func TestKafkaExporterMessage(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   KafkaConfig
+		key      string
+		validate func(t *testing.T, value []byte)
+	}{
+		{
+			name:   "otlp_json keyed by pod",
+			config: KafkaConfig{Topic: "logs"},
+			key:    "web-0",
+			validate: func(t *testing.T, value []byte) {
+				var data otlpLogsData
+				if err := json.Unmarshal(value, &data); err != nil {
+					t.Fatalf("invalid OTLP/JSON: %v", err)
+				}
+				rl := data.ResourceLogs[0]
+				if len(rl.Resource.Attributes) != 1 || rl.Resource.Attributes[0].Key != "k8s.cluster.name" {
+					t.Errorf("expected the resource attributes, got %+v", rl.Resource.Attributes)
+				}
+				record := rl.ScopeLogs[0].LogRecords[0]
+				if record.Body == nil || *record.Body.StringValue != "request served" {
+					t.Errorf("expected body 'request served', got %+v", record.Body)
+				}
+				if record.SeverityNumber != 9 || record.SeverityText != "info" {
+					t.Errorf("expected severity info (9), got %q (%d)", record.SeverityText, record.SeverityNumber)
+				}
+				if record.TimeUnixNano != "1735689600000000000" {
+					t.Errorf("expected timeUnixNano 1735689600000000000, got %s", record.TimeUnixNano)
+				}
+			},
+		},
+		{
+			name:   "raw keyed by namespace",
+			config: KafkaConfig{Topic: "logs", Key: "namespace", Encoding: KafkaEncodingRaw},
+			key:    "default",
+			validate: func(t *testing.T, value []byte) {
+				if string(value) != "request served" {
+					t.Errorf("expected the raw body, got %q", value)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &mockKafkaWriter{}
+			res := resource.NewSchemaless(attribute.String("k8s.cluster.name", "production"))
+			processor := sdklog.NewSimpleProcessor(&kafkaExporter{writer: writer, config: tt.config})
+			provider := sdklog.NewLoggerProvider(sdklog.WithResource(res), sdklog.WithProcessor(processor))
+
+			EmitLog(context.Background(), provider.Logger("test"), &LogRecord{
+				Timestamp:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				Body:          `{"level":"info","msg":"request served"}`,
+				Namespace:     "default",
+				PodName:       "web-0",
+				ContainerName: "app",
+			})
+			if err := provider.Shutdown(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if len(writer.messages) != 1 {
+				t.Fatalf("expected 1 message, got %d", len(writer.messages))
+			}
+			msg := writer.messages[0]
+			if string(msg.Key) != tt.key {
+				t.Errorf("expected key %q, got %q", tt.key, msg.Key)
+			}
+			tt.validate(t, msg.Value)
+			if !writer.closed {
+				t.Error("expected the writer to be closed on shutdown")
+			}
+		})
+	}
+}
+
+func TestKafkaConfigValidate(t *testing.T) {
+	if _, err := newKafkaExporter(&ExporterConfig{Endpoint: "localhost:9092"}); err == nil {
+		t.Error("expected error for missing topic")
+	}
+	if _, err := newKafkaExporter(&ExporterConfig{Endpoint: "localhost:9092", Kafka: KafkaConfig{Topic: "logs", Encoding: "avro"}}); err == nil {
+		t.Error("expected error for unsupported encoding")
+	}
+}
@@ -0,0 +1,63 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"regexp"
+	"strings"
+)
+
+// klogHeader matches the header of klog and glog lines, e.g.
+// `I0101 12:00:00.000000       1 server.go:10] message`: the severity letter,
+// the month and day, the time, the thread id and the source file and line
+var klogHeader = regexp.MustCompile(`^([IWEF])\d{4} \d{2}:\d{2}:\d{2}\.\d{6}\s+\d+ ([^\s\]]+:\d+)\] ?`)
+
+// klogSeverities maps the severity letters of klog to their level
+var klogSeverities = map[byte]string{
+	'I': "INFO",
+	'W': "WARN",
+	'E': "ERROR",
+	'F': "FATAL",
+}
+
+// parseKlog parses a klog or glog line into its message, its severity and
+// the caller attribute, e.g. "server.go:10". The quoted message and the
+// key="value" pairs of klog's structured logging are parsed too. Lines
+// without the header are not klog.
+func parseKlog(body string) (message string, severity string, structuredAttrs map[string]interface{}, isKlog bool) {
+	header := klogHeader.FindStringSubmatch(body)
+	if header == nil {
+		return body, "", nil, false
+	}
+	message = strings.TrimSpace(body[len(header[0]):])
+	structuredAttrs = map[string]interface{}{"caller": header[2]}
+
+	// Structured logging, e.g. `"Pod started" pod="default/web"`
+	if strings.HasPrefix(message, `"`) {
+		if quoted, rest, ok := unquoteLogfmt(message); ok {
+			if rest = strings.TrimSpace(rest); rest == "" {
+				message = quoted
+			} else if pairs, ok := parseLogfmt(rest); ok {
+				message = quoted
+				for key, value := range pairs {
+					if key != "caller" {
+						structuredAttrs[key] = value
+					}
+				}
+			}
+		}
+	}
+	return message, klogSeverities[body[0]], structuredAttrs, true
+}
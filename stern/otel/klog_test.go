@@ -0,0 +1,168 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestParseKlog(t *testing.T) {
+	tests := []struct {
+		name             string
+		line             string
+		expectedMessage  string
+		expectedSeverity string
+		expectedAttrs    map[string]interface{}
+		expectedOK       bool
+	}{
+		{
+			name:             "info",
+			line:             "I0101 12:00:00.000000       1 server.go:10] Starting server",
+			expectedMessage:  "Starting server",
+			expectedSeverity: "INFO",
+			expectedAttrs:    map[string]interface{}{"caller": "server.go:10"},
+			expectedOK:       true,
+		},
+		{
+			name:             "warning",
+			line:             "W1231 23:59:59.999999   12345 reflector.go:324] watch of *v1.Pod ended",
+			expectedMessage:  "watch of *v1.Pod ended",
+			expectedSeverity: "WARN",
+			expectedAttrs:    map[string]interface{}{"caller": "reflector.go:324"},
+			expectedOK:       true,
+		},
+		{
+			name:             "error",
+			line:             "E0615 08:30:00.123456 7 controller.go:99] sync failed: timeout",
+			expectedMessage:  "sync failed: timeout",
+			expectedSeverity: "ERROR",
+			expectedAttrs:    map[string]interface{}{"caller": "controller.go:99"},
+			expectedOK:       true,
+		},
+		{
+			name:             "fatal",
+			line:             "F0615 08:30:00.123456 7 main.go:1] cannot start",
+			expectedMessage:  "cannot start",
+			expectedSeverity: "FATAL",
+			expectedAttrs:    map[string]interface{}{"caller": "main.go:1"},
+			expectedOK:       true,
+		},
+		{
+			name:             "structured logging",
+			line:             `I0101 12:00:00.000000 1 kubelet.go:2453] "Pod started" pod="default/web" attempt="2"`,
+			expectedMessage:  "Pod started",
+			expectedSeverity: "INFO",
+			expectedAttrs:    map[string]interface{}{"caller": "kubelet.go:2453", "pod": "default/web", "attempt": "2"},
+			expectedOK:       true,
+		},
+		{
+			name:             "quoted text",
+			line:             `I0101 12:00:00.000000 1 main.go:5] "quoted" and more`,
+			expectedMessage:  `"quoted" and more`,
+			expectedSeverity: "INFO",
+			expectedAttrs:    map[string]interface{}{"caller": "main.go:5"},
+			expectedOK:       true,
+		},
+		{
+			name:            "plain text",
+			line:            "Starting server on :8080",
+			expectedMessage: "Starting server on :8080",
+		},
+		{
+			name:            "unknown severity letter",
+			line:            "D0101 12:00:00.000000 1 server.go:10] debug",
+			expectedMessage: "D0101 12:00:00.000000 1 server.go:10] debug",
+		},
+		{
+			name:            "no caller",
+			line:            "I0101 12:00:00.000000 1] message",
+			expectedMessage: "I0101 12:00:00.000000 1] message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, severity, attrs, ok := parseKlog(tt.line)
+			if ok != tt.expectedOK {
+				t.Errorf("ok = %v, expected %v", ok, tt.expectedOK)
+			}
+			if message != tt.expectedMessage {
+				t.Errorf("message = %q, expected %q", message, tt.expectedMessage)
+			}
+			if severity != tt.expectedSeverity {
+				t.Errorf("severity = %q, expected %q", severity, tt.expectedSeverity)
+			}
+			if !reflect.DeepEqual(attrs, tt.expectedAttrs) {
+				t.Errorf("attrs = %v, expected %v", attrs, tt.expectedAttrs)
+			}
+		})
+	}
+}
+
+func TestEmitLogKlog(t *testing.T) {
+	line := "W0101 12:00:00.000000       1 reflector.go:324] watch ended"
+	tests := []struct {
+		name             string
+		klog             bool
+		expectedBody     string
+		expectedSeverity log.Severity
+		expectedCaller   string
+	}{
+		{name: "disabled", expectedBody: line},
+		{name: "enabled", klog: true, expectedBody: "watch ended", expectedSeverity: log.SeverityWarn1, expectedCaller: "reflector.go:324"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(mockExporter)))
+			EmitLogWithConfig(context.Background(), provider.Logger("test"), &LogRecord{Timestamp: time.Now(), Body: line}, &TransformConfig{Klog: tt.klog})
+			provider.ForceFlush(context.Background())
+
+			record := mockExporter.records[0]
+			if record.Body().AsString() != tt.expectedBody {
+				t.Errorf("body = %q, expected %q", record.Body().AsString(), tt.expectedBody)
+			}
+			if record.Severity() != tt.expectedSeverity {
+				t.Errorf("severity = %v, expected %v", record.Severity(), tt.expectedSeverity)
+			}
+			var caller string
+			record.WalkAttributes(func(kv log.KeyValue) bool {
+				if kv.Key == "caller" {
+					caller = kv.Value.AsString()
+				}
+				return true
+			})
+			if caller != tt.expectedCaller {
+				t.Errorf("caller = %q, expected %q", caller, tt.expectedCaller)
+			}
+		})
+	}
+
+	// JSON lines are still parsed as JSON
+	mockExporter := &mockLogRecordExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(mockExporter)))
+	EmitLogWithConfig(context.Background(), provider.Logger("test"), &LogRecord{Timestamp: time.Now(), Body: `{"level":"error","msg":"failed"}`}, &TransformConfig{Klog: true})
+	provider.ForceFlush(context.Background())
+	if record := mockExporter.records[0]; record.Body().AsString() != "failed" || record.Severity() != log.SeverityError1 {
+		t.Errorf("expected an ERROR record with body %q, got %v %q", "failed", record.Severity(), record.Body().AsString())
+	}
+}
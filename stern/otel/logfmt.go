@@ -0,0 +1,95 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import "strings"
+
+// parseLogfmt parses a logfmt line, e.g. `level=info msg="server started"
+// port=8080`, into its fields. Values may be quoted, with escaped quotes. It
+// fails unless the line consists only of key=value pairs, so that plain text
+// is not mistaken for logfmt.
+func parseLogfmt(line string) (map[string]interface{}, bool) {
+	fields := make(map[string]interface{})
+	rest := line
+	for {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			break
+		}
+
+		// key
+		end := strings.IndexAny(rest, "= \t\"")
+		if end <= 0 || rest[end] != '=' {
+			return nil, false
+		}
+		key := rest[:end]
+		rest = rest[end+1:]
+
+		// value, possibly quoted
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			var ok bool
+			value, rest, ok = unquoteLogfmt(rest)
+			if !ok {
+				return nil, false
+			}
+			if rest != "" && rest[0] != ' ' && rest[0] != '\t' {
+				return nil, false
+			}
+		} else {
+			end := strings.IndexAny(rest, " \t")
+			if end == -1 {
+				end = len(rest)
+			}
+			value = rest[:end]
+			if strings.ContainsAny(value, `="`) {
+				return nil, false
+			}
+			rest = rest[end:]
+		}
+		fields[key] = value
+	}
+	return fields, len(fields) > 0
+}
+
+// unquoteLogfmt reads the quoted value starting s and returns it unescaped
+// with the text after its closing quote
+func unquoteLogfmt(s string) (value, rest string, ok bool) {
+	var b strings.Builder
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '"':
+			return b.String(), s[i+1:], true
+		case '\\':
+			if i+1 == len(s) {
+				return "", "", false
+			}
+			i++
+			switch s[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(s[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", "", false
+}
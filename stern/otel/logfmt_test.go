@@ -0,0 +1,118 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestParseLogfmt(t *testing.T) {
+	tests := []struct {
+		name           string
+		line           string
+		expectedFields map[string]interface{}
+		expectedOK     bool
+	}{
+		{
+			name:           "go-kit line",
+			line:           `level=info msg="server started" port=8080`,
+			expectedFields: map[string]interface{}{"level": "info", "msg": "server started", "port": "8080"},
+			expectedOK:     true,
+		},
+		{
+			name:           "escaped quotes",
+			line:           `level=error msg="failed to parse \"config.yaml\": line 3" err="unexpected \\ token"`,
+			expectedFields: map[string]interface{}{"level": "error", "msg": `failed to parse "config.yaml": line 3`, "err": `unexpected \ token`},
+			expectedOK:     true,
+		},
+		{
+			name:           "empty values",
+			line:           `caller=main.go:42 user= msg=""`,
+			expectedFields: map[string]interface{}{"caller": "main.go:42", "user": "", "msg": ""},
+			expectedOK:     true,
+		},
+		{
+			name: "plain text",
+			line: "This is a plain text log message",
+		},
+		{
+			name: "plain text with a pair",
+			line: "Server started with port=8080",
+		},
+		{
+			name: "unterminated quote",
+			line: `level=info msg="server started`,
+		},
+		{
+			name: "equals in a bare value",
+			line: "query=a=b",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields, ok := parseLogfmt(tt.line)
+			if ok != tt.expectedOK {
+				t.Errorf("ok = %v, expected %v", ok, tt.expectedOK)
+			}
+			if !reflect.DeepEqual(fields, tt.expectedFields) {
+				t.Errorf("fields = %v, expected %v", fields, tt.expectedFields)
+			}
+		})
+	}
+}
+
+func TestParseStructuredLogfmt(t *testing.T) {
+	message, severity, attrs, isStructured := parseStructuredLog(`ts=2025-01-01T00:00:00Z level=warn msg="slow request" path=/api duration=1.2s`)
+	if !isStructured {
+		t.Fatal("expected the logfmt line to be structured")
+	}
+	if message != "slow request" {
+		t.Errorf("message = %q, expected %q", message, "slow request")
+	}
+	if severity != "WARN" {
+		t.Errorf("severity = %q, expected %q", severity, "WARN")
+	}
+	expected := map[string]interface{}{"ts": "2025-01-01T00:00:00Z", "path": "/api", "duration": "1.2s"}
+	if !reflect.DeepEqual(attrs, expected) {
+		t.Errorf("attrs = %v, expected %v", attrs, expected)
+	}
+
+	// Without a message, the whole line is the body
+	line := "level=debug component=cache hits=3"
+	if message, _, _, _ := parseStructuredLog(line); message != line {
+		t.Errorf("message = %q, expected %q", message, line)
+	}
+
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	EmitLog(context.Background(), provider.Logger("test"), &LogRecord{Timestamp: time.Now(), Body: `level=error msg="connection refused" peer=10.0.0.1`})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+	}
+	record := mockExporter.records[0]
+	if record.Body().AsString() != "connection refused" || record.Severity() != log.SeverityError1 {
+		t.Errorf("expected an ERROR record with body %q, got %v %q", "connection refused", record.Severity(), record.Body().AsString())
+	}
+}
@@ -0,0 +1,221 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// The types below follow the OTLP/JSON encoding of LogsData
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+
+type otlpLogsData struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+	SchemaURL string          `json:"schemaUrl,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpKeyValue `json:"attributes,omitempty"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope       `json:"scope"`
+	LogRecords []otlpLogRecord `json:"logRecords"`
+}
+
+type otlpScope struct {
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano         string         `json:"timeUnixNano,omitempty"`
+	ObservedTimeUnixNano string         `json:"observedTimeUnixNano,omitempty"`
+	SeverityNumber       int            `json:"severityNumber,omitempty"`
+	SeverityText         string         `json:"severityText,omitempty"`
+	Body                 *otlpAnyValue  `json:"body,omitempty"`
+	Attributes           []otlpKeyValue `json:"attributes,omitempty"`
+	Flags                uint32         `json:"flags,omitempty"`
+	TraceID              string         `json:"traceId,omitempty"`
+	SpanID               string         `json:"spanId,omitempty"`
+}
+
+type otlpKeyValue struct {
+	Key   string       `json:"key"`
+	Value otlpAnyValue `json:"value"`
+}
+
+type otlpAnyValue struct {
+	StringValue *string         `json:"stringValue,omitempty"`
+	BoolValue   *bool           `json:"boolValue,omitempty"`
+	IntValue    *string         `json:"intValue,omitempty"`
+	DoubleValue *float64        `json:"doubleValue,omitempty"`
+	BytesValue  []byte          `json:"bytesValue,omitempty"`
+	ArrayValue  *otlpArrayValue `json:"arrayValue,omitempty"`
+	KvlistValue *otlpKvlist     `json:"kvlistValue,omitempty"`
+}
+
+type otlpArrayValue struct {
+	Values []otlpAnyValue `json:"values"`
+}
+
+type otlpKvlist struct {
+	Values []otlpKeyValue `json:"values"`
+}
+
+// marshalOTLPJSON encodes the record, with its resource and scope, as an
+// OTLP/JSON LogsData document
+func marshalOTLPJSON(record *sdklog.Record) ([]byte, error) {
+	res := record.Resource()
+	scope := record.InstrumentationScope()
+
+	var resourceAttrs []otlpKeyValue
+	for _, kv := range res.Attributes() {
+		resourceAttrs = append(resourceAttrs, otlpKeyValue{Key: string(kv.Key), Value: otlpAttributeValue(kv.Value)})
+	}
+
+	data := otlpLogsData{
+		ResourceLogs: []otlpResourceLogs{{
+			Resource: otlpResource{Attributes: resourceAttrs},
+			ScopeLogs: []otlpScopeLogs{{
+				Scope:      otlpScope{Name: scope.Name, Version: scope.Version},
+				LogRecords: []otlpLogRecord{otlpRecord(record)},
+			}},
+			SchemaURL: res.SchemaURL(),
+		}},
+	}
+	return json.Marshal(data)
+}
+
+// otlpRecord converts the record to its OTLP/JSON representation
+func otlpRecord(record *sdklog.Record) otlpLogRecord {
+	out := otlpLogRecord{
+		TimeUnixNano:         unixNano(record.Timestamp()),
+		ObservedTimeUnixNano: unixNano(record.ObservedTimestamp()),
+		SeverityNumber:       int(record.Severity()),
+		SeverityText:         record.SeverityText(),
+		Flags:                uint32(record.TraceFlags()),
+	}
+	if body := record.Body(); body.Kind() != log.KindEmpty {
+		value := otlpLogValue(body)
+		out.Body = &value
+	}
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		out.Attributes = append(out.Attributes, otlpKeyValue{Key: kv.Key, Value: otlpLogValue(kv.Value)})
+		return true
+	})
+	if traceID := record.TraceID(); traceID.IsValid() {
+		out.TraceID = traceID.String()
+	}
+	if spanID := record.SpanID(); spanID.IsValid() {
+		out.SpanID = spanID.String()
+	}
+	return out
+}
+
+// unixNano formats t as the decimal string used for OTLP/JSON timestamps
+func unixNano(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// otlpLogValue converts a log attribute or body value
+func otlpLogValue(v log.Value) otlpAnyValue {
+	switch v.Kind() {
+	case log.KindString:
+		s := v.AsString()
+		return otlpAnyValue{StringValue: &s}
+	case log.KindBool:
+		b := v.AsBool()
+		return otlpAnyValue{BoolValue: &b}
+	case log.KindInt64:
+		i := strconv.FormatInt(v.AsInt64(), 10)
+		return otlpAnyValue{IntValue: &i}
+	case log.KindFloat64:
+		f := v.AsFloat64()
+		return otlpAnyValue{DoubleValue: &f}
+	case log.KindBytes:
+		return otlpAnyValue{BytesValue: v.AsBytes()}
+	case log.KindSlice:
+		values := []otlpAnyValue{}
+		for _, item := range v.AsSlice() {
+			values = append(values, otlpLogValue(item))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case log.KindMap:
+		values := []otlpKeyValue{}
+		for _, kv := range v.AsMap() {
+			values = append(values, otlpKeyValue{Key: kv.Key, Value: otlpLogValue(kv.Value)})
+		}
+		return otlpAnyValue{KvlistValue: &otlpKvlist{Values: values}}
+	default:
+		return otlpAnyValue{}
+	}
+}
+
+// otlpAttributeValue converts a resource attribute value
+func otlpAttributeValue(v attribute.Value) otlpAnyValue {
+	switch v.Type() {
+	case attribute.BOOL:
+		b := v.AsBool()
+		return otlpAnyValue{BoolValue: &b}
+	case attribute.INT64:
+		i := strconv.FormatInt(v.AsInt64(), 10)
+		return otlpAnyValue{IntValue: &i}
+	case attribute.FLOAT64:
+		f := v.AsFloat64()
+		return otlpAnyValue{DoubleValue: &f}
+	case attribute.BOOLSLICE:
+		var values []otlpAnyValue
+		for _, b := range v.AsBoolSlice() {
+			values = append(values, otlpAttributeValue(attribute.BoolValue(b)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.INT64SLICE:
+		var values []otlpAnyValue
+		for _, i := range v.AsInt64Slice() {
+			values = append(values, otlpAttributeValue(attribute.Int64Value(i)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.FLOAT64SLICE:
+		var values []otlpAnyValue
+		for _, f := range v.AsFloat64Slice() {
+			values = append(values, otlpAttributeValue(attribute.Float64Value(f)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	case attribute.STRINGSLICE:
+		var values []otlpAnyValue
+		for _, s := range v.AsStringSlice() {
+			values = append(values, otlpAttributeValue(attribute.StringValue(s)))
+		}
+		return otlpAnyValue{ArrayValue: &otlpArrayValue{Values: values}}
+	default:
+		s := v.Emit()
+		return otlpAnyValue{StringValue: &s}
+	}
+}
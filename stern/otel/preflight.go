@@ -0,0 +1,65 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+)
+
+// preflight checks that the gRPC or HTTP endpoint accepts connections, and
+// completes the TLS handshake when TLS is enabled, within PreflightTimeout.
+// The exporters connect lazily, so that an unreachable endpoint would only
+// show up as failed exports. The endpoint is dialed directly, ignoring the
+// proxy of the environment the HTTP exporter honors, so the check is opt-in.
+func preflight(ctx context.Context, config *ExporterConfig) error {
+	if config.PreflightTimeout <= 0 || (config.Protocol != "grpc" && config.Protocol != "http") {
+		return nil
+	}
+
+	tlsConfig, err := config.tlsConfig()
+	if err != nil {
+		return err
+	}
+	if tlsConfig == nil && !config.Insecure {
+		tlsConfig = &tls.Config{}
+	}
+
+	address := config.Endpoint
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		port := "443"
+		if tlsConfig == nil {
+			port = "80"
+		}
+		address = net.JoinHostPort(address, port)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, config.PreflightTimeout)
+	defer cancel()
+
+	var dialer interface {
+		DialContext(ctx context.Context, network, address string) (net.Conn, error)
+	} = &net.Dialer{}
+	if tlsConfig != nil {
+		dialer = &tls.Dialer{Config: tlsConfig}
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return fmt.Errorf("OTel endpoint %s is unreachable, unset --otel-preflight-timeout if it comes up later: %w", config.Endpoint, err)
+	}
+	return conn.Close()
+}
@@ -0,0 +1,92 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPreflight(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	reachable := listener.Addr().String()
+	defer listener.Close()
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	unreachable := closed.Addr().String()
+	closed.Close()
+
+	ctx := context.Background()
+	tests := []struct {
+		config  ExporterConfig
+		wantErr bool
+	}{
+		{ExporterConfig{Endpoint: reachable, Protocol: "grpc", Insecure: true, PreflightTimeout: time.Second}, false},
+		{ExporterConfig{Endpoint: unreachable, Protocol: "grpc", Insecure: true, PreflightTimeout: time.Second}, true},
+		{ExporterConfig{Endpoint: unreachable, Protocol: "http", Insecure: true, PreflightTimeout: time.Second}, true},
+		// Skipped
+		{ExporterConfig{Endpoint: unreachable, Protocol: "grpc", Insecure: true}, false},
+		{ExporterConfig{Protocol: "stdout", PreflightTimeout: time.Second}, false},
+	}
+	for _, tt := range tests {
+		err := preflight(ctx, &tt.config)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s %s: expected error %v, got %v", tt.config.Protocol, tt.config.Endpoint, tt.wantErr, err)
+		}
+		if err != nil && !strings.Contains(err.Error(), "is unreachable") {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}
+
+	if _, err := NewExporter(ctx, &ExporterConfig{Endpoint: unreachable, Protocol: "grpc", Insecure: true, BatchSize: 10, PreflightTimeout: time.Second}, nil); err == nil {
+		t.Error("expected NewExporter to fail on an unreachable endpoint")
+	}
+}
+
+func TestPreflightTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}), 0o600); err != nil {
+		t.Fatalf("failed to write CA certificate: %v", err)
+	}
+
+	endpoint := strings.TrimPrefix(server.URL, "https://")
+	config := &ExporterConfig{Endpoint: endpoint, Protocol: "http", CACertFile: caFile, PreflightTimeout: time.Second}
+	if err := preflight(context.Background(), config); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// The certificate of the server is not trusted by the system roots
+	config = &ExporterConfig{Endpoint: endpoint, Protocol: "http", PreflightTimeout: time.Second}
+	if err := preflight(context.Background(), config); err == nil {
+		t.Error("expected the TLS handshake to fail")
+	}
+}
@@ -0,0 +1,172 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"encoding/json"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// RedactedValue replaces the redacted values and text
+const RedactedValue = "***"
+
+// SensitiveKeys are common fields of structured logs holding credentials
+var SensitiveKeys = []string{"password", "passwd", "secret", "token", "access_token", "refresh_token", "authorization", "api_key", "apikey", "cookie"}
+
+// redactor hides the sensitive fields and text of a record
+type redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+	drop     bool
+}
+
+// redactor returns the redactor of the configuration, or nil when nothing
+// is redacted
+func (c TransformConfig) redactor() *redactor {
+	if len(c.RedactKeys) == 0 && len(c.RedactPatterns) == 0 {
+		return nil
+	}
+	return &redactor{keys: c.RedactKeys, patterns: c.RedactPatterns, drop: c.RedactDrop}
+}
+
+// sensitive reports whether key names a redacted field, ignoring case
+func (r *redactor) sensitive(key string) bool {
+	return slices.ContainsFunc(r.keys, func(k string) bool {
+		return strings.EqualFold(k, key)
+	})
+}
+
+// redactText replaces the text matching the patterns
+func (r *redactor) redactText(s string) string {
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllLiteralString(s, RedactedValue)
+	}
+	return s
+}
+
+// redactFields redacts the sensitive fields of the structured attributes,
+// including those of nested objects, and the matching text of their strings
+func (r *redactor) redactFields(fields map[string]interface{}) {
+	for key, value := range fields {
+		if r.sensitive(key) {
+			if r.drop {
+				delete(fields, key)
+			} else {
+				fields[key] = RedactedValue
+			}
+			continue
+		}
+		fields[key] = r.redactValue(value)
+	}
+}
+
+// redactValue redacts a field value that is not sensitive by its key
+func (r *redactor) redactValue(value interface{}) interface{} {
+	switch val := value.(type) {
+	case string:
+		return r.redactText(val)
+	case map[string]interface{}:
+		r.redactFields(val)
+	case []interface{}:
+		for i, item := range val {
+			val[i] = r.redactValue(item)
+		}
+	}
+	return value
+}
+
+// redactLine redacts a whole log line. The sensitive fields of a JSON object
+// are redacted in its re-encoded form, since they cannot be told apart in
+// the text, and those of key=value pairs, e.g. logfmt, in place.
+func (r *redactor) redactLine(line string) string {
+	if len(r.keys) > 0 {
+		if fields, ok := unmarshalJSONObject(strings.TrimPrefix(strings.TrimSpace(line), utf8BOM)); ok {
+			r.redactFields(fields)
+			if data, err := json.Marshal(fields); err == nil {
+				return string(data)
+			}
+		}
+		line = r.redactPairs(line)
+	}
+	return r.redactText(line)
+}
+
+// redactPairs redacts the values of the sensitive key=value pairs of a line,
+// quoted or not, dropping the whole pairs when enabled
+func (r *redactor) redactPairs(line string) string {
+	var b strings.Builder
+	copied := 0
+	for i := 0; i < len(line); {
+		eq := strings.IndexByte(line[i:], '=')
+		if eq == -1 {
+			break
+		}
+		eq += i
+
+		// The key runs back from the = over the characters of identifiers
+		start := eq
+		for start > i && isKeyByte(line[start-1]) {
+			start--
+		}
+		end := pairValueEnd(line, eq+1)
+		if start < eq && r.sensitive(line[start:eq]) {
+			if r.drop {
+				// Drop the blanks before the pair, or after it when it starts the line
+				b.WriteString(strings.TrimRight(line[copied:start], " \t"))
+				if b.Len() == 0 {
+					end = len(line) - len(strings.TrimLeft(line[end:], " \t"))
+				}
+			} else {
+				b.WriteString(line[copied : eq+1])
+				b.WriteString(RedactedValue)
+			}
+			copied = end
+		}
+		i = end
+	}
+	if copied == 0 {
+		return line
+	}
+	b.WriteString(line[copied:])
+	return b.String()
+}
+
+// isKeyByte reports whether c may be part of the key of a key=value pair
+func isKeyByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '-' || c == '.'
+}
+
+// pairValueEnd returns the end of the value of a key=value pair starting at
+// start: after the closing quote of a quoted value, at the next blank
+// otherwise
+func pairValueEnd(line string, start int) int {
+	if start < len(line) && line[start] == '"' {
+		for i := start + 1; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return len(line)
+	}
+	if end := strings.IndexAny(line[start:], " \t"); end != -1 {
+		return start + end
+	}
+	return len(line)
+}
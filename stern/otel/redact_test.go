@@ -0,0 +1,160 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestEmitLogRedaction(t *testing.T) {
+	email := regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.]+`)
+	tests := []struct {
+		name          string
+		body          string
+		config        *TransformConfig
+		expectedBody  string
+		expectedAttrs map[string]string
+	}{
+		{
+			name:         "masked keys ignoring case",
+			body:         `{"msg":"login","user":"alice","Password":"hunter2","request":{"Authorization":"Bearer abc"}}`,
+			config:       &TransformConfig{RedactKeys: SensitiveKeys},
+			expectedBody: "login",
+			expectedAttrs: map[string]string{
+				"user":     "alice",
+				"Password": "***",
+				"request":  `{"Authorization":"***"}`,
+			},
+		},
+		{
+			name:         "dropped keys",
+			body:         `{"msg":"login","user":"alice","password":"hunter2"}`,
+			config:       &TransformConfig{RedactKeys: []string{"password"}, RedactDrop: true},
+			expectedBody: "login",
+			expectedAttrs: map[string]string{
+				"user": "alice",
+			},
+		},
+		{
+			name:         "patterns over the body and values",
+			body:         `{"msg":"mail sent to bob@example.com","to":"bob@example.com","tags":["cc carol@example.org"]}`,
+			config:       &TransformConfig{RedactPatterns: []*regexp.Regexp{email}},
+			expectedBody: "mail sent to ***",
+			expectedAttrs: map[string]string{
+				"to":   "***",
+				"tags": `["cc ***"]`,
+			},
+		},
+		{
+			name:          "plain text",
+			body:          "password reset requested by bob@example.com",
+			config:        &TransformConfig{RedactKeys: SensitiveKeys, RedactPatterns: []*regexp.Regexp{email}},
+			expectedBody:  "password reset requested by ***",
+			expectedAttrs: map[string]string{},
+		},
+		{
+			name:         "JSON body without a message",
+			body:         `{"user":"alice","token":"s3cr3t"}`,
+			config:       &TransformConfig{RedactKeys: SensitiveKeys},
+			expectedBody: `{"token":"***","user":"alice"}`,
+			expectedAttrs: map[string]string{
+				"user":  "alice",
+				"token": "***",
+			},
+		},
+		{
+			name:         "logfmt body without a message",
+			body:         `user=bob password=hunter2 token="s3cr3t value"`,
+			config:       &TransformConfig{RedactKeys: SensitiveKeys},
+			expectedBody: `user=bob password=*** token=***`,
+			expectedAttrs: map[string]string{
+				"user":     "bob",
+				"password": "***",
+				"token":    "***",
+			},
+		},
+		{
+			name:          "dropped logfmt pairs",
+			body:          `password=hunter2 user=bob token=s3cr3t`,
+			config:        &TransformConfig{RedactKeys: SensitiveKeys, RedactDrop: true},
+			expectedBody:  `user=bob`,
+			expectedAttrs: map[string]string{"user": "bob"},
+		},
+		{
+			name:          "pairs in plain text",
+			body:          `login failed for bob (password=hunter2, attempts=3)`,
+			config:        &TransformConfig{RedactKeys: SensitiveKeys},
+			expectedBody:  `login failed for bob (password=*** attempts=3)`,
+			expectedAttrs: map[string]string{},
+		},
+		{
+			name:         "original line",
+			body:         `{"msg":"login","password":"hunter2"}`,
+			config:       &TransformConfig{RedactKeys: SensitiveKeys, KeepRaw: true},
+			expectedBody: "login",
+			expectedAttrs: map[string]string{
+				"password":            "***",
+				"log.record.original": `{"msg":"login","password":"***"}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			processor := sdklog.NewSimpleProcessor(mockExporter)
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+
+			EmitLogWithConfig(context.Background(), provider.Logger("test"), &LogRecord{Timestamp: time.Now(), Body: tt.body}, tt.config)
+			provider.ForceFlush(context.Background())
+
+			if len(mockExporter.records) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+			}
+			record := mockExporter.records[0]
+			if got := record.Body().AsString(); got != tt.expectedBody {
+				t.Errorf("body = %q, expected %q", got, tt.expectedBody)
+			}
+
+			attrs := make(map[string]string)
+			record.WalkAttributes(func(kv log.KeyValue) bool {
+				if kv.Key != "service.name" {
+					attrs[kv.Key] = kv.Value.AsString()
+				}
+				return true
+			})
+			for key, want := range tt.expectedAttrs {
+				if got, ok := attrs[key]; !ok || got != want {
+					t.Errorf("attribute %q = %q, expected %q", key, got, want)
+				}
+			}
+			for key, value := range attrs {
+				if _, ok := tt.expectedAttrs[key]; !ok {
+					t.Errorf("unexpected attribute %q=%q", key, value)
+				}
+				if strings.Contains(value, "hunter2") || strings.Contains(value, "s3cr3t") || strings.Contains(value, "@example") {
+					t.Errorf("attribute %q leaks %q", key, value)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+// replayExporter keeps the records the wrapped exporter fails to export in
+// memory, up to max records, and replays them, oldest first, once it
+// recovers, e.g. after a collector rollout. Replays are attempted before
+// each new batch and on flush and shutdown, in batches of batchSize. The
+// oldest records are dropped when the buffer is full.
+type replayExporter struct {
+	sdklog.Exporter
+	max       int
+	batchSize int
+
+	mu      sync.Mutex
+	pending []sdklog.Record
+
+	// replayed and overflowed are the number of records replayed, and
+	// dropped from the full buffer
+	replayed   atomic.Int64
+	overflowed atomic.Int64
+}
+
+// Export replays the buffered records, then exports the records. The
+// records are buffered when either fails, and the failure is still returned
+// so that they are not counted as exported until they are replayed. The
+// records beyond the bound of the buffer are dropped and counted instead.
+func (e *replayExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Keep the order of the records: nothing new goes out before the
+	// buffered ones
+	err := e.replayLocked(ctx)
+	if err == nil {
+		err = e.Exporter.Export(ctx, records)
+	}
+	if err != nil {
+		e.bufferLocked(records)
+		return fmt.Errorf("buffered %d records for replay: %w", len(records), err)
+	}
+	return nil
+}
+
+// replayLocked exports the buffered records until one of the batches fails
+func (e *replayExporter) replayLocked(ctx context.Context) error {
+	for len(e.pending) > 0 {
+		n := min(len(e.pending), max(e.batchSize, 1))
+		if err := e.Exporter.Export(ctx, e.pending[:n]); err != nil {
+			return err
+		}
+		e.replayed.Add(int64(n))
+		e.pending = e.pending[n:]
+	}
+	e.pending = nil
+	return nil
+}
+
+// bufferLocked appends a copy of the records to the buffer, which the batch
+// processor reuses, dropping the oldest ones beyond max
+func (e *replayExporter) bufferLocked(records []sdklog.Record) {
+	for i := range records {
+		e.pending = append(e.pending, records[i].Clone())
+	}
+	if overflow := len(e.pending) - e.max; overflow > 0 {
+		e.overflowed.Add(int64(overflow))
+		e.pending = e.pending[overflow:]
+	}
+}
+
+// buffered returns the number of records waiting to be replayed
+func (e *replayExporter) buffered() int64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return int64(len(e.pending))
+}
+
+// snapshot returns the numbers of records replayed, waiting to be replayed
+// and dropped from the full buffer, consistent with each other
+func (e *replayExporter) snapshot() (replayed, buffered, overflowed int64) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.replayed.Load(), int64(len(e.pending)), e.overflowed.Load()
+}
+
+// ForceFlush replays the buffered records and flushes the wrapped exporter
+func (e *replayExporter) ForceFlush(ctx context.Context) error {
+	e.mu.Lock()
+	err := e.replayLocked(ctx)
+	e.mu.Unlock()
+	return errors.Join(err, e.Exporter.ForceFlush(ctx))
+}
+
+// Shutdown replays the buffered records a last time and shuts down the
+// wrapped exporter. The records still buffered are lost, and reported by
+// buffered.
+func (e *replayExporter) Shutdown(ctx context.Context) error {
+	e.mu.Lock()
+	err := e.replayLocked(ctx)
+	e.mu.Unlock()
+	return errors.Join(err, e.Exporter.Shutdown(ctx))
+}
@@ -0,0 +1,185 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestReplayExporter(t *testing.T) {
+	newBatch := func(bodies ...string) []sdklog.Record {
+		records := make([]sdklog.Record, len(bodies))
+		for i, body := range bodies {
+			records[i].SetBody(log.StringValue(body))
+		}
+		return records
+	}
+	bodiesOf := func(records []sdklog.Record) []string {
+		var bodies []string
+		for _, record := range records {
+			bodies = append(bodies, record.Body().AsString())
+		}
+		return bodies
+	}
+
+	tests := []struct {
+		name               string
+		max                int
+		expectedExported   []string
+		expectedOverflowed int64
+	}{
+		{
+			name:             "outage within the buffer",
+			max:              10,
+			expectedExported: []string{"before", "during 1", "during 2", "during 3", "after"},
+		},
+		{
+			name:               "outage beyond the buffer",
+			max:                2,
+			expectedExported:   []string{"before", "during 2", "during 3", "after"},
+			expectedOverflowed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary := &outageExporter{}
+			exporter := &replayExporter{Exporter: primary, max: tt.max, batchSize: 1}
+
+			ctx := context.Background()
+			if err := exporter.Export(ctx, newBatch("before")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			primary.down = true
+			// the batch processor reuses the slice of the batches
+			batch := newBatch("during 1", "during 2")
+			if err := exporter.Export(ctx, batch); err == nil {
+				t.Fatal("expected the failure to be reported once the batch is buffered")
+			}
+			batch[0].SetBody(log.StringValue("overwritten"))
+			if err := exporter.Export(ctx, newBatch("during 3")); err == nil {
+				t.Fatal("expected the failure to be reported once the batch is buffered")
+			}
+			if buffered := exporter.buffered(); buffered != int64(min(3, tt.max)) {
+				t.Errorf("expected %d buffered records, got %d", min(3, tt.max), buffered)
+			}
+			primary.down = false
+			if err := exporter.Export(ctx, newBatch("after")); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := bodiesOf(primary.records); !reflect.DeepEqual(tt.expectedExported, got) {
+				t.Errorf("expected %v, got %v", tt.expectedExported, got)
+			}
+			if buffered := exporter.buffered(); buffered != 0 {
+				t.Errorf("expected an empty buffer, got %d records", buffered)
+			}
+			if replayed := exporter.replayed.Load(); replayed != int64(min(3, tt.max)) {
+				t.Errorf("expected %d replayed records, got %d", min(3, tt.max), replayed)
+			}
+			if overflowed := exporter.overflowed.Load(); overflowed != tt.expectedOverflowed {
+				t.Errorf("expected %d overflowed records, got %d", tt.expectedOverflowed, overflowed)
+			}
+		})
+	}
+}
+
+func TestReplayExporterShutdown(t *testing.T) {
+	primary := &outageExporter{down: true}
+	exporter := &replayExporter{Exporter: primary, max: 10, batchSize: 10}
+
+	ctx := context.Background()
+	records := make([]sdklog.Record, 2)
+	if err := exporter.Export(ctx, records); err == nil {
+		t.Fatal("expected the failure to be reported once the batch is buffered")
+	}
+
+	// the collector is still down on flush, then recovers before exit
+	if err := exporter.ForceFlush(ctx); err == nil {
+		t.Error("expected the replay to fail while the collector is down")
+	}
+	primary.down = false
+	if err := exporter.Shutdown(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(primary.records) != 2 {
+		t.Errorf("expected the buffered records to be replayed on shutdown, got %d", len(primary.records))
+	}
+}
+
+func TestNewExporterReplayBuffer(t *testing.T) {
+	for _, config := range []*ExporterConfig{
+		{Protocol: "stdout", BatchSize: 10, ReplayBuffer: -1},
+		{Protocol: "stdout", BatchSize: 10, ReplayBuffer: 100, FallbackFile: "fallback.ndjson"},
+	} {
+		if _, err := NewExporter(context.Background(), config, resource.Empty()); err == nil {
+			t.Errorf("expected an error for %+v", config)
+		}
+	}
+
+	exporter, err := NewExporter(context.Background(), &ExporterConfig{Protocol: "stdout", BatchSize: 10, ReplayBuffer: 100}, resource.Empty())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer exporter.Shutdown(context.Background())
+	if exporter.replay == nil || exporter.replay.max != 100 {
+		t.Errorf("expected a replay buffer of 100 records, got %+v", exporter.replay)
+	}
+}
+
+func TestExporterStatsReplay(t *testing.T) {
+	primary := &outageExporter{down: true}
+	replay := &replayExporter{Exporter: primary, max: 10, batchSize: 10}
+	config := &ExporterConfig{
+		BatchSize:       10,
+		ExportTimeout:   time.Minute,
+		ShutdownTimeout: time.Second,
+	}
+	exporter := newExporter(config, resource.Empty(), replay)
+	exporter.replay = replay
+	defer exporter.Shutdown(context.Background())
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		exporter.Emit(ctx, &LogRecord{Timestamp: time.Now(), Body: "during"})
+	}
+	_ = exporter.ForceFlush(ctx)
+
+	// the buffered records are not exported yet
+	if stats := exporter.Stats(); stats.Exported != 0 || stats.Buffered != 3 {
+		t.Errorf("expected 3 buffered and no exported records, got %+v", stats)
+	}
+	if pending := exporter.Pending(); pending != 3 {
+		t.Errorf("expected the buffered records to be pending, got %d", pending)
+	}
+
+	primary.down = false
+	if err := exporter.ForceFlush(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stats := exporter.Stats(); stats.Exported != 3 || stats.Replayed != 3 || stats.Buffered != 0 {
+		t.Errorf("expected the 3 replayed records to be exported, got %+v", stats)
+	}
+	if pending := exporter.Pending(); pending != 0 {
+		t.Errorf("expected no pending records, got %d", pending)
+	}
+}
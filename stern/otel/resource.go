@@ -16,32 +16,205 @@ package otel
 
 import (
 	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"sync"
 
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// DefaultClusterNameEnv is the environment variable holding the cluster name
+// when the kubeconfig has no current context, e.g. when running in-cluster
+const DefaultClusterNameEnv = "K8S_CLUSTER_NAME"
+
+// ResourceConfig configures the creation of the OTel resource
+type ResourceConfig struct {
+	// BestEffortDetectors skips the host and runtime detectors that fail
+	// instead of failing the resource creation
+	BestEffortDetectors bool
+	// OnDetectorError is called with the error of each skipped detector
+	OnDetectorError func(err error)
+	// ClusterNameEnv is the environment variable read for the cluster name
+	// when the kubeconfig has no current context. Defaults to DefaultClusterNameEnv.
+	ClusterNameEnv string
+	// Client, when set, is used as a last resort to identify the cluster by
+	// the UID of the kube-system namespace
+	Client kubernetes.Interface
+	// ClusterUID sets k8s.cluster.uid to the UID of the kube-system
+	// namespace, read with Client. It is left out when the read is denied.
+	ClusterUID bool
+	// DisableHostDetector leaves out the host.name of the stern process, so
+	// that the only host.name is the node of each record
+	DisableHostDetector bool
+	// ServiceVersion is the service.version of stern, e.g. injected at build
+	// time. Empty or "dev" falls back to the version of the main module in
+	// the build info, e.g. with go install, then to "dev".
+	ServiceVersion string
+	// Attributes are merged last into the resource, so that they take
+	// precedence over the attributes set or detected by stern
+	Attributes []attribute.KeyValue
+}
+
+// resourceDetectors are the detectors adding runtime information
+var resourceDetectors = []resource.Option{
+	resource.WithProcessRuntimeDescription(),
+}
+
+// hostDetectors are the detectors adding the host of the stern process
+var hostDetectors = []resource.Option{
+	resource.WithHost(),
+}
+
+// readBuildInfo reads the build info of the binary, replaced by tests
+var readBuildInfo = debug.ReadBuildInfo
+
+// serviceVersion returns the version of stern, falling back to the build
+// info when none was injected
+func serviceVersion(injected string) string {
+	if injected != "" && injected != "dev" {
+		return injected
+	}
+	if info, ok := readBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// ParseResourceAttributes parses attributes in the format of
+// OTEL_RESOURCE_ATTRIBUTES, e.g. "env=staging,team=payments", where the
+// values may be percent-encoded
+func ParseResourceAttributes(s string) ([]attribute.KeyValue, error) {
+	var attrs []attribute.KeyValue
+	for _, pair := range strings.Split(s, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid resource attribute %q: expected <key>=<value>", pair)
+		}
+		value, err := url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource attribute %q: %w", pair, err)
+		}
+		attrs = append(attrs, attribute.String(key, value))
+	}
+	return attrs, nil
+}
+
 // NewResource creates an OTel resource with K8s cluster information
 func NewResource(ctx context.Context, clientConfig clientcmd.ClientConfig) (*resource.Resource, error) {
+	return NewResourceWithConfig(ctx, clientConfig, nil)
+}
+
+// ResourceClusterName returns the k8s.cluster.name attribute of the
+// resource, empty when the cluster name could not be resolved
+func ResourceClusterName(res *resource.Resource) string {
+	if res == nil {
+		return ""
+	}
+	value, _ := res.Set().Value(semconv.K8SClusterNameKey)
+	return value.AsString()
+}
+
+// NewResourceWithConfig creates an OTel resource with K8s cluster information
+// using the provided configuration
+func NewResourceWithConfig(ctx context.Context, clientConfig clientcmd.ClientConfig, config *ResourceConfig) (*resource.Resource, error) {
+	if config == nil {
+		config = &ResourceConfig{}
+	}
+
 	attrs := []attribute.KeyValue{
 		semconv.ServiceNameKey.String("stern"),
-		semconv.ServiceVersionKey.String("v1.33.0"), // TODO: Make this dynamic
+		semconv.ServiceVersionKey.String(serviceVersion(config.ServiceVersion)),
+	}
+
+	clusterUID := sync.OnceValue(func() string {
+		return kubeSystemUID(ctx, config.Client)
+	})
+	if clusterName := clusterName(ctx, clientConfig, config, clusterUID); clusterName != "" {
+		attrs = append(attrs, semconv.K8SClusterName(clusterName))
+	}
+	if config.ClusterUID {
+		if uid := clusterUID(); uid != "" {
+			attrs = append(attrs, semconv.K8SClusterUID(uid))
+		}
 	}
 
+	detectors := slices.Clip(resourceDetectors)
+	if !config.DisableHostDetector {
+		detectors = append(detectors, hostDetectors...)
+	}
+
+	if !config.BestEffortDetectors {
+		options := append([]resource.Option{resource.WithAttributes(attrs...)}, detectors...)
+		return resource.New(ctx, append(options, resource.WithAttributes(config.Attributes...))...)
+	}
+
+	// Run each detector on its own so that a failing one does not take the
+	// core attributes down with it
+	res, err := resource.New(ctx, resource.WithAttributes(attrs...))
+	if err != nil {
+		return nil, err
+	}
+	for _, detector := range detectors {
+		detected, err := resource.New(ctx, detector)
+		if err == nil {
+			detected, err = resource.Merge(res, detected)
+		}
+		if err != nil {
+			if config.OnDetectorError != nil {
+				config.OnDetectorError(err)
+			}
+			continue
+		}
+		res = detected
+	}
+	return resource.Merge(res, resource.NewSchemaless(config.Attributes...))
+}
+
+// clusterName identifies the cluster by the kubeconfig context, falling
+// back to the environment and then to the UID of the kube-system namespace
+func clusterName(ctx context.Context, clientConfig clientcmd.ClientConfig, config *ResourceConfig, clusterUID func() string) string {
 	// Try to get cluster name from kubeconfig context
 	if clientConfig != nil {
 		rawConfig, err := clientConfig.RawConfig()
 		if err == nil && rawConfig.CurrentContext != "" {
 			// Use context name as cluster identifier
-			attrs = append(attrs, semconv.K8SClusterName(rawConfig.CurrentContext))
+			return rawConfig.CurrentContext
 		}
 	}
 
-	return resource.New(ctx,
-		resource.WithAttributes(attrs...),
-		resource.WithProcessRuntimeDescription(),
-		resource.WithHost(),
-	)
+	env := config.ClusterNameEnv
+	if env == "" {
+		env = DefaultClusterNameEnv
+	}
+	if name := os.Getenv(env); name != "" {
+		return name
+	}
+
+	return clusterUID()
+}
+
+// kubeSystemUID returns the UID of the kube-system namespace, a stable
+// identifier of the cluster, or an empty string when it cannot be read
+func kubeSystemUID(ctx context.Context, client kubernetes.Interface) string {
+	if client == nil {
+		return ""
+	}
+	ns, err := client.CoreV1().Namespaces().Get(ctx, "kube-system", metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+	return string(ns.UID)
 }
@@ -16,9 +16,20 @@ package otel
 
 import (
 	"context"
+	"errors"
+	"reflect"
+	"runtime/debug"
 	"testing"
 
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
 	semconv "go.opentelemetry.io/otel/semconv/v1.27.0"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestNewResource(t *testing.T) {
@@ -49,3 +60,242 @@ func TestNewResource(t *testing.T) {
 		t.Error("service.name attribute not found or incorrect")
 	}
 }
+
+func TestServiceVersion(t *testing.T) {
+	orig := readBuildInfo
+	defer func() { readBuildInfo = orig }()
+
+	tests := []struct {
+		injected  string
+		buildInfo *debug.BuildInfo
+		expected  string
+	}{
+		{"v1.33.0", &debug.BuildInfo{Main: debug.Module{Version: "v1.32.0"}}, "v1.33.0"},
+		{"dev", &debug.BuildInfo{Main: debug.Module{Version: "v1.32.0"}}, "v1.32.0"},
+		{"", &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}, "dev"},
+		{"", nil, "dev"},
+	}
+
+	for _, tt := range tests {
+		readBuildInfo = func() (*debug.BuildInfo, bool) {
+			return tt.buildInfo, tt.buildInfo != nil
+		}
+		if got := serviceVersion(tt.injected); got != tt.expected {
+			t.Errorf("serviceVersion(%q) = %q, expected %q", tt.injected, got, tt.expected)
+		}
+	}
+
+	readBuildInfo = orig
+	res, err := NewResourceWithConfig(context.Background(), nil, &ResourceConfig{ServiceVersion: "v9.9.9"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if version, _ := res.Set().Value(semconv.ServiceVersionKey); version.AsString() != "v9.9.9" {
+		t.Errorf("expected service.version v9.9.9, got %q", version.AsString())
+	}
+}
+
+// failingDetector simulates a detector failing in a restricted environment
+type failingDetector struct{}
+
+func (failingDetector) Detect(ctx context.Context) (*resource.Resource, error) {
+	return nil, errors.New("permission denied")
+}
+
+func TestNewResourceBestEffortDetectors(t *testing.T) {
+	detectors, hosts := resourceDetectors, hostDetectors
+	defer func() { resourceDetectors, hostDetectors = detectors, hosts }()
+	hostDetectors = nil
+	resourceDetectors = []resource.Option{
+		resource.WithDetectors(failingDetector{}),
+		resource.WithAttributes(attribute.String("host.name", "node-1")),
+	}
+
+	ctx := context.Background()
+
+	if _, err := NewResource(ctx, nil); err == nil {
+		t.Error("expected detector error without best effort detectors")
+	}
+
+	var skipped []error
+	res, err := NewResourceWithConfig(ctx, nil, &ResourceConfig{
+		BestEffortDetectors: true,
+		OnDetectorError: func(err error) {
+			skipped = append(skipped, err)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewResourceWithConfig failed: %v", err)
+	}
+	if len(skipped) != 1 {
+		t.Errorf("expected 1 skipped detector, got %d", len(skipped))
+	}
+
+	if v, ok := res.Set().Value(semconv.ServiceNameKey); !ok || v.AsString() != "stern" {
+		t.Error("service.name attribute not found or incorrect")
+	}
+	if v, ok := res.Set().Value("host.name"); !ok || v.AsString() != "node-1" {
+		t.Error("expected attributes of the succeeding detector to be kept")
+	}
+}
+
+func TestNewResourceClusterNameFallback(t *testing.T) {
+	ctx := context.Background()
+
+	clusterNameOf := func(res *resource.Resource) string {
+		v, _ := res.Set().Value(semconv.K8SClusterNameKey)
+		return v.AsString()
+	}
+
+	t.Setenv(DefaultClusterNameEnv, "in-cluster")
+	res, err := NewResource(ctx, nil)
+	if err != nil {
+		t.Fatalf("NewResource failed: %v", err)
+	}
+	if got := clusterNameOf(res); got != "in-cluster" {
+		t.Errorf("expected cluster name from %s, got %q", DefaultClusterNameEnv, got)
+	}
+
+	t.Setenv("MY_CLUSTER", "custom")
+	res, err = NewResourceWithConfig(ctx, nil, &ResourceConfig{ClusterNameEnv: "MY_CLUSTER"})
+	if err != nil {
+		t.Fatalf("NewResourceWithConfig failed: %v", err)
+	}
+	if got := clusterNameOf(res); got != "custom" {
+		t.Errorf("expected cluster name from MY_CLUSTER, got %q", got)
+	}
+
+	t.Setenv(DefaultClusterNameEnv, "")
+	client := fake.NewSimpleClientset(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "6f1c0a44-uid"},
+	})
+	res, err = NewResourceWithConfig(ctx, nil, &ResourceConfig{Client: client})
+	if err != nil {
+		t.Fatalf("NewResourceWithConfig failed: %v", err)
+	}
+	if got := clusterNameOf(res); got != "6f1c0a44-uid" {
+		t.Errorf("expected cluster name from the kube-system UID, got %q", got)
+	}
+}
+
+func TestNewResourceDisableHostDetector(t *testing.T) {
+	hosts := hostDetectors
+	defer func() { hostDetectors = hosts }()
+	hostDetectors = []resource.Option{resource.WithAttributes(semconv.HostName("forwarder"))}
+
+	ctx := context.Background()
+
+	res, err := NewResource(ctx, nil)
+	if err != nil {
+		t.Fatalf("NewResource failed: %v", err)
+	}
+	if v, ok := res.Set().Value(semconv.HostNameKey); !ok || v.AsString() != "forwarder" {
+		t.Error("expected host.name of the stern process by default")
+	}
+
+	res, err = NewResourceWithConfig(ctx, nil, &ResourceConfig{DisableHostDetector: true})
+	if err != nil {
+		t.Fatalf("NewResourceWithConfig failed: %v", err)
+	}
+	if _, ok := res.Set().Value(semconv.HostNameKey); ok {
+		t.Error("expected no host.name when the host detector is disabled")
+	}
+	if v, ok := res.Set().Value(semconv.ServiceNameKey); !ok || v.AsString() != "stern" {
+		t.Error("service.name attribute not found or incorrect")
+	}
+}
+
+func TestParseResourceAttributes(t *testing.T) {
+	attrs, err := ParseResourceAttributes("deployment.environment=staging, team=payments,,note=a%2Cb%3Dc")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []attribute.KeyValue{
+		attribute.String("deployment.environment", "staging"),
+		attribute.String("team", "payments"),
+		attribute.String("note", "a,b=c"),
+	}
+	if !reflect.DeepEqual(attrs, expected) {
+		t.Errorf("expected %v, got %v", expected, attrs)
+	}
+
+	for _, s := range []string{"team", "=payments", "note=%zz"} {
+		if _, err := ParseResourceAttributes(s); err == nil {
+			t.Errorf("expected an error for %q", s)
+		}
+	}
+}
+
+func TestNewResourceAttributes(t *testing.T) {
+	hosts := hostDetectors
+	defer func() { hostDetectors = hosts }()
+	hostDetectors = []resource.Option{resource.WithAttributes(semconv.HostName("forwarder"))}
+
+	attrs := []attribute.KeyValue{
+		attribute.String("team", "payments"),
+		semconv.ServiceName("payments-logs"),
+		semconv.HostName("gateway"),
+	}
+	for _, bestEffort := range []bool{false, true} {
+		res, err := NewResourceWithConfig(context.Background(), nil, &ResourceConfig{
+			BestEffortDetectors: bestEffort,
+			Attributes:          attrs,
+		})
+		if err != nil {
+			t.Fatalf("NewResourceWithConfig failed: %v", err)
+		}
+		for _, attr := range attrs {
+			if v, ok := res.Set().Value(attr.Key); !ok || v.AsString() != attr.Value.AsString() {
+				t.Errorf("best effort %v: expected %s=%s, got %q", bestEffort, attr.Key, attr.Value.AsString(), v.AsString())
+			}
+		}
+	}
+}
+
+func TestNewResourceClusterUID(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(&corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "6f1c0a44-uid"},
+	})
+
+	res, err := NewResourceWithConfig(ctx, nil, &ResourceConfig{Client: client})
+	if err != nil {
+		t.Fatalf("NewResourceWithConfig failed: %v", err)
+	}
+	if _, ok := res.Set().Value(semconv.K8SClusterUIDKey); ok {
+		t.Error("expected no k8s.cluster.uid by default")
+	}
+
+	res, err = NewResourceWithConfig(ctx, nil, &ResourceConfig{Client: client, ClusterUID: true})
+	if err != nil {
+		t.Fatalf("NewResourceWithConfig failed: %v", err)
+	}
+	if v, ok := res.Set().Value(semconv.K8SClusterUIDKey); !ok || v.AsString() != "6f1c0a44-uid" {
+		t.Errorf("expected k8s.cluster.uid 6f1c0a44-uid, got %q", v.AsString())
+	}
+
+	denied := fake.NewSimpleClientset()
+	denied.PrependReactor("get", "namespaces", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(corev1.Resource("namespaces"), "kube-system", errors.New("denied"))
+	})
+	res, err = NewResourceWithConfig(ctx, nil, &ResourceConfig{Client: denied, ClusterUID: true})
+	if err != nil {
+		t.Fatalf("expected no error when the read is denied, got %v", err)
+	}
+	if _, ok := res.Set().Value(semconv.K8SClusterUIDKey); ok {
+		t.Error("expected no k8s.cluster.uid when the read is denied")
+	}
+}
+
+func TestResourceClusterName(t *testing.T) {
+	if got := ResourceClusterName(nil); got != "" {
+		t.Errorf("expected empty name for nil resource, got %q", got)
+	}
+	if got := ResourceClusterName(resource.NewSchemaless(semconv.ServiceName("stern"))); got != "" {
+		t.Errorf("expected empty name without cluster, got %q", got)
+	}
+	res := resource.NewSchemaless(semconv.K8SClusterName("production"))
+	if got := ResourceClusterName(res); got != "production" {
+		t.Errorf("expected %q, got %q", "production", got)
+	}
+}
@@ -0,0 +1,90 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"go.opentelemetry.io/otel/log"
+)
+
+// DefaultSampleKeepSeverity is the severity kept in full when SampleConfig.KeepSeverity is unset
+const DefaultSampleKeepSeverity = "WARN"
+
+// SampleConfig configures severity-aware sampling: records at or above
+// KeepSeverity are all emitted while the others are sampled at Rate
+type SampleConfig struct {
+	// Rate is the fraction of the records below KeepSeverity that are
+	// emitted. Sampling is disabled when zero or one.
+	Rate float64
+	// KeepSeverity is the lowest severity never sampled, e.g. "WARN".
+	// Defaults to DefaultSampleKeepSeverity.
+	KeepSeverity string
+}
+
+// Enabled reports whether sampling is turned on
+func (c SampleConfig) Enabled() bool {
+	return c.Rate > 0 && c.Rate < 1
+}
+
+// validate checks the rate and the kept severity
+func (c SampleConfig) validate() error {
+	if c.Rate < 0 || c.Rate > 1 {
+		return fmt.Errorf("sample rate must be between 0 and 1, got %v", c.Rate)
+	}
+	if c.KeepSeverity != "" && mapSeverityToOTel(c.KeepSeverity) == log.SeverityUndefined {
+		return fmt.Errorf("unsupported sample keep severity: %s", c.KeepSeverity)
+	}
+	return nil
+}
+
+// sampler emits every record at or above the kept severity and an evenly
+// spread Rate of the others, including records without a severity. The
+// severity is the one of the emitted record, honoring the field keys, the
+// floor, the rules and the default severities of the transform, so that an
+// error is never sampled.
+type sampler struct {
+	rate      float64
+	keep      log.Severity
+	transform TransformConfig
+
+	mu   sync.Mutex
+	seen uint64 // records below the kept severity
+}
+
+// newSampler returns a sampler for a validated config
+func newSampler(config SampleConfig, transform TransformConfig) *sampler {
+	keep := config.KeepSeverity
+	if keep == "" {
+		keep = DefaultSampleKeepSeverity
+	}
+	return &sampler{rate: config.Rate, keep: mapSeverityToOTel(keep), transform: transform}
+}
+
+// admit reports whether the record must be emitted
+func (s *sampler) admit(record *LogRecord) bool {
+	if s.transform.severityOf(record) >= s.keep {
+		return true
+	}
+
+	// Emit a record whenever the running count of sampled records, seen*rate,
+	// reaches the next whole number
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seen++
+	return math.Floor(float64(s.seen)*s.rate) > math.Floor(float64(s.seen-1)*s.rate)
+}
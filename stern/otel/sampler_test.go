@@ -0,0 +1,117 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"testing"
+)
+
+func TestSamplerKeepsErrors(t *testing.T) {
+	s := newSampler(SampleConfig{Rate: 0.1}, TransformConfig{})
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		for _, level := range []string{"debug", "info", "warn", "error", "fatal"} {
+			if s.admit(&LogRecord{Body: `{"level":"` + level + `","msg":"hello"}`}) {
+				counts[level]++
+			}
+		}
+	}
+
+	for _, level := range []string{"warn", "error", "fatal"} {
+		if counts[level] != 1000 {
+			t.Errorf("expected all %s records to be kept, got %d", level, counts[level])
+		}
+	}
+	// debug and info share the 10% budget
+	if sampled := counts["debug"] + counts["info"]; sampled != 200 {
+		t.Errorf("expected 200 of the 2000 debug and info records, got %d", sampled)
+	}
+}
+
+func TestSamplerKeepSeverity(t *testing.T) {
+	s := newSampler(SampleConfig{Rate: 0.5, KeepSeverity: "ERROR"}, TransformConfig{})
+
+	var warns, plain int
+	for i := 0; i < 100; i++ {
+		if s.admit(&LogRecord{Body: `{"level":"warn","msg":"slow"}`}) {
+			warns++
+		}
+		if s.admit(&LogRecord{Body: "plain text"}) {
+			plain++
+		}
+		if !s.admit(&LogRecord{Body: `{"level":"error","msg":"failed"}`}) {
+			t.Fatal("expected errors to be kept")
+		}
+	}
+	if warns+plain != 100 {
+		t.Errorf("expected half of the warn and plain records, got %d", warns+plain)
+	}
+}
+
+func TestSamplerTransformSeverity(t *testing.T) {
+	rules, err := ParseSeverityRules([]string{"panic=fatal"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	transform := TransformConfig{
+		SeverityKeys:  []string{"lvl"},
+		SeverityRules: rules,
+		SeverityFloor: "WARN",
+	}
+	s := newSampler(SampleConfig{Rate: 0.01}, transform)
+
+	for i := 0; i < 100; i++ {
+		for _, body := range []string{
+			"panic: runtime error",
+			`{"lvl":"error","msg":"failed"}`,
+			`{"level":"debug","msg":"raised by the floor"}`,
+		} {
+			if !s.admit(&LogRecord{Body: body}) {
+				t.Fatalf("expected %q to be kept", body)
+			}
+		}
+	}
+	var plain int
+	for i := 0; i < 100; i++ {
+		if s.admit(&LogRecord{Body: "plain text"}) {
+			plain++
+		}
+	}
+	if plain != 1 {
+		t.Errorf("expected 1 of the 100 plain records, got %d", plain)
+	}
+}
+
+func TestSampleConfigValidate(t *testing.T) {
+	tests := []struct {
+		config  SampleConfig
+		wantErr bool
+	}{
+		{config: SampleConfig{}},
+		{config: SampleConfig{Rate: 0.25, KeepSeverity: "warn"}},
+		{config: SampleConfig{Rate: 1.5}, wantErr: true},
+		{config: SampleConfig{Rate: -0.1}, wantErr: true},
+		{config: SampleConfig{Rate: 0.5, KeepSeverity: "loud"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := tt.config.validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%+v: expected error %v, got %v", tt.config, tt.wantErr, err)
+		}
+	}
+	if (SampleConfig{Rate: 1}).Enabled() || (SampleConfig{}).Enabled() {
+		t.Error("expected rates of 0 and 1 to disable sampling")
+	}
+}
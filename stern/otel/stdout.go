@@ -0,0 +1,87 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// stdoutExporter prints the records as indented OTLP/JSON, to check the
+// attribute mapping and the batching without a collector
+type stdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// newStdoutExporter creates an exporter printing the records to w
+func newStdoutExporter(w io.Writer) *stdoutExporter {
+	return &stdoutExporter{w: w}
+}
+
+// newDryRunExporter creates an exporter printing the records to w, after
+// the attributes of the resource they share
+func newDryRunExporter(w io.Writer, res *resource.Resource) (*stdoutExporter, error) {
+	var attrs []otlpKeyValue
+	for _, kv := range res.Attributes() {
+		attrs = append(attrs, otlpKeyValue{Key: string(kv.Key), Value: otlpAttributeValue(kv.Value)})
+	}
+	data, err := json.MarshalIndent(struct {
+		Resource otlpResource `json:"resource"`
+	}{otlpResource{Attributes: attrs}}, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode resource: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "%s\n", data); err != nil {
+		return nil, err
+	}
+	return newStdoutExporter(w), nil
+}
+
+// Export prints the batch in a single write, so that the batches of
+// concurrent exports do not interleave
+func (e *stdoutExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	var buf bytes.Buffer
+	for i := range records {
+		data, err := json.MarshalIndent(otlpRecord(&records[i]), "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode record: %w", err)
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.w.Write(buf.Bytes())
+	return err
+}
+
+// Shutdown is a no-op since Export writes synchronously
+func (e *stdoutExporter) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+// ForceFlush is a no-op since Export writes synchronously
+func (e *stdoutExporter) ForceFlush(ctx context.Context) error {
+	return nil
+}
@@ -0,0 +1,139 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+func TestStdoutExporter(t *testing.T) {
+	var out bytes.Buffer
+	config := &ExporterConfig{BatchSize: 2, ExportTimeout: time.Minute}
+	exporter := newExporter(config, resource.Empty(), newStdoutExporter(&out))
+
+	exporter.Emit(context.Background(), &LogRecord{
+		Timestamp: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Body:      `{"level":"error","msg":"payment failed","order_id":42}`,
+		Namespace: "shop",
+		PodName:   "checkout-0",
+	})
+	exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "plain text"})
+	if err := exporter.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	printed := out.String()
+	if !strings.Contains(printed, "\n  \"body\"") {
+		t.Errorf("expected indented records, got %q", printed)
+	}
+
+	decoder := json.NewDecoder(&out)
+	var records []otlpLogRecord
+	for decoder.More() {
+		var record otlpLogRecord
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("expected OTLP/JSON records, got %q: %v", printed, err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(records))
+	}
+
+	record := records[0]
+	if *record.Body.StringValue != "payment failed" || record.SeverityNumber != 17 || record.TimeUnixNano != "1735689600000000000" {
+		t.Errorf("unexpected record %+v", record)
+	}
+	attrs := make(map[string]otlpAnyValue)
+	for _, kv := range record.Attributes {
+		attrs[kv.Key] = kv.Value
+	}
+	if pod := attrs["k8s.pod.name"]; pod.StringValue == nil || *pod.StringValue != "checkout-0" {
+		t.Errorf("expected the k8s.pod.name attribute, got %+v", attrs)
+	}
+	if orderID := attrs["order_id"]; orderID.IntValue == nil || *orderID.IntValue != "42" {
+		t.Errorf("expected the order_id attribute, got %+v", attrs)
+	}
+}
+
+func TestDryRunExporter(t *testing.T) {
+	var out bytes.Buffer
+	res := resource.NewSchemaless(attribute.String("service.name", "stern"))
+	logExporter, err := newDryRunExporter(&out, res)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config := &ExporterConfig{BatchSize: 2, ExportTimeout: time.Minute}
+	exporter := newExporter(config, res, logExporter)
+
+	for i := 0; i < 2; i++ {
+		exporter.Emit(context.Background(), &LogRecord{Timestamp: time.Now(), Body: "plain text"})
+	}
+	if err := exporter.ForceFlush(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	decoder := json.NewDecoder(&out)
+	var header struct {
+		Resource otlpResource `json:"resource"`
+	}
+	if err := decoder.Decode(&header); err != nil {
+		t.Fatalf("expected the resource first: %v", err)
+	}
+	if attrs := header.Resource.Attributes; len(attrs) != 1 || attrs[0].Key != "service.name" || *attrs[0].Value.StringValue != "stern" {
+		t.Errorf("unexpected resource attributes %+v", attrs)
+	}
+	var records int
+	for decoder.More() {
+		var record otlpLogRecord
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("expected OTLP/JSON records: %v", err)
+		}
+		if record.Body == nil || *record.Body.StringValue != "plain text" {
+			t.Errorf("unexpected record %+v", record)
+		}
+		records++
+	}
+	if records != 2 {
+		t.Errorf("expected 2 records, got %d", records)
+	}
+}
+
+func TestNewExporterDryRun(t *testing.T) {
+	// The endpoint is unreachable and the file protocol has no path, but
+	// neither is used
+	config := &ExporterConfig{
+		Endpoint:         "127.0.0.1:1",
+		Protocol:         "file",
+		BatchSize:        2,
+		PreflightTimeout: time.Second,
+		DryRun:           true,
+	}
+	exporter, err := NewExporter(context.Background(), config, resource.Empty())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Stats reports export statistics
+type Stats struct {
+	// Emitted is the number of records emitted to the batch processor,
+	// including the aggregation and deduplication counts and the records
+	// dropped once draining
+	Emitted int64
+	// Exported is the number of records the collector accepted
+	Exported int64
+	// Dropped is the number of records dropped because the queue of the
+	// batch processor was full, e.g. under bursts outpacing the collector,
+	// or because they were emitted once Drain began. Raise the queue size
+	// if records are dropped on a full queue.
+	Dropped int64
+	// Throttled is the number of export attempts the collector rejected
+	// as throttled (gRPC RESOURCE_EXHAUSTED, HTTP Retry-After)
+	Throttled int64
+	// Fallback is the number of records written to the fallback file
+	// because the collector failed to export them
+	Fallback int64
+	// Replayed is the number of records exported from the replay buffer once
+	// the collector recovered, Buffered the number still waiting in it and
+	// Overflowed the number dropped because it was full
+	Replayed   int64
+	Buffered   int64
+	Overflowed int64
+}
+
+// exportStats holds the counters behind Stats
+type exportStats struct {
+	throttled atomic.Int64
+}
+
+// Default retry schedule of failed exports, matching the defaults of the
+// OTLP exporters
+const (
+	retryInitialInterval = 5 * time.Second
+	retryMaxInterval     = 30 * time.Second
+	retryMaxElapsedTime  = time.Minute
+)
+
+// RetryConfig configures the retry of the exports failing with a transient
+// error. Zero intervals use the defaults of the OTLP exporters.
+type RetryConfig struct {
+	// Disabled drops the batches failing to export instead of retrying them
+	Disabled bool
+	// InitialInterval is the wait before the first retry, doubled after
+	// each attempt up to MaxInterval
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+	// MaxElapsedTime is the time after which a batch is no longer retried
+	MaxElapsedTime time.Duration
+}
+
+// validate checks that the intervals are not negative
+func (c RetryConfig) validate() error {
+	if c.InitialInterval < 0 || c.MaxInterval < 0 || c.MaxElapsedTime < 0 {
+		return fmt.Errorf("retry intervals must not be negative")
+	}
+	return nil
+}
+
+// withDefaults returns the configuration with the unset intervals defaulted
+func (c RetryConfig) withDefaults() RetryConfig {
+	if c.InitialInterval == 0 {
+		c.InitialInterval = retryInitialInterval
+	}
+	if c.MaxInterval == 0 {
+		c.MaxInterval = retryMaxInterval
+	}
+	if c.MaxElapsedTime == 0 {
+		c.MaxElapsedTime = retryMaxElapsedTime
+	}
+	return c
+}
+
+// otlploghttpPkg is the package of the retryable errors of the HTTP exporter
+const otlploghttpPkg = "go.opentelemetry.io/otel/exporters/otlp/otlplog/otlploghttp"
+
+// throttleInterceptor counts the gRPC calls rejected with RESOURCE_EXHAUSTED.
+// The gRPC exporter already waits for the delay of their RetryInfo.
+func throttleInterceptor(stats *exportStats) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if status.Code(err) == codes.ResourceExhausted {
+			stats.throttled.Add(1)
+		}
+		return err
+	}
+}
+
+// httpRetryable reports whether err is a retryable failure of the HTTP
+// exporter and the delay of its Retry-After header, if any. The exporter
+// keeps the header value, in seconds, in an unexported field, and takes no
+// HTTP client whose transport could read the header instead. Its shape is
+// pinned by TestHTTPRetryable.
+func httpRetryable(err error) (retryable bool, retryAfter time.Duration) {
+	v := reflect.ValueOf(err)
+	if !v.IsValid() || v.Kind() != reflect.Struct || v.Type().Name() != "retryableError" || v.Type().PkgPath() != otlploghttpPkg {
+		return false, 0
+	}
+	if throttle := v.FieldByName("throttle"); throttle.IsValid() && throttle.Kind() == reflect.Int64 {
+		retryAfter = time.Duration(throttle.Int()) * time.Second
+	}
+	return true, retryAfter
+}
+
+// retryAfterExporter retries the retryable failures of the HTTP exporter,
+// waiting for the delay of the Retry-After header when the collector sends
+// one. It replaces the retry of the exporter, which does not honor it.
+type retryAfterExporter struct {
+	sdklog.Exporter
+	stats *exportStats
+	retry RetryConfig
+	// retryable classifies export errors, httpRetryable unless overridden in tests
+	retryable func(error) (bool, time.Duration)
+}
+
+func newRetryAfterExporter(exporter sdklog.Exporter, stats *exportStats, retry RetryConfig) *retryAfterExporter {
+	return &retryAfterExporter{Exporter: exporter, stats: stats, retry: retry.withDefaults(), retryable: httpRetryable}
+}
+
+// Export exports the records, retrying retryable failures until the context
+// is done or the maximum elapsed time has passed
+func (e *retryAfterExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	deadline := time.Now().Add(e.retry.MaxElapsedTime)
+	backoff := e.retry.InitialInterval
+	for {
+		err := e.Exporter.Export(ctx, records)
+		retryable, retryAfter := e.retryable(err)
+		if !retryable {
+			return err
+		}
+
+		delay := backoff
+		if retryAfter > 0 {
+			e.stats.throttled.Add(1)
+			delay = retryAfter
+		} else {
+			backoff = min(2*backoff, e.retry.MaxInterval)
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return fmt.Errorf("max retry time elapsed: %w", err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestHTTPExporterRespectsRetryAfter(t *testing.T) {
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	stats := &exportStats{}
+	config := &ExporterConfig{
+		Endpoint:          strings.TrimPrefix(server.URL, "http://"),
+		Insecure:          true,
+		RespectRetryAfter: true,
+	}
+	exporter, err := newHTTPExporter(context.Background(), config, stats)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer exporter.Shutdown(context.Background())
+
+	var record sdklog.Record
+	record.SetBody(log.StringValue("throttled"))
+
+	start := time.Now()
+	if err := exporter.Export(context.Background(), []sdklog.Record{record}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if elapsed < time.Second {
+		t.Errorf("expected the export to wait for the 1s Retry-After, took %v", elapsed)
+	}
+	if elapsed > retryInitialInterval {
+		t.Errorf("expected the Retry-After instead of the backoff to be used, took %v", elapsed)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("expected 2 requests, got %d", got)
+	}
+	if got := stats.throttled.Load(); got != 1 {
+		t.Errorf("expected 1 throttled export, got %d", got)
+	}
+}
+
+// TestHTTPRetryable pins the shape of the retryable errors of the HTTP
+// exporter, which httpRetryable reads by reflection, so that an upgrade of
+// the exporter changing it fails here instead of ignoring Retry-After
+func TestHTTPRetryable(t *testing.T) {
+	tests := []struct {
+		name               string
+		status             int
+		retryAfter         string
+		expectedRetryable  bool
+		expectedRetryAfter time.Duration
+	}{
+		{"throttled", http.StatusTooManyRequests, "7", true, 7 * time.Second},
+		{"unavailable without delay", http.StatusServiceUnavailable, "", true, 0},
+		{"rejected", http.StatusBadRequest, "", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			// Without retries, the exporter returns its own error
+			config := &ExporterConfig{
+				Endpoint: strings.TrimPrefix(server.URL, "http://"),
+				Insecure: true,
+				Retry:    RetryConfig{Disabled: true},
+			}
+			exporter, err := newHTTPExporter(context.Background(), config, &exportStats{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer exporter.Shutdown(context.Background())
+
+			err = exporter.Export(context.Background(), make([]sdklog.Record, 1))
+			if err == nil {
+				t.Fatal("expected the export to fail")
+			}
+			retryable, retryAfter := httpRetryable(err)
+			if retryable != tt.expectedRetryable || retryAfter != tt.expectedRetryAfter {
+				t.Errorf("expected retryable %v after %v, got %v after %v for %#v: update httpRetryable to the errors of %s",
+					tt.expectedRetryable, tt.expectedRetryAfter, retryable, retryAfter, err, otlploghttpPkg)
+			}
+		})
+	}
+}
+
+func TestRetryAfterExporterGivesUp(t *testing.T) {
+	mockExporter := &failingLogRecordExporter{err: errors.New("throttled")}
+	exporter := newRetryAfterExporter(mockExporter, &exportStats{}, RetryConfig{})
+	exporter.retryable = func(err error) (bool, time.Duration) {
+		return err != nil, 2 * time.Minute
+	}
+
+	// A delay beyond the maximum elapsed time is not waited for
+	if err := exporter.Export(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "max retry time elapsed") {
+		t.Errorf("expected the retry to give up, got %v", err)
+	}
+	if mockExporter.calls != 1 {
+		t.Errorf("expected 1 attempt, got %d", mockExporter.calls)
+	}
+}
+
+func TestThrottleInterceptor(t *testing.T) {
+	stats := &exportStats{}
+	interceptor := throttleInterceptor(stats)
+
+	for _, code := range []codes.Code{codes.ResourceExhausted, codes.OK, codes.Unavailable, codes.ResourceExhausted} {
+		invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return status.Error(code, "")
+		}
+		_ = interceptor(context.Background(), "/Export", nil, nil, nil, invoker)
+	}
+
+	if got := stats.throttled.Load(); got != 2 {
+		t.Errorf("expected 2 throttled calls, got %d", got)
+	}
+	if got := (&Exporter{stats: stats}).Stats().Throttled; got != 2 {
+		t.Errorf("expected Stats to report 2 throttled calls, got %d", got)
+	}
+}
+
+// failingLogRecordExporter fails every export with err
+type failingLogRecordExporter struct {
+	mockLogRecordExporter
+	err   error
+	calls int
+}
+
+func (f *failingLogRecordExporter) Export(ctx context.Context, records []sdklog.Record) error {
+	f.calls++
+	return f.err
+}
+
+func TestHTTPExporterRetryConfig(t *testing.T) {
+	tests := []struct {
+		name              string
+		retry             RetryConfig
+		respectRetryAfter bool
+		expectedRequests  int64
+		expectedError     bool
+	}{
+		{
+			name:             "short schedule",
+			retry:            RetryConfig{InitialInterval: 10 * time.Millisecond, MaxInterval: 20 * time.Millisecond},
+			expectedRequests: 3,
+		},
+		{
+			name:              "short schedule honoring Retry-After",
+			retry:             RetryConfig{InitialInterval: 10 * time.Millisecond, MaxInterval: 20 * time.Millisecond},
+			respectRetryAfter: true,
+			expectedRequests:  3,
+		},
+		{
+			name:             "disabled",
+			retry:            RetryConfig{Disabled: true},
+			expectedRequests: 1,
+			expectedError:    true,
+		},
+		{
+			name:              "disabled honoring Retry-After",
+			retry:             RetryConfig{Disabled: true},
+			respectRetryAfter: true,
+			expectedRequests:  1,
+			expectedError:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The collector is unavailable for the first two requests
+			var requests atomic.Int64
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) <= 2 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.Header().Set("Content-Type", "application/x-protobuf")
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			config := &ExporterConfig{
+				Endpoint:          strings.TrimPrefix(server.URL, "http://"),
+				Insecure:          true,
+				RespectRetryAfter: tt.respectRetryAfter,
+				Retry:             tt.retry,
+			}
+			exporter, err := newHTTPExporter(context.Background(), config, &exportStats{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer exporter.Shutdown(context.Background())
+
+			var record sdklog.Record
+			record.SetBody(log.StringValue("retried"))
+
+			start := time.Now()
+			err = exporter.Export(context.Background(), []sdklog.Record{record})
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error %v, got %v", tt.expectedError, err)
+			}
+			if elapsed := time.Since(start); elapsed > retryInitialInterval {
+				t.Errorf("expected the configured schedule instead of the default one, took %v", elapsed)
+			}
+			if got := requests.Load(); got != tt.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tt.expectedRequests, got)
+			}
+		})
+	}
+
+	if err := (RetryConfig{MaxElapsedTime: -time.Second}).validate(); err == nil {
+		t.Error("expected error for a negative interval")
+	}
+}
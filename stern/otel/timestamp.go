@@ -0,0 +1,95 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"math"
+	"strconv"
+	"time"
+)
+
+// DefaultTimestampFields are the fields holding the event time in common
+// structured loggers: Zap, Logrus and Bunyan, Pino and Logstash
+var DefaultTimestampFields = []string{"ts", "time", "timestamp", "@timestamp"}
+
+// Layouts of the timestamp strings, besides epoch numbers
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999",
+}
+
+// extractTimestamp returns the time of the first of the fields holding a
+// parseable timestamp, and removes it from the structured attributes unless
+// keep is set
+func extractTimestamp(structuredAttrs map[string]interface{}, fields []string, keep bool) (time.Time, bool) {
+	for _, field := range fields {
+		value, ok := structuredAttrs[field]
+		if !ok {
+			continue
+		}
+		if t, ok := parseTimestamp(value); ok {
+			if !keep {
+				delete(structuredAttrs, field)
+			}
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseTimestamp parses an RFC 3339 string, or epoch seconds, milliseconds,
+// microseconds or nanoseconds told apart by their magnitude
+func parseTimestamp(value interface{}) (time.Time, bool) {
+	switch val := value.(type) {
+	case float64:
+		return epochTime(val)
+	case string:
+		for _, layout := range timestampLayouts {
+			if t, err := time.Parse(layout, val); err == nil {
+				return t, true
+			}
+		}
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return epochTime(f)
+		}
+	}
+	return time.Time{}, false
+}
+
+// epochTime converts an epoch number to a time. Numbers of up to 11 digits
+// are seconds, which covers dates until the year 5138.
+func epochTime(epoch float64) (time.Time, bool) {
+	if epoch <= 0 || math.IsInf(epoch, 0) || math.IsNaN(epoch) {
+		return time.Time{}, false
+	}
+	var nanos float64
+	switch {
+	case epoch < 1e11:
+		nanos = epoch * 1e9
+	case epoch < 1e14:
+		nanos = epoch * 1e6
+	case epoch < 1e17:
+		nanos = epoch * 1e3
+	default:
+		nanos = epoch
+	}
+	if nanos > math.MaxInt64 {
+		return time.Time{}, false
+	}
+	sec, frac := math.Modf(nanos / 1e9)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9))), true
+}
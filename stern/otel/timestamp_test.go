@@ -0,0 +1,150 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/log"
+	sdklog "go.opentelemetry.io/otel/sdk/log"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	expected := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected time.Time
+		ok       bool
+	}{
+		{"RFC 3339", "2025-01-02T03:04:05Z", expected, true},
+		{"RFC 3339 with nanoseconds", "2025-01-02T03:04:05.123456789Z", expected.Add(123456789), true},
+		{"RFC 3339 with offset", "2025-01-02T04:04:05+01:00", expected, true},
+		{"Log4j offset", "2025-01-02T04:04:05.000+0100", expected, true},
+		{"space separated", "2025-01-02 03:04:05Z", expected, true},
+		{"without zone", "2025-01-02T03:04:05.5", expected.Add(500 * time.Millisecond), true},
+		{"epoch seconds", float64(expected.Unix()), expected, true},
+		{"fractional epoch seconds", float64(expected.Unix()) + 0.25, expected.Add(250 * time.Millisecond), true},
+		{"epoch milliseconds", float64(expected.UnixMilli()), expected, true},
+		{"epoch microseconds", float64(expected.UnixMicro()), expected, true},
+		{"epoch seconds string", "1735787045", expected, true},
+		{"not a timestamp", "yesterday", time.Time{}, false},
+		{"negative", float64(-1), time.Time{}, false},
+		{"boolean", true, time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseTimestamp(tt.value)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, expected %v", ok, tt.ok)
+			}
+			// Epoch numbers lose precision below the microsecond
+			if ok && got.Sub(tt.expected).Abs() > time.Microsecond {
+				t.Errorf("parseTimestamp(%v) = %v, expected %v", tt.value, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEmitLogWithBodyTimestamp(t *testing.T) {
+	containerTime := time.Date(2025, 1, 2, 3, 4, 10, 0, time.UTC)
+	eventTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name              string
+		body              string
+		config            *TransformConfig
+		expectedTimestamp time.Time
+		expectedObserved  time.Time
+		expectedField     string
+	}{
+		{
+			name:              "consumed field",
+			body:              `{"ts":"2025-01-02T03:04:05Z","msg":"started"}`,
+			config:            &TransformConfig{TimestampFields: DefaultTimestampFields},
+			expectedTimestamp: eventTime,
+			expectedObserved:  containerTime,
+		},
+		{
+			name:              "kept field",
+			body:              `{"@timestamp":"2025-01-02T03:04:05Z","msg":"started"}`,
+			config:            &TransformConfig{TimestampFields: DefaultTimestampFields, KeepTimestampField: true},
+			expectedTimestamp: eventTime,
+			expectedObserved:  containerTime,
+			expectedField:     "@timestamp",
+		},
+		{
+			name:              "epoch milliseconds before noise removal",
+			body:              `{"time":1735787045000,"msg":"started"}`,
+			config:            &TransformConfig{TimestampFields: DefaultTimestampFields, DropFields: NoiseFields},
+			expectedTimestamp: eventTime,
+			expectedObserved:  containerTime,
+		},
+		{
+			name:              "unparseable field",
+			body:              `{"ts":"soon","msg":"started"}`,
+			config:            &TransformConfig{TimestampFields: DefaultTimestampFields},
+			expectedTimestamp: containerTime,
+			expectedField:     "ts",
+		},
+		{
+			name:              "disabled",
+			body:              `{"ts":"2025-01-02T03:04:05Z","msg":"started"}`,
+			config:            &TransformConfig{},
+			expectedTimestamp: containerTime,
+			expectedField:     "ts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			processor := sdklog.NewSimpleProcessor(mockExporter)
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+
+			EmitLogWithConfig(context.Background(), provider.Logger("test"), &LogRecord{Timestamp: containerTime, Body: tt.body}, tt.config)
+			provider.ForceFlush(context.Background())
+
+			if len(mockExporter.records) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+			}
+			record := mockExporter.records[0]
+			if !record.Timestamp().Equal(tt.expectedTimestamp) {
+				t.Errorf("timestamp = %v, expected %v", record.Timestamp(), tt.expectedTimestamp)
+			}
+			if !tt.expectedObserved.IsZero() && !record.ObservedTimestamp().Equal(tt.expectedObserved) {
+				t.Errorf("observed timestamp = %v, expected %v", record.ObservedTimestamp(), tt.expectedObserved)
+			}
+
+			var fields []string
+			record.WalkAttributes(func(kv log.KeyValue) bool {
+				switch kv.Key {
+				case "ts", "time", "timestamp", "@timestamp":
+					fields = append(fields, kv.Key)
+				}
+				return true
+			})
+			if tt.expectedField == "" && len(fields) > 0 {
+				t.Errorf("expected the timestamp field to be removed, got %v", fields)
+			}
+			if tt.expectedField != "" && (len(fields) != 1 || fields[0] != tt.expectedField) {
+				t.Errorf("expected the %s attribute, got %v", tt.expectedField, fields)
+			}
+		})
+	}
+}
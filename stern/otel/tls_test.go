@@ -0,0 +1,146 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package otel
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel/sdk/resource"
+)
+
+// writeCertificate writes a self-signed certificate and its key to dir as
+// cert.pem and key.pem and returns their paths
+func writeCertificate(t *testing.T, dir string) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "stern test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	certFile := filepath.Join(dir, "cert.pem")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to encode key: %v", err)
+	}
+	keyFile := filepath.Join(dir, "key.pem")
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	if tlsConfig, err := (&ExporterConfig{}).tlsConfig(); tlsConfig != nil || err != nil {
+		t.Errorf("expected no TLS configuration without a CA certificate, got %v, %v", tlsConfig, err)
+	}
+
+	certFile, _ := writeCertificate(t, dir)
+	tlsConfig, err := (&ExporterConfig{CACertFile: certFile}).tlsConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tlsConfig == nil || tlsConfig.RootCAs == nil || tlsConfig.RootCAs.Equal(x509.NewCertPool()) {
+		t.Errorf("expected the CA certificate in the root CAs, got %v", tlsConfig)
+	}
+
+	garbage := filepath.Join(dir, "garbage.pem")
+	if err := os.WriteFile(garbage, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		file     string
+		expected string
+	}{
+		{filepath.Join(dir, "missing.pem"), "failed to read CA certificate"},
+		{garbage, "failed to parse CA certificate"},
+	} {
+		if _, err := (&ExporterConfig{CACertFile: tt.file}).tlsConfig(); err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: expected error %q, got %v", tt.file, tt.expected, err)
+		}
+	}
+
+	// The exporters fail to be created with an unreadable certificate
+	for _, protocol := range []string{"grpc", "http"} {
+		config := &ExporterConfig{Endpoint: "localhost:4317", Protocol: protocol, Insecure: true, CACertFile: garbage, BatchSize: 1}
+		if _, err := NewExporter(context.Background(), config, resource.Empty()); err == nil {
+			t.Errorf("%s: expected error for an invalid CA certificate", protocol)
+		}
+	}
+}
+
+func TestTLSConfigClientCertificate(t *testing.T) {
+	certFile, keyFile := writeCertificate(t, t.TempDir())
+
+	tlsConfig, err := (&ExporterConfig{ClientCertFile: certFile, ClientKeyFile: keyFile}).tlsConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tlsConfig.Certificates) != 1 || tlsConfig.RootCAs != nil {
+		t.Errorf("expected the client certificate with the system roots, got %v", tlsConfig)
+	}
+
+	tlsConfig, err = (&ExporterConfig{CACertFile: certFile, ClientCertFile: certFile, ClientKeyFile: keyFile}).tlsConfig()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(tlsConfig.Certificates) != 1 || tlsConfig.RootCAs == nil {
+		t.Errorf("expected the client certificate with the CA certificate, got %v", tlsConfig)
+	}
+
+	for _, config := range []*ExporterConfig{
+		{ClientCertFile: certFile},
+		{ClientKeyFile: keyFile},
+	} {
+		if _, err := config.tlsConfig(); err == nil || !strings.Contains(err.Error(), "must be set together") {
+			t.Errorf("expected error for a lone client certificate or key, got %v", err)
+		}
+	}
+
+	// The key must match the certificate
+	otherCert, _ := writeCertificate(t, t.TempDir())
+	if _, err := (&ExporterConfig{ClientCertFile: otherCert, ClientKeyFile: keyFile}).tlsConfig(); err == nil || !strings.Contains(err.Error(), "failed to load client certificate") {
+		t.Errorf("expected error for a mismatched key, got %v", err)
+	}
+}
@@ -16,11 +16,23 @@ package otel
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"maps"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"go.opentelemetry.io/otel/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // LogRecord represents a log entry with metadata
@@ -29,10 +41,524 @@ type LogRecord struct {
 	Body          string
 	Namespace     string
 	PodName       string
+	PodUID        string
 	ContainerName string
 	NodeName      string
 	Labels        map[string]string
 	Annotations   map[string]string
+	// LineIndex is the position of the line among the lines of the
+	// container sharing the same timestamp second
+	LineIndex int
+	// Matches holds the substrings matched by the include filters
+	Matches []string
+	// Owners is the chain of controllers owning the pod, nearest first
+	Owners []Owner
+	// QOSClass and Priority are the pod's QoS class and priority, if set
+	QOSClass string
+	Priority *int32
+	// Image, ImageID and RestartCount come from the status of the
+	// container, if present
+	Image        string
+	ImageID      string
+	RestartCount *int32
+	// Heartbeat marks a record emitted while the container is silent;
+	// LastSeen is when its last line arrived
+	Heartbeat bool
+	LastSeen  time.Time
+	// TailedContainers is the number of containers tailed by the session
+	// when the record was read, if counted
+	TailedContainers int64
+	// LineNumber is the number of the line among the lines of the tail,
+	// if numbered
+	LineNumber int64
+	// Previous marks a line of the previous instance of the container
+	Previous bool
+	// RepeatCount is the number of repeats of the line collapsed into the
+	// record, if any
+	RepeatCount int64
+}
+
+// Owner identifies a controller owning a pod, e.g. a Job or its CronJob
+type Owner struct {
+	Kind string
+	Name string
+}
+
+// EnrichFunc computes additional attributes for a record. structuredAttrs
+// holds the fields parsed from a structured log and is nil for plain text.
+type EnrichFunc func(record *LogRecord, structuredAttrs map[string]interface{}) []log.KeyValue
+
+// HookFunc reshapes the OTel record of a record once all of its fields and
+// attributes are set, just before it is emitted. RewriteAttributes renames
+// or drops attributes.
+type HookFunc func(record *LogRecord, logRecord *log.Record)
+
+// TransformConfig controls how EmitLogWithConfig shapes log records.
+// The zero value keeps the default behavior of EmitLog.
+type TransformConfig struct {
+	// Enrich is invoked after the built-in extraction and the attributes it
+	// returns are appended to the record
+	Enrich EnrichFunc
+	// Hook is invoked once per record after the built-in attribute
+	// population and before the record is emitted
+	Hook HookFunc
+	// RecordID adds a deterministic log.record.id attribute for idempotent ingestion
+	RecordID bool
+	// TaggedLogs parses Rails-style leading bracket tags ("[request-id] [tenant] message")
+	// into attributes when the body is not JSON
+	TaggedLogs bool
+	// TagNames names the tag attributes by position; unnamed tags use "tag.<index>"
+	TagNames []string
+	// Klog parses the klog and glog lines of the Kubernetes components
+	// ("I0101 12:00:00.000000 1 file.go:10] message") into their severity,
+	// caller attribute and message when the body is not JSON or logfmt
+	Klog bool
+	// MaxJSONDepth is the maximum nesting depth of structured logs. Deeper
+	// payloads are kept as a plain body. Defaults to DefaultMaxJSONDepth.
+	MaxJSONDepth int
+	// LabelAttributes promotes pod labels to the named attributes, e.g.
+	// "team" to "service.team", instead of k8s.pod.label.<key>
+	LabelAttributes map[string]string
+	// AnnotationAttributes promotes pod annotations to the named attributes,
+	// e.g. "example.com/tenant" to "tenant.id" for the collector to route
+	// the records by tenant, instead of k8s.pod.annotation.<key>
+	AnnotationAttributes map[string]string
+	// SeverityFloor raises the severity of records below it, e.g. "INFO"
+	// turns DEBUG and TRACE records into INFO ones. Records without a
+	// severity are left unchanged.
+	SeverityFloor string
+	// MinSeverity skips the records below it, e.g. "WARN" emits only the
+	// warnings and errors, after the severity rules and the floor apply.
+	// The records without a severity, e.g. plain text, are kept unless
+	// DropUnleveled is set. Heartbeats are always kept.
+	MinSeverity   string
+	DropUnleveled bool
+	// Identity is set as the stern.identity attribute to record who
+	// configured the tail, see ResolveIdentity
+	Identity string
+	// FieldObjects names the sub-objects of structured logs, e.g. "extra" or
+	// "attributes", whose fields are emitted as top-level attributes
+	FieldObjects []string
+	// SeverityRules override the severity of the lines they match, e.g. FATAL
+	// for lines matching "panic". The first matching rule wins.
+	SeverityRules []SeverityRule
+	// DefaultSeverityRules set the severity of the lines without a level
+	// they match, e.g. ERROR for lines matching "ERROR" in the plain text of
+	// legacy apps. The first matching rule wins. Lines carrying a level are
+	// left unchanged.
+	DefaultSeverityRules []SeverityRule
+	// ContainerSeverities map container names to the severity of their lines
+	// without a level matching no DefaultSeverityRules, and DefaultSeverity
+	// is the severity of the lines of the other containers
+	ContainerSeverities map[string]string
+	DefaultSeverity     string
+	// EmbeddedJSON parses a JSON object trailing a text prefix, e.g.
+	// `2025-01-01 INFO handler: {"user":"alice"}`, when the body is not JSON.
+	// It is one of the EmbeddedJSON* modes choosing the body; empty disables it.
+	EmbeddedJSON string
+	// DurationFields names the fields of structured logs, e.g. "duration" or
+	// "latency", whose values with a unit such as "15ms" or "1.2s" are
+	// replaced by a duration.ms attribute. The first parseable field wins.
+	DurationFields []string
+	// CoerceTypes adds a numeric attribute next to the string fields of
+	// structured logs holding a Go duration or an RFC 3339 timestamp, e.g.
+	// latency_ns=1200000000 for "latency":"1.2s" and deadline_epoch_ms for
+	// "deadline":"2025-01-01T00:00:00Z", for numeric and temporal queries
+	CoerceTypes bool
+	// RenderMessage renders a message field of structured logs that is not a
+	// string, e.g. {"template":"...","args":[...]}, to the body. It is one of
+	// the RenderMessage* modes; empty keeps the whole JSON as the body.
+	RenderMessage string
+	// ContainerFQNSeparator joins the namespace, pod and container into a
+	// k8s.container.fqn attribute, e.g. "ns/pod/container" with "/". The
+	// attribute is omitted when empty.
+	ContainerFQNSeparator string
+	// LoggerField names the field of structured logs holding the logger name,
+	// e.g. Zap's "logger", moved to the LoggerAttribute attribute
+	LoggerField string
+	// LoggerAttribute is the attribute of the logger name, e.g.
+	// "code.namespace". Defaults to DefaultLoggerAttribute.
+	LoggerAttribute string
+	// LoggerServiceName uses the last segment of the logger name, e.g.
+	// "boho-api" for "statler.server.boho-api", as service.name
+	LoggerServiceName bool
+	// StatefulSetOrdinal adds the ordinal of the pods owned by a StatefulSet,
+	// e.g. 1 for "web-1", as k8s.statefulset.pod_ordinal
+	StatefulSetOrdinal bool
+	// ContainerStatus adds the image and the restart count of the container,
+	// e.g. to pinpoint a bad rollout, as container.image.name,
+	// container.image.tags, container.image.id and k8s.container.restart_count
+	ContainerStatus bool
+	// BodyHash adds a log.body.hash attribute fingerprinting the body with
+	// one of the BodyHash* algorithms, e.g. for deduplication by the
+	// backend. Empty disables it.
+	BodyHash string
+	// URLFields names the fields of structured logs holding a URL, e.g.
+	// "url" or "request_uri", whose query parameters are added as
+	// query.<name> attributes. The fields are kept.
+	URLFields []string
+	// DropFields names the fields of structured logs left out of the
+	// attributes, e.g. NoiseFields
+	DropFields []string
+	// MessageKeys and SeverityKeys name the fields of structured logs
+	// holding the message and the level, e.g. "event" and "log.level". They
+	// are tried in order before DefaultMessageKeys and DefaultSeverityKeys.
+	MessageKeys  []string
+	SeverityKeys []string
+	// FlattenDepth flattens up to this many levels of the objects and arrays
+	// of structured logs into attributes with dotted keys, e.g.
+	// resource.service.name or tags.0. Deeper values are sent as JSON
+	// strings, like all the nested values when it is zero.
+	FlattenDepth int
+	// KeepNulls adds the null fields of structured logs as empty values,
+	// which are left out by default so that they are not mistaken for empty
+	// strings
+	KeepNulls bool
+	// NumericSeverity is the scale of numeric levels, one of the
+	// NumericSeverity* scales. Defaults to NumericSeverityBunyan.
+	NumericSeverity string
+	// KeepRaw adds the line of the structured logs as log.record.original,
+	// e.g. to debug the field extraction on the backend
+	KeepRaw bool
+	// IncludeLabels and ExcludeLabels select the pod labels added as
+	// k8s.pod.label.<key> with patterns such as "app.kubernetes.io/*", where
+	// "*" matches any text and "?" any character. A label is added when it
+	// matches an include pattern, or when there are none, unless it matches
+	// an exclude pattern. Labels of LabelAttributes are always added.
+	IncludeLabels []string
+	ExcludeLabels []string
+	// IncludeAnnotations and ExcludeAnnotations select the pod annotations
+	// added as k8s.pod.annotation.<key> the same way
+	IncludeAnnotations []string
+	ExcludeAnnotations []string
+	// NoLabels and NoAnnotations leave out all the pod labels and
+	// annotations, e.g. multi-kilobyte last-applied configurations inflating
+	// every record, but the ones of LabelAttributes and AnnotationAttributes
+	NoLabels      bool
+	NoAnnotations bool
+	// ClusterName is set as the k8s.cluster.name attribute of every record,
+	// e.g. the one of the resource, see ResourceClusterName, for the
+	// backends that do not propagate the resource attributes to the records.
+	// Empty leaves the cluster to the resource.
+	ClusterName string
+	// LabelPrefix and AnnotationPrefix prefix the keys of the pod labels and
+	// annotations added as attributes, e.g. "kube.label.". An empty prefix
+	// keeps the raw keys. Nil uses DefaultLabelPrefix and
+	// DefaultAnnotationPrefix.
+	LabelPrefix      *string
+	AnnotationPrefix *string
+	// RedactKeys names the fields of structured logs, e.g. SensitiveKeys,
+	// whose values are replaced by RedactedValue. Keys are compared ignoring
+	// case, and the fields of nested objects are redacted too.
+	RedactKeys []string
+	// RedactPatterns match the text replaced by RedactedValue in the body and
+	// the string fields, e.g. email addresses
+	RedactPatterns []*regexp.Regexp
+	// RedactDrop drops the fields of RedactKeys instead of replacing their values
+	RedactDrop bool
+	// TimestampFields names the fields of structured logs holding the event
+	// time, e.g. DefaultTimestampFields, tried in order. The first parseable
+	// one is the record timestamp instead of the container time, which
+	// becomes the observed timestamp. Empty disables it.
+	TimestampFields []string
+	// KeepTimestampField keeps the field of the record timestamp in the
+	// attributes instead of removing it
+	KeepTimestampField bool
+	// ComposeServiceName names the service after its app.kubernetes.io/name,
+	// or app.kubernetes.io/instance, and app.kubernetes.io/component labels,
+	// e.g. "postgresql/primary", and adds service.namespace and
+	// service.instance.id from the namespace and the pod name
+	ComposeServiceName bool
+}
+
+// Fields holding the message and the level of structured logs, tried in order
+var (
+	DefaultMessageKeys  = []string{"msg", "message", "Message"}
+	DefaultSeverityKeys = []string{"level", "severity", "levelname"}
+)
+
+// fieldKeys names the message and level fields of structured logs, and the
+// scale of numeric levels
+type fieldKeys struct {
+	message         []string
+	severity        []string
+	numericSeverity string
+}
+
+// defaultFieldKeys are the fields of structured logs without configured keys
+var defaultFieldKeys = fieldKeys{message: DefaultMessageKeys, severity: DefaultSeverityKeys, numericSeverity: NumericSeverityBunyan}
+
+// fieldKeys returns the configured message and level fields followed by the defaults
+func (c TransformConfig) fieldKeys() fieldKeys {
+	keys := defaultFieldKeys
+	if len(c.MessageKeys) > 0 {
+		keys.message = append(slices.Clip(c.MessageKeys), DefaultMessageKeys...)
+	}
+	if len(c.SeverityKeys) > 0 {
+		keys.severity = append(slices.Clip(c.SeverityKeys), DefaultSeverityKeys...)
+	}
+	if c.NumericSeverity != "" {
+		keys.numericSeverity = c.NumericSeverity
+	}
+	return keys
+}
+
+// NoiseFields are the fields of common structured loggers that duplicate
+// the Kubernetes attributes or the record timestamp: the process ID, host
+// name, Bunyan's format version, time and logger name
+var NoiseFields = []string{"pid", "hostname", "v", "time", "name"}
+
+// Algorithms of the log.body.hash attribute
+const (
+	BodyHashFNV    = "fnv"
+	BodyHashSHA256 = "sha256"
+)
+
+// DefaultLoggerAttribute is the attribute used when TransformConfig.LoggerAttribute is unset
+const DefaultLoggerAttribute = "component"
+
+// Default prefixes of the pod label and annotation attributes
+const (
+	DefaultLabelPrefix      = "k8s.pod.label."
+	DefaultAnnotationPrefix = "k8s.pod.annotation."
+)
+
+// Renderings of the message fields that are not strings
+const (
+	// RenderMessageJSON renders the field as JSON
+	RenderMessageJSON = "json"
+	// RenderMessageTemplate fills the "{}" and "{N}" placeholders of the
+	// "template" or "format" of the field with its "args", falling back to JSON
+	RenderMessageTemplate = "template"
+)
+
+// Bodies of the lines with an embedded JSON object
+const (
+	// EmbeddedJSONPrefix keeps the text prefix as the body
+	EmbeddedJSONPrefix = "prefix"
+	// EmbeddedJSONMessage uses the message of the JSON object, or the text
+	// prefix when it has none
+	EmbeddedJSONMessage = "message"
+)
+
+// Scales of numeric levels
+const (
+	// NumericSeverityBunyan is the scale of Bunyan and Pino, from 10 (trace)
+	// to 60 (fatal)
+	NumericSeverityBunyan = "bunyan"
+	// NumericSeveritySyslog is the scale of syslog, from 0 (emergency) to
+	// 7 (debug)
+	NumericSeveritySyslog = "syslog"
+)
+
+// SeverityRule sets Severity on the lines matching Pattern
+type SeverityRule struct {
+	Pattern  *regexp.Regexp
+	Severity string
+}
+
+// ParseSeverityRules parses rules of the form "<regex>=<severity>", e.g.
+// "panic=fatal". The severity follows the last '=' so that the expression
+// may contain one.
+func ParseSeverityRules(specs []string) ([]SeverityRule, error) {
+	rules := make([]SeverityRule, 0, len(specs))
+	for _, spec := range specs {
+		i := strings.LastIndex(spec, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid severity rule %q: expected <regex>=<severity>", spec)
+		}
+		pattern, err := regexp.Compile(spec[:i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid severity rule %q: %w", spec, err)
+		}
+		rules = append(rules, SeverityRule{Pattern: pattern, Severity: spec[i+1:]})
+	}
+	return rules, nil
+}
+
+// validate checks the severity floor and minimum, the severities of the rules and the
+// embedded JSON, message rendering and body hash modes
+func (c TransformConfig) validate() error {
+	if c.SeverityFloor != "" && mapSeverityToOTel(c.SeverityFloor) == log.SeverityUndefined {
+		return fmt.Errorf("unsupported severity floor: %s", c.SeverityFloor)
+	}
+	if c.MinSeverity != "" && mapSeverityToOTel(c.MinSeverity) == log.SeverityUndefined {
+		return fmt.Errorf("unsupported minimum severity: %s", c.MinSeverity)
+	}
+	switch c.EmbeddedJSON {
+	case "", EmbeddedJSONPrefix, EmbeddedJSONMessage:
+	default:
+		return fmt.Errorf("unsupported embedded JSON mode: %s", c.EmbeddedJSON)
+	}
+	switch c.BodyHash {
+	case "", BodyHashFNV, BodyHashSHA256:
+	default:
+		return fmt.Errorf("unsupported body hash algorithm: %s", c.BodyHash)
+	}
+	switch c.RenderMessage {
+	case "", RenderMessageJSON, RenderMessageTemplate:
+	default:
+		return fmt.Errorf("unsupported message rendering: %s", c.RenderMessage)
+	}
+	switch c.NumericSeverity {
+	case "", NumericSeverityBunyan, NumericSeveritySyslog:
+	default:
+		return fmt.Errorf("unsupported numeric severity scale: %s", c.NumericSeverity)
+	}
+	for _, rule := range slices.Concat(c.SeverityRules, c.DefaultSeverityRules) {
+		if mapSeverityToOTel(rule.Severity) == log.SeverityUndefined {
+			return fmt.Errorf("unsupported severity in rule %q: %s", rule.Pattern, rule.Severity)
+		}
+	}
+	for container, severity := range c.ContainerSeverities {
+		if mapSeverityToOTel(severity) == log.SeverityUndefined {
+			return fmt.Errorf("unsupported severity of container %s: %s", container, severity)
+		}
+	}
+	if c.DefaultSeverity != "" && mapSeverityToOTel(c.DefaultSeverity) == log.SeverityUndefined {
+		return fmt.Errorf("unsupported default severity: %s", c.DefaultSeverity)
+	}
+	return nil
+}
+
+// ruleSeverity returns the severity of the first rule matching body
+func ruleSeverity(rules []SeverityRule, body string) (string, bool) {
+	for _, rule := range rules {
+		if rule.Pattern.MatchString(body) {
+			return rule.Severity, true
+		}
+	}
+	return "", false
+}
+
+// resolveSeverity returns the severity text and number of the record given
+// the level extracted from its line, if any. SeverityRules override the
+// level, while the default severities only apply without one. A record
+// raised to SeverityFloor takes its text too. The text is empty when the
+// record has no severity.
+func (c TransformConfig) resolveSeverity(record *LogRecord, level string) (string, log.Severity) {
+	if severity, ok := ruleSeverity(c.SeverityRules, record.Body); ok {
+		return severity, mapSeverityToOTel(severity)
+	}
+
+	if level == "" {
+		if severity, ok := ruleSeverity(c.DefaultSeverityRules, record.Body); ok {
+			level = severity
+		} else if severity, ok := c.ContainerSeverities[record.ContainerName]; ok {
+			level = severity
+		} else {
+			level = c.DefaultSeverity
+		}
+		if level == "" {
+			return "", log.SeverityUndefined
+		}
+	}
+
+	severity := mapSeverityToOTel(level)
+	if c.SeverityFloor != "" && severity != log.SeverityUndefined {
+		if floor := mapSeverityToOTel(c.SeverityFloor); severity < floor {
+			return c.SeverityFloor, floor
+		}
+	}
+	return level, severity
+}
+
+// admitSeverity reports whether the record, given the level extracted from
+// its line, passes MinSeverity
+func (c TransformConfig) admitSeverity(record *LogRecord, level string) bool {
+	if c.MinSeverity == "" || record.Heartbeat {
+		return true
+	}
+	_, severity := c.resolveSeverity(record, level)
+	if severity == log.SeverityUndefined {
+		return !c.DropUnleveled
+	}
+	return severity >= mapSeverityToOTel(c.MinSeverity)
+}
+
+// severityOf returns the severity EmitLogWithConfig gives to the record, or
+// log.SeverityUndefined when it has none
+func (c TransformConfig) severityOf(record *LogRecord) log.Severity {
+	_, _, severity := c.messageAndSeverityOf(record)
+	return severity
+}
+
+// messageAndSeverityOf returns the message extracted from the line of the
+// record with the severity text and number EmitLogWithConfig gives to it
+func (c TransformConfig) messageAndSeverityOf(record *LogRecord) (string, string, log.Severity) {
+	body := record.Body
+	maxDepth := c.MaxJSONDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxJSONDepth
+	}
+	keys := c.fieldKeys()
+	message, severity, _, isStructured, depthExceeded := parseStructuredLogWithDepth(body, maxDepth, keys)
+	if !isStructured && !depthExceeded && c.EmbeddedJSON != "" {
+		message, severity, _, isStructured = parseEmbeddedJSON(body, maxDepth, c.EmbeddedJSON, keys)
+	}
+	if !isStructured && !depthExceeded && c.Klog {
+		message, severity, _, _ = parseKlog(body)
+	}
+
+	text, otelSeverity := c.resolveSeverity(record, severity)
+	return message, text, otelSeverity
+}
+
+// DefaultMaxJSONDepth is the nesting depth used when TransformConfig.MaxJSONDepth is unset
+const DefaultMaxJSONDepth = 32
+
+// selectKey reports whether key matches one of the include patterns, or
+// there are none, and none of the exclude patterns
+func selectKey(key string, include, exclude []string) bool {
+	matches := func(patterns []string) bool {
+		return slices.ContainsFunc(patterns, func(pattern string) bool {
+			return globMatch(pattern, key)
+		})
+	}
+	return (len(include) == 0 || matches(include)) && !matches(exclude)
+}
+
+// globMatch reports whether s matches pattern, where "*" matches any text
+// and "?" any single byte
+func globMatch(pattern, s string) bool {
+	// Backtrack to the last star when a literal fails to match
+	p, i := 0, 0
+	star, next := -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == s[i]):
+			p++
+			i++
+		case p < len(pattern) && pattern[p] == '*':
+			star, next = p, i
+			p++
+		case star >= 0:
+			next++
+			p, i = star+1, next
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// composeServiceName joins the name, or instance, and component labels of
+// the recommended Kubernetes labels, falling back to deriveServiceName
+func composeServiceName(labels map[string]string, podName string) string {
+	name := labels["app.kubernetes.io/name"]
+	if name == "" {
+		name = labels["app.kubernetes.io/instance"]
+	}
+	if name == "" {
+		return deriveServiceName(labels, podName)
+	}
+	if component := labels["app.kubernetes.io/component"]; component != "" {
+		return name + "/" + component
+	}
+	return name
 }
 
 // deriveServiceName extracts service name from pod labels or falls back to pod name
@@ -51,21 +577,133 @@ func deriveServiceName(labels map[string]string, podName string) string {
 	return podName
 }
 
+// statefulSetOrdinal returns the ordinal of a pod of the StatefulSet, named
+// "<statefulset>-<ordinal>"
+func statefulSetOrdinal(podName, statefulSet string) (int64, bool) {
+	suffix, ok := strings.CutPrefix(podName, statefulSet+"-")
+	if !ok {
+		return 0, false
+	}
+	ordinal, err := strconv.ParseUint(suffix, 10, 31)
+	if err != nil {
+		return 0, false
+	}
+	return int64(ordinal), true
+}
+
+// splitImage splits an image reference, e.g. "registry:5000/app:1.2@sha256:…",
+// into its name "registry:5000/app" and its tag "1.2", if any
+func splitImage(image string) (name, tag string) {
+	name, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		return name[:i], name[i+1:]
+	}
+	return name, ""
+}
+
+// queryAttributes returns the query parameters of the URLs of the named
+// fields as query.<name> attributes, decoded and in name order. Repeated
+// parameters are a slice of their values.
+func queryAttributes(structuredAttrs map[string]interface{}, fields []string) []log.KeyValue {
+	var attrs []log.KeyValue
+	for _, field := range fields {
+		raw, ok := structuredAttrs[field].(string)
+		if !ok {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		query, err := url.ParseQuery(u.RawQuery)
+		if err != nil && len(query) == 0 {
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(query)) {
+			values := query[name]
+			if len(values) == 1 {
+				attrs = append(attrs, log.String("query."+name, values[0]))
+				continue
+			}
+			slice := make([]log.Value, len(values))
+			for i, value := range values {
+				slice[i] = log.StringValue(value)
+			}
+			attrs = append(attrs, log.Slice("query."+name, slice...))
+		}
+	}
+	return attrs
+}
+
+// lastLoggerSegment returns the last segment of a dotted or slashed logger
+// name, e.g. "boho-api" for "statler.server.boho-api"
+func lastLoggerSegment(name string) string {
+	if i := strings.LastIndexAny(name, "./"); i != -1 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// utf8BOM is the byte order mark written at the start of lines by some
+// logging libraries
+const utf8BOM = "\uFEFF"
+
+// unmarshalJSONObject parses a JSON object, or an array holding a single
+// object as written by some loggers. Other arrays are not structured logs.
+func unmarshalJSONObject(body string) (map[string]interface{}, bool) {
+	if strings.HasPrefix(body, "[") {
+		var array []map[string]interface{}
+		if err := json.Unmarshal([]byte(body), &array); err != nil || len(array) != 1 || array[0] == nil {
+			return nil, false
+		}
+		return array[0], true
+	}
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil || parsed == nil {
+		return nil, false
+	}
+	return parsed, true
+}
+
 // parseStructuredLog attempts to parse the log body as JSON and extract structured fields
 func parseStructuredLog(body string) (message string, severity string, structuredAttrs map[string]interface{}, isStructured bool) {
-	body = strings.TrimSpace(body)
-	if !strings.HasPrefix(body, "{") {
-		return body, "", nil, false
+	message, severity, structuredAttrs, isStructured, _ = parseStructuredLogWithDepth(body, DefaultMaxJSONDepth, defaultFieldKeys)
+	return message, strings.ToUpper(severity), structuredAttrs, isStructured
+}
+
+// parseStructuredLogWithDepth is parseStructuredLog rejecting payloads nested
+// deeper than maxDepth before decoding them, and taking the message and the
+// level from the given fields
+func parseStructuredLogWithDepth(body string, maxDepth int, keys fieldKeys) (message string, severity string, structuredAttrs map[string]interface{}, isStructured bool, depthExceeded bool) {
+	// Some logging libraries start lines with a byte order mark
+	body = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(body), utf8BOM))
+	if !strings.HasPrefix(body, "{") && !strings.HasPrefix(body, "[") {
+		// Try logfmt, e.g. go-kit/log's `level=info msg="server started"`
+		if parsed, ok := parseLogfmt(body); ok {
+			message, severity = extractMessageAndSeverity(parsed, body, keys)
+			return message, severity, parsed, true, false
+		}
+		return body, "", nil, false, false
 	}
 
-	var parsed map[string]interface{}
-	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
-		return body, "", nil, false
+	if exceedsJSONDepth(body, maxDepth) {
+		return body, "", nil, false, true
 	}
 
-	// Extract common logging fields
-	// Try various common message field names
-	for _, key := range []string{"msg", "message", "Message"} {
+	parsed, ok := unmarshalJSONObject(body)
+	if !ok {
+		return body, "", nil, false, false
+	}
+
+	message, severity = extractMessageAndSeverity(parsed, body, keys)
+	return message, severity, parsed, true, false
+}
+
+// extractMessageAndSeverity removes the message and the level from the
+// parsed fields. The message defaults to the whole body.
+func extractMessageAndSeverity(parsed map[string]interface{}, body string, keys fieldKeys) (message string, severity string) {
+	// Try the message fields in order
+	for _, key := range keys.message {
 		if val, ok := parsed[key]; ok {
 			if strVal, ok := val.(string); ok {
 				message = strVal
@@ -75,31 +713,420 @@ func parseStructuredLog(body string) (message string, severity string, structure
 		}
 	}
 
-	// Extract severity/level
-	for _, key := range []string{"level", "severity", "levelname"} {
-		if val, ok := parsed[key]; ok {
-			if strVal, ok := val.(string); ok {
-				severity = strings.ToUpper(strVal)
-				delete(parsed, key)
-				break
+	// Extract severity/level, keeping the casing of the app for the severity
+	// text. Unknown numeric levels are kept as attributes.
+	for _, key := range keys.severity {
+		switch val := parsed[key].(type) {
+		case string:
+			severity = val
+		case float64:
+			if severity = mapNumericSeverity(val, keys.numericSeverity); severity == "" {
+				continue
 			}
+		default:
+			continue
 		}
+		delete(parsed, key)
+		break
 	}
 
-	// If we couldn't extract a message, use the whole JSON as the body
+	// If we couldn't extract a message, use the whole line as the body
 	if message == "" {
 		message = body
 	}
 
-	return message, severity, parsed, true
+	return message, severity
+}
+
+// exceedsJSONDepth reports whether the objects and arrays of the JSON text
+// are nested deeper than maxDepth. It scans the text without decoding it.
+func exceedsJSONDepth(body string, maxDepth int) bool {
+	depth := 0
+	inString, escaped := false, false
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > maxDepth {
+				return true
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+	return false
+}
+
+// parseTaggedLog extracts leading bracket tags such as Rails' tagged logging
+// "[request-id] [tenant] message" into attributes named by position
+func parseTaggedLog(body string, names []string) (message string, tags map[string]interface{}, isTagged bool) {
+	rest := strings.TrimSpace(body)
+	var values []string
+	for strings.HasPrefix(rest, "[") {
+		end := strings.IndexByte(rest, ']')
+		if end == -1 {
+			break
+		}
+		values = append(values, rest[1:end])
+		rest = strings.TrimLeft(rest[end+1:], " ")
+	}
+	if len(values) == 0 {
+		return body, nil, false
+	}
+
+	tags = make(map[string]interface{}, len(values))
+	for i, value := range values {
+		key := "tag." + strconv.Itoa(i)
+		if i < len(names) && names[i] != "" {
+			key = names[i]
+		}
+		tags[key] = value
+	}
+
+	return rest, tags, true
+}
+
+// parseEmbeddedJSON parses the JSON object ending a text line, e.g.
+// `2025-01-01 INFO handler: {"user":"alice"}`. The message is the text prefix
+// or, in EmbeddedJSONMessage mode, the message of the object if it has one.
+func parseEmbeddedJSON(body string, maxDepth int, mode string, keys fieldKeys) (message string, severity string, structuredAttrs map[string]interface{}, isEmbedded bool) {
+	body = strings.TrimSpace(body)
+	if !strings.HasSuffix(body, "}") {
+		return body, "", nil, false
+	}
+
+	// The object starts at the first brace from which the rest parses
+	for i := 0; ; {
+		next := strings.IndexByte(body[i+1:], '{')
+		if next == -1 {
+			break
+		}
+		i += next + 1
+
+		object := body[i:]
+		jsonMessage, jsonSeverity, attrs, ok, _ := parseStructuredLogWithDepth(object, maxDepth, keys)
+		if ok {
+			message = strings.TrimSpace(body[:i])
+			if mode == EmbeddedJSONMessage && jsonMessage != object {
+				message = jsonMessage
+			}
+			return message, jsonSeverity, attrs, true
+		}
+	}
+	return body, "", nil, false
+}
+
+// renderMessageField renders the first message field of the structured
+// attributes that is not a string and removes it from the attributes
+func renderMessageField(structuredAttrs map[string]interface{}, mode string, keys []string) (string, bool) {
+	for _, key := range keys {
+		value, ok := structuredAttrs[key]
+		if !ok || value == nil {
+			continue
+		}
+		delete(structuredAttrs, key)
+		if mode == RenderMessageTemplate {
+			if rendered, ok := renderTemplate(value); ok {
+				return rendered, true
+			}
+		}
+		return formatArg(value), true
+	}
+	return "", false
 }
 
-// convertToLogKeyValue converts a Go value to an OTel log.Value
+// renderTemplate fills the placeholders of a {"template":"...","args":[...]}
+// message, "{}" taking the next argument and "{N}" the Nth one
+func renderTemplate(value interface{}) (string, bool) {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	template, ok := object["template"].(string)
+	if !ok {
+		if template, ok = object["format"].(string); !ok {
+			return "", false
+		}
+	}
+	args, _ := object["args"].([]interface{})
+
+	var b strings.Builder
+	next := 0
+	for {
+		start := strings.IndexByte(template, '{')
+		if start == -1 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end == -1 {
+			break
+		}
+		end += start
+
+		index := next
+		if placeholder := template[start+1 : end]; placeholder != "" {
+			n, err := strconv.Atoi(placeholder)
+			if err != nil {
+				// not a placeholder, e.g. a literal brace
+				b.WriteString(template[:start+1])
+				template = template[start+1:]
+				continue
+			}
+			index = n
+		} else {
+			next++
+		}
+
+		b.WriteString(template[:start])
+		if index >= 0 && index < len(args) {
+			b.WriteString(formatArg(args[index]))
+		} else {
+			b.WriteString(template[start : end+1])
+		}
+		template = template[end+1:]
+	}
+	b.WriteString(template)
+	return b.String(), true
+}
+
+// formatArg formats a template argument, strings as-is and others as JSON
+func formatArg(arg interface{}) string {
+	if s, ok := arg.(string); ok {
+		return s
+	}
+	jsonBytes, err := json.Marshal(arg)
+	if err != nil {
+		return fmt.Sprint(arg)
+	}
+	return string(jsonBytes)
+}
+
+// normalizeDuration replaces the first of the named fields holding a
+// duration with a unit, e.g. "15ms", "1.2s" or "1500000ns", by a duration.ms
+// float. Values without a unit or that do not parse are left as-is.
+func normalizeDuration(structuredAttrs map[string]interface{}, names []string) {
+	for _, name := range names {
+		value, ok := structuredAttrs[name].(string)
+		if !ok {
+			continue
+		}
+		duration, err := time.ParseDuration(strings.ReplaceAll(value, " ", ""))
+		if err != nil {
+			continue
+		}
+		delete(structuredAttrs, name)
+		structuredAttrs["duration.ms"] = float64(duration) / float64(time.Millisecond)
+		return
+	}
+}
+
+// coercedDuration matches the Go durations coerced by coerceTypes, with a
+// unit for each number so that plain numbers are left alone
+var coercedDuration = regexp.MustCompile(`^-?(\d+(\.\d+)?(ns|us|µs|ms|s|m|h))+$`)
+
+// coerceTypes adds a _ns attribute next to the string fields, nested ones
+// included, holding a Go duration, and a _epoch_ms attribute next to those
+// holding an RFC 3339 timestamp. The fields are kept, and existing fields
+// are not overwritten.
+func coerceTypes(structuredAttrs map[string]interface{}) {
+	for key, value := range structuredAttrs {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			coerceTypes(value)
+		case string:
+			if coercedDuration.MatchString(value) {
+				if duration, err := time.ParseDuration(value); err == nil {
+					setIfAbsent(structuredAttrs, key+"_ns", int64(duration))
+				}
+				continue
+			}
+			// The shortest RFC 3339 timestamp is "2006-01-02T15:04:05Z"
+			if len(value) < len("2006-01-02T15:04:05Z") || value[10] != 'T' && value[10] != 't' {
+				continue
+			}
+			if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+				setIfAbsent(structuredAttrs, key+"_epoch_ms", t.UnixMilli())
+			}
+		}
+	}
+}
+
+// setIfAbsent sets the field unless the log has it already
+func setIfAbsent(structuredAttrs map[string]interface{}, key string, value interface{}) {
+	if _, ok := structuredAttrs[key]; !ok {
+		structuredAttrs[key] = value
+	}
+}
+
+// liftFieldObjects moves the fields of the named sub-objects to the top
+// level of the structured attributes. Top-level fields win on conflict.
+func liftFieldObjects(structuredAttrs map[string]interface{}, names []string) {
+	for _, name := range names {
+		fields, ok := structuredAttrs[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		delete(structuredAttrs, name)
+		for key, value := range fields {
+			if _, exists := structuredAttrs[key]; !exists {
+				structuredAttrs[key] = value
+			}
+		}
+	}
+}
+
+// traceIDField and spanIDField name a trace correlation field and how its
+// value is decoded
+type traceIDField struct {
+	key   string
+	parse func(string) (trace.TraceID, bool)
+}
+
+type spanIDField struct {
+	key   string
+	parse func(string) (trace.SpanID, bool)
+}
+
+// Trace correlation fields of OpenTelemetry-aware loggers, hex encoded, and
+// of the Datadog tracer, decimal encoded
+var (
+	traceIDFields = []traceIDField{
+		{"trace_id", traceIDFromHex},
+		{"traceID", traceIDFromHex},
+		{"traceId", traceIDFromHex},
+		{"dd.trace_id", traceIDFromDecimal},
+	}
+	spanIDFields = []spanIDField{
+		{"span_id", spanIDFromHex},
+		{"spanID", spanIDFromHex},
+		{"spanId", spanIDFromHex},
+		{"dd.span_id", spanIDFromDecimal},
+	}
+)
+
+// traceContext moves the trace correlation fields of the structured
+// attributes to a span context. Malformed ids are left as attributes, and so
+// is a span id without a valid trace id.
+func traceContext(structuredAttrs map[string]interface{}) (trace.SpanContext, bool) {
+	var config trace.SpanContextConfig
+	for _, field := range traceIDFields {
+		if value, ok := structuredAttrs[field.key].(string); ok {
+			if id, ok := field.parse(value); ok {
+				config.TraceID = id
+				delete(structuredAttrs, field.key)
+				break
+			}
+		}
+	}
+	if !config.TraceID.IsValid() {
+		return trace.SpanContext{}, false
+	}
+	for _, field := range spanIDFields {
+		if value, ok := structuredAttrs[field.key].(string); ok {
+			if id, ok := field.parse(value); ok {
+				config.SpanID = id
+				delete(structuredAttrs, field.key)
+				break
+			}
+		}
+	}
+	return trace.NewSpanContext(config), true
+}
+
+// traceIDFromHex decodes a 128-bit trace id, or a 64-bit one padded with
+// zeros as the 128-bit form of Jaeger and Zipkin ids
+func traceIDFromHex(s string) (trace.TraceID, bool) {
+	s = strings.ToLower(s)
+	if len(s) == 16 {
+		s = strings.Repeat("0", 16) + s
+	}
+	id, err := trace.TraceIDFromHex(s)
+	return id, err == nil
+}
+
+// spanIDFromHex decodes a 64-bit span id
+func spanIDFromHex(s string) (trace.SpanID, bool) {
+	id, err := trace.SpanIDFromHex(strings.ToLower(s))
+	return id, err == nil
+}
+
+// traceIDFromDecimal decodes a Datadog trace id, the lower 64 bits of the
+// trace id in decimal
+func traceIDFromDecimal(s string) (trace.TraceID, bool) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n == 0 {
+		return trace.TraceID{}, false
+	}
+	var id trace.TraceID
+	binary.BigEndian.PutUint64(id[8:], n)
+	return id, true
+}
+
+// spanIDFromDecimal decodes a Datadog span id
+func spanIDFromDecimal(s string) (trace.SpanID, bool) {
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil || n == 0 {
+		return trace.SpanID{}, false
+	}
+	var id trace.SpanID
+	binary.BigEndian.PutUint64(id[:], n)
+	return id, true
+}
+
+// flattenAttribute appends the value as an attribute, or the leaves of an
+// object or array down to depth levels as attributes with dotted keys. Null
+// values are left out unless keepNulls is set.
+func flattenAttribute(attrs []log.KeyValue, key string, value interface{}, depth int, keepNulls bool) []log.KeyValue {
+	if value == nil && !keepNulls {
+		return attrs
+	}
+	if depth > 0 {
+		switch val := value.(type) {
+		case map[string]interface{}:
+			if len(val) > 0 {
+				for _, name := range slices.Sorted(maps.Keys(val)) {
+					attrs = flattenAttribute(attrs, key+"."+name, val[name], depth-1, keepNulls)
+				}
+				return attrs
+			}
+		case []interface{}:
+			if len(val) > 0 {
+				for i, item := range val {
+					attrs = flattenAttribute(attrs, key+"."+strconv.Itoa(i), item, depth-1, keepNulls)
+				}
+				return attrs
+			}
+		}
+	}
+	return append(attrs, log.KeyValue{Key: key, Value: convertToLogKeyValue(value)})
+}
+
+// convertToLogKeyValue converts a Go value to an OTel log.Value. JSON null
+// becomes the empty value, and whole numbers, decoded as float64, integers.
 func convertToLogKeyValue(v interface{}) log.Value {
 	switch val := v.(type) {
+	case nil:
+		return log.Value{}
 	case string:
 		return log.StringValue(val)
 	case float64:
+		if val == math.Trunc(val) && val >= math.MinInt64 && val < math.MaxInt64 {
+			return log.Int64Value(int64(val))
+		}
 		return log.Float64Value(val)
 	case int:
 		return log.Int64Value(int64(val))
@@ -125,28 +1152,210 @@ func convertToLogKeyValue(v interface{}) log.Value {
 	}
 }
 
-// mapSeverityToOTel maps common log levels to OTel severity
+// recordID computes a stable identifier from the record origin, timestamp,
+// line index and body so that replayed lines get the same id
+func recordID(record *LogRecord) string {
+	h := sha256.New()
+	for _, part := range []string{
+		record.Namespace,
+		record.PodName,
+		record.ContainerName,
+		record.Timestamp.UTC().Format(time.RFC3339Nano),
+		strconv.Itoa(record.LineIndex),
+		record.Body,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)[:16])
+}
+
+// bodyHash fingerprints the body with the algorithm, FNV-1a 64 or SHA-256
+func bodyHash(body, algorithm string) string {
+	if algorithm == BodyHashFNV {
+		h := fnv.New64a()
+		h.Write([]byte(body))
+		return hex.EncodeToString(h.Sum(nil))
+	}
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+// mapSeverityToOTel maps common log levels to OTel severity. The numbered
+// OTel severity text variants (e.g. INFO2, WARN3) map to the exact enum value.
 func mapSeverityToOTel(severity string) log.Severity {
-	switch strings.ToUpper(severity) {
+	severity = strings.ToUpper(severity)
+
+	// Split a trailing variant number (1-4) from the severity text
+	variant := 0
+	if n := len(severity); n > 1 && severity[n-1] >= '1' && severity[n-1] <= '4' {
+		variant = int(severity[n-1] - '1')
+		severity = severity[:n-1]
+	}
+
+	var base log.Severity
+	switch severity {
+	case "TRACE":
+		base = log.SeverityTrace1
 	case "DEBUG":
-		return log.SeverityDebug
+		base = log.SeverityDebug1
 	case "INFO":
-		return log.SeverityInfo
+		base = log.SeverityInfo1
+	case "NOTICE":
+		// syslog notice sits between info and warn
+		if variant != 0 {
+			return log.SeverityUndefined
+		}
+		return log.SeverityInfo2
 	case "WARN", "WARNING":
-		return log.SeverityWarn
+		base = log.SeverityWarn1
 	case "ERROR":
-		return log.SeverityError
+		base = log.SeverityError1
 	case "FATAL", "CRITICAL":
-		return log.SeverityFatal
+		base = log.SeverityFatal1
 	default:
 		return log.SeverityUndefined
 	}
+
+	return base + log.Severity(variant)
+}
+
+// mapNumericSeverity returns the severity text of a numeric level on the
+// given scale, or "" for an unknown level
+func mapNumericSeverity(level float64, scale string) string {
+	if level != float64(int(level)) {
+		return ""
+	}
+	if scale == NumericSeveritySyslog {
+		switch int(level) {
+		case 0:
+			return "FATAL3" // emergency
+		case 1:
+			return "FATAL2" // alert
+		case 2:
+			return "FATAL" // critical
+		case 3:
+			return "ERROR"
+		case 4:
+			return "WARN"
+		case 5:
+			return "NOTICE"
+		case 6:
+			return "INFO"
+		case 7:
+			return "DEBUG"
+		}
+		return ""
+	}
+	switch int(level) {
+	case 10:
+		return "TRACE"
+	case 20:
+		return "DEBUG"
+	case 30:
+		return "INFO"
+	case 40:
+		return "WARN"
+	case 50:
+		return "ERROR"
+	case 60:
+		return "FATAL"
+	}
+	return ""
+}
+
+// SeverityOf returns the severity of a structured log line, or
+// log.SeverityUndefined when the line carries no recognized level
+func SeverityOf(body string) log.Severity {
+	_, severity, _, _ := parseStructuredLog(body)
+	return mapSeverityToOTel(severity)
 }
 
 // EmitLog emits a log record to the OTel logger with proper attributes
 func EmitLog(ctx context.Context, logger log.Logger, record *LogRecord) {
+	EmitLogWithConfig(ctx, logger, record, nil)
+}
+
+// EmitLogWithConfig emits a log record to the OTel logger, shaping it
+// according to config. A nil config behaves like EmitLog.
+func EmitLogWithConfig(ctx context.Context, logger log.Logger, record *LogRecord, config *TransformConfig) {
+	if config == nil {
+		config = &TransformConfig{}
+	}
+
+	maxDepth := config.MaxJSONDepth
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxJSONDepth
+	}
+
 	// Try to parse structured logs
-	message, severity, structuredAttrs, isStructured := parseStructuredLog(record.Body)
+	keys := config.fieldKeys()
+	message, severity, structuredAttrs, isStructured, depthExceeded := parseStructuredLogWithDepth(record.Body, maxDepth, keys)
+	if !isStructured && !depthExceeded && config.EmbeddedJSON != "" {
+		message, severity, structuredAttrs, isStructured = parseEmbeddedJSON(record.Body, maxDepth, config.EmbeddedJSON, keys)
+	}
+	if !isStructured && !depthExceeded && config.Klog {
+		message, severity, structuredAttrs, isStructured = parseKlog(record.Body)
+	}
+	if !isStructured && !depthExceeded && config.TaggedLogs {
+		message, structuredAttrs, isStructured = parseTaggedLog(record.Body, config.TagNames)
+	}
+
+	// Skip the records below the minimum severity before shaping them
+	if !config.admitSeverity(record, severity) {
+		return
+	}
+
+	// Without a string message, the body is the whole JSON unless rendered
+	if isStructured && config.RenderMessage != "" && message == strings.TrimSpace(record.Body) {
+		if rendered, ok := renderMessageField(structuredAttrs, config.RenderMessage, keys.message); ok {
+			message = rendered
+		}
+	}
+
+	if isStructured && len(config.FieldObjects) > 0 {
+		liftFieldObjects(structuredAttrs, config.FieldObjects)
+	}
+
+	// Prefer the time the application logged the event, before the field
+	// may be dropped as noise
+	var eventTime time.Time
+	if isStructured && len(config.TimestampFields) > 0 {
+		eventTime, _ = extractTimestamp(structuredAttrs, config.TimestampFields, config.KeepTimestampField)
+	}
+
+	if isStructured {
+		for _, field := range config.DropFields {
+			delete(structuredAttrs, field)
+		}
+	}
+
+	// Hide credentials and personal data before anything is derived from them
+	redactor := config.redactor()
+	if redactor != nil {
+		if isStructured {
+			redactor.redactFields(structuredAttrs)
+		}
+		if message == strings.TrimSpace(record.Body) {
+			message = redactor.redactLine(message)
+		} else {
+			message = redactor.redactText(message)
+		}
+	}
+
+	// Correlate the record with its trace rather than leaving loose attributes
+	if isStructured {
+		if spanContext, ok := traceContext(structuredAttrs); ok {
+			ctx = trace.ContextWithSpanContext(ctx, spanContext)
+		}
+	}
+
+	if isStructured && len(config.DurationFields) > 0 {
+		normalizeDuration(structuredAttrs, config.DurationFields)
+	}
+	if isStructured && config.CoerceTypes {
+		coerceTypes(structuredAttrs)
+	}
 
 	// Build log record with K8s semantic conventions
 	var attrs []log.KeyValue
@@ -154,7 +1363,40 @@ func EmitLog(ctx context.Context, logger log.Logger, record *LogRecord) {
 	// Service and host attributes (resource-level semantic conventions)
 	// https://opentelemetry.io/docs/specs/semconv/resource/
 	serviceName := deriveServiceName(record.Labels, record.PodName)
+	if config.ComposeServiceName {
+		serviceName = composeServiceName(record.Labels, record.PodName)
+	}
+
+	// Move the logger name to its attribute, possibly naming the service
+	var loggerName string
+	if config.LoggerField != "" && isStructured {
+		if name, ok := structuredAttrs[config.LoggerField].(string); ok && name != "" {
+			delete(structuredAttrs, config.LoggerField)
+			loggerName = name
+			if config.LoggerServiceName {
+				if segment := lastLoggerSegment(name); segment != "" {
+					serviceName = segment
+				}
+			}
+		}
+	}
+
 	attrs = append(attrs, log.String("service.name", serviceName))
+	if config.ComposeServiceName {
+		if record.Namespace != "" {
+			attrs = append(attrs, log.String("service.namespace", record.Namespace))
+		}
+		if record.PodName != "" {
+			attrs = append(attrs, log.String("service.instance.id", record.PodName))
+		}
+	}
+	if loggerName != "" {
+		loggerAttribute := config.LoggerAttribute
+		if loggerAttribute == "" {
+			loggerAttribute = DefaultLoggerAttribute
+		}
+		attrs = append(attrs, log.String(loggerAttribute, loggerName))
+	}
 
 	if record.NodeName != "" {
 		attrs = append(attrs, log.String("host.name", record.NodeName))
@@ -162,51 +1404,234 @@ func EmitLog(ctx context.Context, logger log.Logger, record *LogRecord) {
 
 	// Core K8s attributes following semantic conventions
 	// https://opentelemetry.io/docs/specs/semconv/resource/k8s/
+	if config.ClusterName != "" {
+		attrs = append(attrs, log.String("k8s.cluster.name", config.ClusterName))
+	}
 	if record.Namespace != "" {
 		attrs = append(attrs, log.String("k8s.namespace.name", record.Namespace))
 	}
 	if record.PodName != "" {
 		attrs = append(attrs, log.String("k8s.pod.name", record.PodName))
 	}
+	if record.PodUID != "" {
+		attrs = append(attrs, log.String("k8s.pod.uid", record.PodUID))
+	}
 	if record.ContainerName != "" {
 		attrs = append(attrs, log.String("k8s.container.name", record.ContainerName))
 	}
+	if sep := config.ContainerFQNSeparator; sep != "" {
+		attrs = append(attrs, log.String("k8s.container.fqn", record.Namespace+sep+record.PodName+sep+record.ContainerName))
+	}
 	if record.NodeName != "" {
 		attrs = append(attrs, log.String("k8s.node.name", record.NodeName))
 	}
 
-	// Add pod labels as attributes with prefix
+	// Scheduling attributes for capacity and eviction debugging
+	if record.QOSClass != "" {
+		attrs = append(attrs, log.String("k8s.pod.qos_class", record.QOSClass))
+	}
+	if record.Priority != nil {
+		attrs = append(attrs, log.Int64("k8s.pod.priority", int64(*record.Priority)))
+	}
+
+	// Container status attributes for triaging rollouts
+	if config.ContainerStatus {
+		if record.Image != "" {
+			name, tag := splitImage(record.Image)
+			attrs = append(attrs, log.String("container.image.name", name))
+			if tag != "" {
+				attrs = append(attrs, log.Slice("container.image.tags", log.StringValue(tag)))
+			}
+		}
+		if record.ImageID != "" {
+			attrs = append(attrs, log.String("container.image.id", record.ImageID))
+		}
+		if record.RestartCount != nil {
+			attrs = append(attrs, log.Int64("k8s.container.restart_count", int64(*record.RestartCount)))
+		}
+	}
+
+	// Workload attributes from the owner chain
+	for _, owner := range record.Owners {
+		switch owner.Kind {
+		case "Job":
+			attrs = append(attrs, log.String("k8s.job.name", owner.Name))
+		case "CronJob":
+			attrs = append(attrs, log.String("k8s.cronjob.name", owner.Name))
+		case "ReplicaSet":
+			attrs = append(attrs, log.String("k8s.replicaset.name", owner.Name))
+		case "Deployment":
+			attrs = append(attrs, log.String("k8s.deployment.name", owner.Name))
+		case "StatefulSet":
+			if !config.StatefulSetOrdinal {
+				continue
+			}
+			if ordinal, ok := statefulSetOrdinal(record.PodName, owner.Name); ok {
+				attrs = append(attrs, log.Int64("k8s.statefulset.pod_ordinal", ordinal))
+			}
+		}
+	}
+
+	labelPrefix, annotationPrefix := DefaultLabelPrefix, DefaultAnnotationPrefix
+	if config.LabelPrefix != nil {
+		labelPrefix = *config.LabelPrefix
+	}
+	if config.AnnotationPrefix != nil {
+		annotationPrefix = *config.AnnotationPrefix
+	}
+
+	// Add pod labels as attributes with prefix, or under their mapped name
 	for key, value := range record.Labels {
-		attrs = append(attrs, log.String("k8s.pod.label."+key, value))
+		if name, ok := config.LabelAttributes[key]; ok && name != "" {
+			attrs = append(attrs, log.String(name, value))
+			continue
+		}
+		if !config.NoLabels && selectKey(key, config.IncludeLabels, config.ExcludeLabels) {
+			attrs = append(attrs, log.String(labelPrefix+key, value))
+		}
 	}
 
-	// Add pod annotations as attributes with prefix
+	// Add pod annotations as attributes with prefix, or under their mapped name
 	for key, value := range record.Annotations {
-		attrs = append(attrs, log.String("k8s.pod.annotation."+key, value))
+		if name, ok := config.AnnotationAttributes[key]; ok && name != "" {
+			attrs = append(attrs, log.String(name, value))
+			continue
+		}
+		if !config.NoAnnotations && selectKey(key, config.IncludeAnnotations, config.ExcludeAnnotations) {
+			attrs = append(attrs, log.String(annotationPrefix+key, value))
+		}
 	}
 
 	// Add structured log fields as attributes
 	if isStructured {
 		for key, value := range structuredAttrs {
-			attrs = append(attrs, log.KeyValue{
-				Key:   key,
-				Value: convertToLogKeyValue(value),
-			})
+			attrs = flattenAttribute(attrs, key, value, config.FlattenDepth, config.KeepNulls)
+		}
+		if len(config.URLFields) > 0 {
+			attrs = append(attrs, queryAttributes(structuredAttrs, config.URLFields)...)
 		}
 	}
 
-	// Create and emit the log record using the builder pattern
+	// Flag payloads that were too deeply nested to be parsed
+	if depthExceeded {
+		attrs = append(attrs, log.Bool("stern.parse_depth_exceeded", true))
+	}
+
+	// Mark heartbeats of silent containers
+	if record.Heartbeat {
+		attrs = append(attrs, log.Bool("stern.heartbeat", true))
+		if !record.LastSeen.IsZero() {
+			attrs = append(attrs, log.String("stern.heartbeat.last_seen", record.LastSeen.UTC().Format(time.RFC3339Nano)))
+		}
+	}
+
+	if record.LineNumber > 0 {
+		attrs = append(attrs, log.Int64("stern.line_no", record.LineNumber))
+	}
+
+	// Tell the crashed instance of a container apart from the current one
+	if record.Previous {
+		attrs = append(attrs, log.Bool("log.previous", true))
+	}
+	if record.RepeatCount > 0 {
+		attrs = append(attrs, log.Int64("log.repeat_count", record.RepeatCount))
+	}
+
+	if record.TailedContainers > 0 {
+		attrs = append(attrs, log.Int64("stern.session.tailed_containers", record.TailedContainers))
+	}
+
+	// Explain why the line was selected by the include filters
+	if len(record.Matches) > 0 {
+		matches := make([]log.Value, len(record.Matches))
+		for i, m := range record.Matches {
+			matches[i] = log.StringValue(m)
+		}
+		attrs = append(attrs, log.Slice("stern.matches", matches...))
+	}
+
+	if config.Identity != "" {
+		attrs = append(attrs, log.String("stern.identity", config.Identity))
+	}
+
+	if config.RecordID {
+		attrs = append(attrs, log.String("log.record.id", recordID(record)))
+	}
+
+	if config.BodyHash != "" {
+		attrs = append(attrs, log.String("log.body.hash", bodyHash(message, config.BodyHash)))
+	}
+
+	// Plain text lines are already the body
+	if config.KeepRaw && isStructured {
+		original := record.Body
+		if redactor != nil {
+			original = redactor.redactLine(original)
+		}
+		attrs = append(attrs, log.String("log.record.original", original))
+	}
+
+	// Add user-computed attributes once the built-in extraction is done
+	if config.Enrich != nil {
+		attrs = append(attrs, config.Enrich(record, structuredAttrs)...)
+	}
+
+	// Create and emit the log record using the builder pattern. The
+	// timestamps share the location of the record's, e.g. the one of
+	// --timezone, so that they render alike.
 	logRecord := log.Record{}
-	logRecord.SetTimestamp(record.Timestamp)
-	logRecord.SetObservedTimestamp(time.Now())
+	now := time.Now()
+	if !record.Timestamp.IsZero() {
+		now = now.In(record.Timestamp.Location())
+	}
+	if !eventTime.IsZero() && !record.Timestamp.IsZero() {
+		logRecord.SetTimestamp(eventTime.In(record.Timestamp.Location()))
+		logRecord.SetObservedTimestamp(record.Timestamp)
+	} else if !eventTime.IsZero() {
+		logRecord.SetTimestamp(eventTime)
+		logRecord.SetObservedTimestamp(now)
+	} else {
+		logRecord.SetTimestamp(record.Timestamp)
+		logRecord.SetObservedTimestamp(now)
+	}
 	logRecord.SetBody(log.StringValue(message))
 
-	// Set severity if extracted from structured log
-	if severity != "" {
-		logRecord.SetSeverity(mapSeverityToOTel(severity))
+	// Set severity if extracted from structured log, along with the level as
+	// written by the app, e.g. "warn" or "WARNING". Rules override it, and
+	// the default severities apply to the lines without a level.
+	if text, otelSeverity := config.resolveSeverity(record, severity); text != "" {
+		logRecord.SetSeverityText(text)
+		logRecord.SetSeverity(otelSeverity)
 	}
 
 	logRecord.AddAttributes(attrs...)
 
+	// Let the caller reshape the record last
+	if config.Hook != nil {
+		config.Hook(record, &logRecord)
+	}
+
 	logger.Emit(ctx, logRecord)
 }
+
+// RewriteAttributes replaces each attribute of the record with the one
+// returned by rewrite, or drops it when rewrite returns false, e.g. in a
+// HookFunc. The other fields of the record are kept.
+func RewriteAttributes(logRecord *log.Record, rewrite func(log.KeyValue) (log.KeyValue, bool)) {
+	attrs := make([]log.KeyValue, 0, logRecord.AttributesLen())
+	logRecord.WalkAttributes(func(kv log.KeyValue) bool {
+		if kv, ok := rewrite(kv); ok {
+			attrs = append(attrs, kv)
+		}
+		return true
+	})
+
+	rewritten := log.Record{}
+	rewritten.SetTimestamp(logRecord.Timestamp())
+	rewritten.SetObservedTimestamp(logRecord.ObservedTimestamp())
+	rewritten.SetSeverity(logRecord.Severity())
+	rewritten.SetSeverityText(logRecord.SeverityText())
+	rewritten.SetBody(logRecord.Body())
+	rewritten.AddAttributes(attrs...)
+	*logRecord = rewritten
+}
@@ -16,6 +16,10 @@ package otel
 
 import (
 	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -216,6 +220,86 @@ func TestDeriveServiceName(t *testing.T) {
 	}
 }
 
+func TestComposeServiceName(t *testing.T) {
+	tests := []struct {
+		name     string
+		labels   map[string]string
+		expected string
+	}{
+		{
+			name:     "name and component",
+			labels:   map[string]string{"app.kubernetes.io/name": "postgresql", "app.kubernetes.io/instance": "orders-db", "app.kubernetes.io/component": "primary"},
+			expected: "postgresql/primary",
+		},
+		{
+			name:     "name only",
+			labels:   map[string]string{"app.kubernetes.io/name": "postgresql", "app.kubernetes.io/instance": "orders-db"},
+			expected: "postgresql",
+		},
+		{
+			name:     "instance and component",
+			labels:   map[string]string{"app.kubernetes.io/instance": "orders-db", "app.kubernetes.io/component": "replica"},
+			expected: "orders-db/replica",
+		},
+		{
+			name:     "component without a name",
+			labels:   map[string]string{"app": "checkout", "app.kubernetes.io/component": "worker"},
+			expected: "checkout",
+		},
+		{
+			name:     "pod name",
+			labels:   map[string]string{},
+			expected: "test-pod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := composeServiceName(tt.labels, "test-pod"); result != tt.expected {
+				t.Errorf("composeServiceName() = %q, expected %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEmitLogComposeServiceName(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	record := &LogRecord{
+		Timestamp: time.Now(),
+		Body:      "hello",
+		Namespace: "shop",
+		PodName:   "orders-db-0",
+		Labels:    map[string]string{"app.kubernetes.io/name": "postgresql", "app.kubernetes.io/component": "primary"},
+	}
+	EmitLogWithConfig(context.Background(), logger, record, &TransformConfig{ComposeServiceName: true})
+	EmitLog(context.Background(), logger, record)
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(mockExporter.records))
+	}
+	expected := []map[string]string{
+		{"service.name": "postgresql/primary", "service.namespace": "shop", "service.instance.id": "orders-db-0"},
+		{"service.name": "postgresql"},
+	}
+	for i, want := range expected {
+		attrs := make(map[string]string)
+		mockExporter.records[i].WalkAttributes(func(kv log.KeyValue) bool {
+			if strings.HasPrefix(kv.Key, "service.") {
+				attrs[kv.Key] = kv.Value.AsString()
+			}
+			return true
+		})
+		if !reflect.DeepEqual(attrs, want) {
+			t.Errorf("%d: service attributes = %v, expected %v", i, attrs, want)
+		}
+	}
+}
+
 func TestParseStructuredLog(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -295,6 +379,42 @@ func TestParseStructuredLog(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:               "JSON with a byte order mark",
+			body:               "\uFEFF" + `{"level":"info","msg":"Server started","port":8080}`,
+			expectedMessage:    "Server started",
+			expectedSeverity:   "INFO",
+			expectedStructured: true,
+			checkAttrs: func(t *testing.T, attrs map[string]interface{}) {
+				if port, ok := attrs["port"].(float64); !ok || port != 8080 {
+					t.Errorf("expected port=8080, got %v", attrs["port"])
+				}
+			},
+		},
+		{
+			name:               "JSON array holding an object",
+			body:               ` [{"level":"error","msg":"Request failed","status":500}]`,
+			expectedMessage:    "Request failed",
+			expectedSeverity:   "ERROR",
+			expectedStructured: true,
+			checkAttrs: func(t *testing.T, attrs map[string]interface{}) {
+				if status, ok := attrs["status"].(float64); !ok || status != 500 {
+					t.Errorf("expected status=500, got %v", attrs["status"])
+				}
+			},
+		},
+		{
+			name:               "JSON array of values",
+			body:               `[1,2,3]`,
+			expectedMessage:    `[1,2,3]`,
+			expectedStructured: false,
+		},
+		{
+			name:               "plain text with a byte order mark",
+			body:               "\uFEFFServer started",
+			expectedMessage:    "Server started",
+			expectedStructured: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -335,6 +455,17 @@ func TestMapSeverityToOTel(t *testing.T) {
 		{"CRITICAL", log.SeverityFatal},
 		{"unknown", log.SeverityUndefined},
 		{"", log.SeverityUndefined},
+		{"TRACE", log.SeverityTrace},
+		{"trace2", log.SeverityTrace2},
+		{"DEBUG4", log.SeverityDebug4},
+		{"INFO2", log.SeverityInfo2},
+		{"info3", log.SeverityInfo3},
+		{"NOTICE", log.SeverityInfo2},
+		{"WARN3", log.SeverityWarn3},
+		{"ERROR4", log.SeverityError4},
+		{"FATAL2", log.SeverityFatal2},
+		{"INFO5", log.SeverityUndefined},
+		{"1", log.SeverityUndefined},
 	}
 
 	for _, tt := range tests {
@@ -347,6 +478,84 @@ func TestMapSeverityToOTel(t *testing.T) {
 	}
 }
 
+func TestMapNumericSeverity(t *testing.T) {
+	tests := []struct {
+		level    float64
+		scale    string
+		expected log.Severity
+	}{
+		{10, NumericSeverityBunyan, log.SeverityTrace},
+		{20, NumericSeverityBunyan, log.SeverityDebug},
+		{30, NumericSeverityBunyan, log.SeverityInfo},
+		{40, NumericSeverityBunyan, log.SeverityWarn},
+		{50, NumericSeverityBunyan, log.SeverityError},
+		{60, NumericSeverityBunyan, log.SeverityFatal},
+		{35, NumericSeverityBunyan, log.SeverityUndefined},
+		{3, NumericSeverityBunyan, log.SeverityUndefined},
+		{0, NumericSeveritySyslog, log.SeverityFatal3},
+		{2, NumericSeveritySyslog, log.SeverityFatal},
+		{3, NumericSeveritySyslog, log.SeverityError},
+		{4, NumericSeveritySyslog, log.SeverityWarn},
+		{5, NumericSeveritySyslog, log.SeverityInfo2},
+		{6, NumericSeveritySyslog, log.SeverityInfo},
+		{7, NumericSeveritySyslog, log.SeverityDebug},
+		{8, NumericSeveritySyslog, log.SeverityUndefined},
+		{30, NumericSeveritySyslog, log.SeverityUndefined},
+		{6.5, NumericSeveritySyslog, log.SeverityUndefined},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%s/%v", tt.scale, tt.level), func(t *testing.T) {
+			result := mapSeverityToOTel(mapNumericSeverity(tt.level, tt.scale))
+			if result != tt.expected {
+				t.Errorf("mapNumericSeverity(%v, %q) = %v, expected %v", tt.level, tt.scale, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEmitLogWithNumericSeverity(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	for _, body := range []string{
+		`{"level":50,"msg":"request failed","pid":42}`,
+		`{"level":35,"msg":"custom level"}`,
+	} {
+		EmitLog(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body})
+	}
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: `{"severity":3,"msg":"disk error"}`}, &TransformConfig{NumericSeverity: NumericSeveritySyslog})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(mockExporter.records))
+	}
+	expected := []log.Severity{log.SeverityError, log.SeverityUndefined, log.SeverityError}
+	for i, want := range expected {
+		if got := mockExporter.records[i].Severity(); got != want {
+			t.Errorf("%d: expected severity %v, got %v", i, want, got)
+		}
+	}
+
+	// An unknown level is kept as an attribute
+	var level log.Value
+	mockExporter.records[1].WalkAttributes(func(kv log.KeyValue) bool {
+		if kv.Key == "level" {
+			level = kv.Value
+		}
+		return true
+	})
+	if level.AsInt64() != 35 {
+		t.Errorf("expected the unknown level as an attribute, got %v", level)
+	}
+
+	if err := (TransformConfig{NumericSeverity: "log4j"}).validate(); err == nil {
+		t.Error("expected error for unsupported numeric severity scale")
+	}
+}
+
 func TestEmitStructuredLog(t *testing.T) {
 	mockExporter := &mockLogRecordExporter{}
 	processor := sdklog.NewSimpleProcessor(mockExporter)
@@ -388,8 +597,8 @@ func TestEmitStructuredLog(t *testing.T) {
 	exportedRecord.WalkAttributes(func(kv log.KeyValue) bool {
 		switch kv.Key {
 		case "user_id":
-			// JSON numbers are parsed as float64
-			if kv.Value.AsFloat64() == 12345.0 {
+			// Whole JSON numbers are sent as integers
+			if kv.Value.AsInt64() == 12345 {
 				foundUserId = true
 			}
 		case "action":
@@ -407,3 +616,2076 @@ func TestEmitStructuredLog(t *testing.T) {
 		t.Error("action attribute not found or incorrect")
 	}
 }
+
+func TestEmitStructuredLogNulls(t *testing.T) {
+	body := `{"msg":"x","user":null,"name":"","big":12345678901,"ratio":0.5,"tags":{"env":null}}`
+	for _, keepNulls := range []bool{false, true} {
+		mockExporter := &mockLogRecordExporter{}
+		provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(mockExporter)))
+
+		EmitLogWithConfig(context.Background(), provider.Logger("test"), &LogRecord{Timestamp: time.Now(), Body: body}, &TransformConfig{FlattenDepth: 1, KeepNulls: keepNulls})
+		provider.ForceFlush(context.Background())
+
+		attrs := make(map[string]log.Value)
+		mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+			attrs[kv.Key] = kv.Value
+			return true
+		})
+
+		for _, key := range []string{"user", "tags.env"} {
+			user, ok := attrs[key]
+			if ok != keepNulls {
+				t.Errorf("keep nulls %v: expected %s attribute %v, got %v", keepNulls, key, keepNulls, ok)
+			}
+			if ok && user.Kind() != log.KindEmpty {
+				t.Errorf("expected an empty %s value, got %v", key, user)
+			}
+		}
+		if name, ok := attrs["name"]; !ok || name.Kind() != log.KindString || name.AsString() != "" {
+			t.Errorf("expected the empty string name attribute, got %v", name)
+		}
+		if big := attrs["big"]; big.Kind() != log.KindInt64 || big.AsInt64() != 12345678901 {
+			t.Errorf("expected the integer big attribute, got %v", big)
+		}
+		if ratio := attrs["ratio"]; ratio.Kind() != log.KindFloat64 || ratio.AsFloat64() != 0.5 {
+			t.Errorf("expected the float ratio attribute, got %v", ratio)
+		}
+	}
+}
+
+func TestEmitLogWithEnrich(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	config := &TransformConfig{
+		Enrich: func(record *LogRecord, structuredAttrs map[string]interface{}) []log.KeyValue {
+			// Derive an attribute from a parsed field and the record metadata
+			if ip, ok := structuredAttrs["client_ip"].(string); ok && strings.HasPrefix(ip, "10.") {
+				return []log.KeyValue{
+					log.String("client.network", "internal"),
+					log.String("client.pod", record.PodName),
+				}
+			}
+			return nil
+		},
+	}
+
+	record := &LogRecord{
+		Timestamp: time.Now(),
+		Body:      `{"level":"info","msg":"request","client_ip":"10.0.0.12"}`,
+		Namespace: "default",
+		PodName:   "test-pod",
+	}
+
+	EmitLogWithConfig(context.Background(), logger, record, config)
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+	}
+
+	attrs := map[string]string{}
+	mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.String()
+		return true
+	})
+	if attrs["client.network"] != "internal" {
+		t.Errorf("expected client.network=internal, got %q", attrs["client.network"])
+	}
+	if attrs["client.pod"] != "test-pod" {
+		t.Errorf("expected client.pod=test-pod, got %q", attrs["client.pod"])
+	}
+	if attrs["client_ip"] != "10.0.0.12" {
+		t.Errorf("expected client_ip to be kept, got %q", attrs["client_ip"])
+	}
+}
+
+func TestEmitLogWithHook(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	calls := 0
+	config := &TransformConfig{
+		Enrich: func(record *LogRecord, structuredAttrs map[string]interface{}) []log.KeyValue {
+			return []log.KeyValue{log.String("enriched", "true")}
+		},
+		Hook: func(record *LogRecord, logRecord *log.Record) {
+			calls++
+			RewriteAttributes(logRecord, func(kv log.KeyValue) (log.KeyValue, bool) {
+				switch kv.Key {
+				case "k8s.pod.name":
+					kv.Key = "pod"
+				case "k8s.namespace.name":
+					return kv, false
+				}
+				return kv, true
+			})
+			logRecord.AddAttributes(log.String("container", record.ContainerName))
+		},
+	}
+
+	record := &LogRecord{
+		Timestamp:     time.Now(),
+		Body:          `{"level":"warn","msg":"slow request"}`,
+		Namespace:     "default",
+		PodName:       "test-pod",
+		ContainerName: "app",
+	}
+
+	EmitLogWithConfig(context.Background(), logger, record, config)
+	provider.ForceFlush(context.Background())
+
+	if calls != 1 {
+		t.Errorf("expected the hook to run once, got %d", calls)
+	}
+	if len(mockExporter.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+	}
+
+	got := mockExporter.records[0]
+	attrs := map[string]string{}
+	got.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.String()
+		return true
+	})
+	want := map[string]string{"pod": "test-pod", "container": "app", "enriched": "true"}
+	for key, value := range want {
+		if attrs[key] != value {
+			t.Errorf("expected %s=%s, got %q", key, value, attrs[key])
+		}
+	}
+	for _, key := range []string{"k8s.pod.name", "k8s.namespace.name"} {
+		if _, ok := attrs[key]; ok {
+			t.Errorf("expected %s to be rewritten, got %v", key, attrs)
+		}
+	}
+
+	// the other fields survive the rewrite
+	if got.Body().AsString() != "slow request" {
+		t.Errorf("expected the body to be kept, got %q", got.Body().AsString())
+	}
+	if got.Severity() != log.SeverityWarn || got.SeverityText() != "warn" {
+		t.Errorf("expected the severity to be kept, got %v %q", got.Severity(), got.SeverityText())
+	}
+	if !got.Timestamp().Equal(record.Timestamp) {
+		t.Errorf("expected the timestamp to be kept, got %v", got.Timestamp())
+	}
+}
+
+func TestRecordID(t *testing.T) {
+	ts := time.Date(2025, 10, 3, 20, 4, 36, 479000000, time.UTC)
+	base := LogRecord{
+		Timestamp:     ts,
+		Body:          "same line",
+		Namespace:     "default",
+		PodName:       "my-pod",
+		ContainerName: "app",
+		LineIndex:     1,
+	}
+
+	same := base
+	same.Labels = map[string]string{"ignored": "true"}
+	if recordID(&base) != recordID(&same) {
+		t.Error("expected identical ids for identical inputs")
+	}
+
+	variants := map[string]func(r *LogRecord){
+		"body":      func(r *LogRecord) { r.Body = "other line" },
+		"pod":       func(r *LogRecord) { r.PodName = "other-pod" },
+		"container": func(r *LogRecord) { r.ContainerName = "sidecar" },
+		"timestamp": func(r *LogRecord) { r.Timestamp = ts.Add(time.Nanosecond) },
+		"lineIndex": func(r *LogRecord) { r.LineIndex = 2 },
+	}
+	for name, mutate := range variants {
+		t.Run(name, func(t *testing.T) {
+			other := base
+			mutate(&other)
+			if recordID(&base) == recordID(&other) {
+				t.Errorf("expected distinct ids when %s differs", name)
+			}
+		})
+	}
+}
+
+func TestEmitLogWithRecordID(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	record := &LogRecord{
+		Timestamp: time.Now(),
+		Body:      "test message",
+		PodName:   "test-pod",
+	}
+
+	EmitLogWithConfig(context.Background(), logger, record, &TransformConfig{RecordID: true})
+	EmitLogWithConfig(context.Background(), logger, record, &TransformConfig{RecordID: true})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(mockExporter.records))
+	}
+
+	var ids []string
+	for _, r := range mockExporter.records {
+		r.WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == "log.record.id" {
+				ids = append(ids, kv.Value.AsString())
+			}
+			return true
+		})
+	}
+	if len(ids) != 2 || ids[0] == "" || ids[0] != ids[1] {
+		t.Errorf("expected the same non-empty log.record.id on both records, got %v", ids)
+	}
+}
+
+func TestParseTaggedLog(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		names           []string
+		expectedMessage string
+		expectedTags    map[string]interface{}
+		expectedTagged  bool
+	}{
+		{
+			name:            "Rails tagged line with names",
+			body:            `[af3c-12] [acme] Started GET "/users" for 127.0.0.1`,
+			names:           []string{"request_id", "tenant"},
+			expectedMessage: `Started GET "/users" for 127.0.0.1`,
+			expectedTags:    map[string]interface{}{"request_id": "af3c-12", "tenant": "acme"},
+			expectedTagged:  true,
+		},
+		{
+			name:            "positional names for unnamed tags",
+			body:            "[af3c-12] [acme] Completed 200 OK",
+			names:           []string{"request_id"},
+			expectedMessage: "Completed 200 OK",
+			expectedTags:    map[string]interface{}{"request_id": "af3c-12", "tag.1": "acme"},
+			expectedTagged:  true,
+		},
+		{
+			name:            "no tags",
+			body:            "plain message [not a tag]",
+			expectedMessage: "plain message [not a tag]",
+			expectedTagged:  false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, tags, isTagged := parseTaggedLog(tt.body, tt.names)
+			if message != tt.expectedMessage {
+				t.Errorf("message = %q, expected %q", message, tt.expectedMessage)
+			}
+			if isTagged != tt.expectedTagged {
+				t.Errorf("isTagged = %v, expected %v", isTagged, tt.expectedTagged)
+			}
+			if !reflect.DeepEqual(tags, tt.expectedTags) {
+				t.Errorf("tags = %v, expected %v", tags, tt.expectedTags)
+			}
+		})
+	}
+}
+
+func TestParseEmbeddedJSON(t *testing.T) {
+	tests := []struct {
+		name             string
+		body             string
+		mode             string
+		expectedMessage  string
+		expectedSeverity string
+		expectedAttrs    map[string]interface{}
+		expectedEmbedded bool
+	}{
+		{
+			name:             "trailing object keeps the prefix",
+			body:             `2025-01-01 INFO handler: {"user":"alice","action":"x"}`,
+			mode:             EmbeddedJSONPrefix,
+			expectedMessage:  "2025-01-01 INFO handler:",
+			expectedAttrs:    map[string]interface{}{"user": "alice", "action": "x"},
+			expectedEmbedded: true,
+		},
+		{
+			name:             "message of the object",
+			body:             `handler {x} : {"level":"warn","msg":"slow","user":"alice"}`,
+			mode:             EmbeddedJSONMessage,
+			expectedMessage:  "slow",
+			expectedSeverity: "warn",
+			expectedAttrs:    map[string]interface{}{"user": "alice"},
+			expectedEmbedded: true,
+		},
+		{
+			name:             "prefix without message in the object",
+			body:             `handler: {"user":"alice"}`,
+			mode:             EmbeddedJSONMessage,
+			expectedMessage:  "handler:",
+			expectedAttrs:    map[string]interface{}{"user": "alice"},
+			expectedEmbedded: true,
+		},
+		{
+			name:             "no trailing object",
+			body:             `handler: {"user":"alice"} done`,
+			mode:             EmbeddedJSONPrefix,
+			expectedMessage:  `handler: {"user":"alice"} done`,
+			expectedEmbedded: false,
+		},
+		{
+			name:             "invalid object",
+			body:             "handler: {not json}",
+			mode:             EmbeddedJSONPrefix,
+			expectedMessage:  "handler: {not json}",
+			expectedEmbedded: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, severity, attrs, isEmbedded := parseEmbeddedJSON(tt.body, DefaultMaxJSONDepth, tt.mode, defaultFieldKeys)
+			if message != tt.expectedMessage {
+				t.Errorf("message = %q, expected %q", message, tt.expectedMessage)
+			}
+			if severity != tt.expectedSeverity {
+				t.Errorf("severity = %q, expected %q", severity, tt.expectedSeverity)
+			}
+			if isEmbedded != tt.expectedEmbedded {
+				t.Errorf("isEmbedded = %v, expected %v", isEmbedded, tt.expectedEmbedded)
+			}
+			if !reflect.DeepEqual(attrs, tt.expectedAttrs) {
+				t.Errorf("attrs = %v, expected %v", attrs, tt.expectedAttrs)
+			}
+		})
+	}
+}
+
+func TestEmitLogWithEmbeddedJSON(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	config := &TransformConfig{EmbeddedJSON: EmbeddedJSONPrefix}
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: `2025-01-01 INFO handler: {"user":"alice","action":"x"}`}, config)
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: "2025-01-01 INFO handler: done"}, config)
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(mockExporter.records))
+	}
+	for i, want := range []struct {
+		body string
+		user string
+	}{
+		{body: "2025-01-01 INFO handler:", user: "alice"},
+		{body: "2025-01-01 INFO handler: done"},
+	} {
+		record := mockExporter.records[i]
+		if got := record.Body().AsString(); got != want.body {
+			t.Errorf("%d: expected body %q, got %q", i, want.body, got)
+		}
+		var user string
+		record.WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == "user" {
+				user = kv.Value.AsString()
+			}
+			return true
+		})
+		if user != want.user {
+			t.Errorf("%d: expected user %q, got %q", i, want.user, user)
+		}
+	}
+
+	if err := (TransformConfig{EmbeddedJSON: "suffix"}).validate(); err == nil {
+		t.Error("expected error for unsupported embedded JSON mode")
+	}
+}
+
+func TestEmitLogRenderMessage(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		body         string
+		expectedBody string
+		keepsMsg     bool
+	}{
+		{
+			name:         "object rendered as JSON",
+			mode:         RenderMessageJSON,
+			body:         `{"level":"info","msg":{"template":"user {} logged in","args":["alice"]},"user":"alice"}`,
+			expectedBody: `{"args":["alice"],"template":"user {} logged in"}`,
+		},
+		{
+			name:         "object rendered from its template",
+			mode:         RenderMessageTemplate,
+			body:         `{"level":"info","msg":{"template":"user {} logged in from {1} after {0} tries","args":["alice",3]},"user":"alice"}`,
+			expectedBody: "user alice logged in from 3 after alice tries",
+		},
+		{
+			name:         "array rendered as JSON in template mode",
+			mode:         RenderMessageTemplate,
+			body:         `{"level":"info","msg":["a",1],"user":"alice"}`,
+			expectedBody: `["a",1]`,
+		},
+		{
+			name:         "string message wins",
+			mode:         RenderMessageTemplate,
+			body:         `{"level":"info","msg":{"template":"ignored"},"message":"plain","user":"alice"}`,
+			expectedBody: "plain",
+			keepsMsg:     true,
+		},
+		{
+			name:         "whole JSON without rendering",
+			body:         `{"level":"info","msg":{"template":"user {}","args":["alice"]},"user":"alice"}`,
+			expectedBody: `{"level":"info","msg":{"template":"user {}","args":["alice"]},"user":"alice"}`,
+			keepsMsg:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			processor := sdklog.NewSimpleProcessor(mockExporter)
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+			logger := provider.Logger("test")
+
+			EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: tt.body}, &TransformConfig{RenderMessage: tt.mode})
+			provider.ForceFlush(context.Background())
+
+			if len(mockExporter.records) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+			}
+			record := mockExporter.records[0]
+			if got := record.Body().AsString(); got != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, got)
+			}
+			record.WalkAttributes(func(kv log.KeyValue) bool {
+				if kv.Key == "msg" && !tt.keepsMsg {
+					t.Errorf("expected the rendered msg field to be removed, got %s", kv.Value)
+				}
+				return true
+			})
+		})
+	}
+
+	if err := (TransformConfig{RenderMessage: "yaml"}).validate(); err == nil {
+		t.Error("expected error for unsupported message rendering")
+	}
+}
+
+func TestEmitLogWithContainerFQN(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	record := &LogRecord{Timestamp: time.Now(), Body: "line", Namespace: "default", PodName: "my-app-7d8f9c-xyz", ContainerName: "app"}
+	for _, sep := range []string{"/", ":", ""} {
+		EmitLogWithConfig(context.Background(), logger, record, &TransformConfig{ContainerFQNSeparator: sep})
+	}
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(mockExporter.records))
+	}
+	for i, want := range []string{"default/my-app-7d8f9c-xyz/app", "default:my-app-7d8f9c-xyz:app", ""} {
+		var fqn, container string
+		mockExporter.records[i].WalkAttributes(func(kv log.KeyValue) bool {
+			switch kv.Key {
+			case "k8s.container.fqn":
+				fqn = kv.Value.AsString()
+			case "k8s.container.name":
+				container = kv.Value.AsString()
+			}
+			return true
+		})
+		if fqn != want {
+			t.Errorf("%d: expected k8s.container.fqn %q, got %q", i, want, fqn)
+		}
+		if container != "app" {
+			t.Errorf("%d: expected k8s.container.name to be kept, got %q", i, container)
+		}
+	}
+}
+
+func TestEmitLogWithLoggerField(t *testing.T) {
+	tests := []struct {
+		name              string
+		config            *TransformConfig
+		expectedService   string
+		expectedAttribute string
+		expectedComponent string
+	}{
+		{
+			name:              "logger mapped to the component attribute",
+			config:            &TransformConfig{LoggerField: "logger"},
+			expectedService:   "my-app",
+			expectedAttribute: "component",
+			expectedComponent: "statler.server.boho-api",
+		},
+		{
+			name:              "last segment used as service name",
+			config:            &TransformConfig{LoggerField: "logger", LoggerAttribute: "code.namespace", LoggerServiceName: true},
+			expectedService:   "boho-api",
+			expectedAttribute: "code.namespace",
+			expectedComponent: "statler.server.boho-api",
+		},
+		{
+			name:              "logger field kept without mapping",
+			config:            &TransformConfig{},
+			expectedService:   "my-app",
+			expectedAttribute: "logger",
+			expectedComponent: "statler.server.boho-api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			processor := sdklog.NewSimpleProcessor(mockExporter)
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+			logger := provider.Logger("test")
+
+			record := &LogRecord{
+				Timestamp: time.Now(),
+				Body:      `{"level":"info","logger":"statler.server.boho-api","msg":"listening"}`,
+				PodName:   "my-app-7d8f9c-xyz",
+				Labels:    map[string]string{"app": "my-app"},
+			}
+			EmitLogWithConfig(context.Background(), logger, record, tt.config)
+			provider.ForceFlush(context.Background())
+
+			if len(mockExporter.records) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+			}
+			attrs := map[string]string{}
+			mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+				attrs[kv.Key] = kv.Value.String()
+				return true
+			})
+			if got := attrs["service.name"]; got != tt.expectedService {
+				t.Errorf("expected service.name %q, got %q", tt.expectedService, got)
+			}
+			if got := attrs[tt.expectedAttribute]; got != tt.expectedComponent {
+				t.Errorf("expected %s %q, got %q", tt.expectedAttribute, tt.expectedComponent, got)
+			}
+			if _, ok := attrs["logger"]; ok && tt.config.LoggerField != "" {
+				t.Error("expected the logger field to be moved")
+			}
+		})
+	}
+}
+
+func TestEmitLogWithStatefulSetOrdinal(t *testing.T) {
+	tests := []struct {
+		name            string
+		podName         string
+		owners          []Owner
+		expectedOrdinal string
+	}{
+		{
+			name:            "first replica",
+			podName:         "web-0",
+			owners:          []Owner{{Kind: "StatefulSet", Name: "web"}},
+			expectedOrdinal: "0",
+		},
+		{
+			name:            "dashed StatefulSet name",
+			podName:         "my-web-12",
+			owners:          []Owner{{Kind: "StatefulSet", Name: "my-web"}},
+			expectedOrdinal: "12",
+		},
+		{
+			name:    "ReplicaSet pod with a numeric suffix",
+			podName: "web-0",
+			owners:  []Owner{{Kind: "ReplicaSet", Name: "web"}},
+		},
+		{
+			name:    "pod without owner",
+			podName: "web-0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			processor := sdklog.NewSimpleProcessor(mockExporter)
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+			logger := provider.Logger("test")
+
+			record := &LogRecord{Timestamp: time.Now(), Body: "line", PodName: tt.podName, Owners: tt.owners}
+			EmitLogWithConfig(context.Background(), logger, record, &TransformConfig{StatefulSetOrdinal: true})
+			EmitLogWithConfig(context.Background(), logger, record, &TransformConfig{})
+			provider.ForceFlush(context.Background())
+
+			for i, want := range []string{tt.expectedOrdinal, ""} {
+				var ordinal string
+				mockExporter.records[i].WalkAttributes(func(kv log.KeyValue) bool {
+					if kv.Key == "k8s.statefulset.pod_ordinal" {
+						ordinal = kv.Value.String()
+					}
+					return true
+				})
+				if ordinal != want {
+					t.Errorf("%d: expected k8s.statefulset.pod_ordinal %q, got %q", i, want, ordinal)
+				}
+			}
+		})
+	}
+}
+
+func TestEmitLogWithBodyHash(t *testing.T) {
+	for _, algorithm := range []string{BodyHashFNV, BodyHashSHA256} {
+		t.Run(algorithm, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			processor := sdklog.NewSimpleProcessor(mockExporter)
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+			logger := provider.Logger("test")
+
+			config := &TransformConfig{BodyHash: algorithm}
+			for _, record := range []*LogRecord{
+				{Timestamp: time.Now(), Body: "connection refused", PodName: "pod-1"},
+				{Timestamp: time.Now().Add(time.Second), Body: `{"level":"error","msg":"connection refused"}`, PodName: "pod-2"},
+				{Timestamp: time.Now(), Body: "connection reset", PodName: "pod-1"},
+			} {
+				EmitLogWithConfig(context.Background(), logger, record, config)
+			}
+			provider.ForceFlush(context.Background())
+
+			hashes := make([]string, len(mockExporter.records))
+			for i, r := range mockExporter.records {
+				r.WalkAttributes(func(kv log.KeyValue) bool {
+					if kv.Key == "log.body.hash" {
+						hashes[i] = kv.Value.AsString()
+					}
+					return true
+				})
+			}
+			if len(hashes) != 3 || hashes[0] == "" {
+				t.Fatalf("expected 3 hashes, got %v", hashes)
+			}
+			if hashes[0] != hashes[1] {
+				t.Errorf("expected identical bodies to share a hash, got %q and %q", hashes[0], hashes[1])
+			}
+			if hashes[0] == hashes[2] {
+				t.Errorf("expected different bodies to have different hashes, got %q", hashes[0])
+			}
+		})
+	}
+
+	if got := bodyHash("hello", BodyHashFNV); got != "a430d84680aabd0b" {
+		t.Errorf("unexpected FNV hash %q", got)
+	}
+	if got := bodyHash("hello", BodyHashSHA256); got != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("unexpected SHA-256 hash %q", got)
+	}
+	if err := (TransformConfig{BodyHash: "md5"}).validate(); err == nil {
+		t.Error("expected error for unsupported body hash algorithm")
+	}
+}
+
+func TestEmitLogWithURLFields(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	body := `{"msg":"request","url":"https://api.example.com/search?q=hello%20world&tag=a&tag=b&page=2","status":200}`
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body}, &TransformConfig{URLFields: []string{"url", "referer"}})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+	}
+	attrs := map[string]log.Value{}
+	mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value
+		return true
+	})
+
+	if got := attrs["query.q"].AsString(); got != "hello world" {
+		t.Errorf("expected query.q %q, got %q", "hello world", got)
+	}
+	if got := attrs["query.page"].AsString(); got != "2" {
+		t.Errorf("expected query.page %q, got %q", "2", got)
+	}
+	tags := attrs["query.tag"]
+	if tags.Kind() != log.KindSlice || len(tags.AsSlice()) != 2 || tags.AsSlice()[0].AsString() != "a" || tags.AsSlice()[1].AsString() != "b" {
+		t.Errorf("expected query.tag [a b], got %v", tags)
+	}
+	if got := attrs["url"].AsString(); got != "https://api.example.com/search?q=hello%20world&tag=a&tag=b&page=2" {
+		t.Errorf("expected the raw URL to be kept, got %q", got)
+	}
+}
+
+func TestEmitLogWithDropFields(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	// A Bunyan record
+	body := `{"name":"api","hostname":"api-7d8f9c-xyz","pid":1,"level":"info","msg":"listening","time":"2025-01-01T00:00:00.000Z","v":0,"port":8080,"tenant":"acme"}`
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body}, &TransformConfig{DropFields: NoiseFields})
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body}, &TransformConfig{})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(mockExporter.records))
+	}
+	fieldsOf := func(r sdklog.Record) map[string]bool {
+		fields := map[string]bool{}
+		r.WalkAttributes(func(kv log.KeyValue) bool {
+			fields[kv.Key] = true
+			return true
+		})
+		return fields
+	}
+
+	trimmed, full := fieldsOf(mockExporter.records[0]), fieldsOf(mockExporter.records[1])
+	for _, field := range []string{"pid", "hostname", "v", "time", "name"} {
+		if trimmed[field] {
+			t.Errorf("expected %s to be dropped by the preset", field)
+		}
+		if !full[field] {
+			t.Errorf("expected %s to be kept by default", field)
+		}
+	}
+	for _, field := range []string{"port", "tenant"} {
+		if !trimmed[field] {
+			t.Errorf("expected app field %s to be kept", field)
+		}
+	}
+}
+
+func TestNormalizeDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:     "milliseconds",
+			attrs:    map[string]interface{}{"duration": "15ms"},
+			expected: map[string]interface{}{"duration.ms": 15.0},
+		},
+		{
+			name:     "seconds",
+			attrs:    map[string]interface{}{"latency": "1.2s"},
+			expected: map[string]interface{}{"duration.ms": 1200.0},
+		},
+		{
+			name:     "nanoseconds",
+			attrs:    map[string]interface{}{"duration": "1500000ns"},
+			expected: map[string]interface{}{"duration.ms": 1.5},
+		},
+		{
+			name:     "first parseable field wins",
+			attrs:    map[string]interface{}{"duration": "slow", "latency": "2s"},
+			expected: map[string]interface{}{"duration": "slow", "duration.ms": 2000.0},
+		},
+		{
+			name:     "values without a unit are left as-is",
+			attrs:    map[string]interface{}{"duration": 15.0, "latency": "15"},
+			expected: map[string]interface{}{"duration": 15.0, "latency": "15"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalizeDuration(tt.attrs, []string{"duration", "latency"})
+			if !reflect.DeepEqual(tt.attrs, tt.expected) {
+				t.Errorf("attrs = %v, expected %v", tt.attrs, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCoerceTypes(t *testing.T) {
+	tests := []struct {
+		name     string
+		attrs    map[string]interface{}
+		expected map[string]interface{}
+	}{
+		{
+			name:     "duration",
+			attrs:    map[string]interface{}{"latency": "1.2s"},
+			expected: map[string]interface{}{"latency": "1.2s", "latency_ns": int64(1200000000)},
+		},
+		{
+			name:     "compound duration",
+			attrs:    map[string]interface{}{"uptime": "1h30m"},
+			expected: map[string]interface{}{"uptime": "1h30m", "uptime_ns": int64(90 * time.Minute)},
+		},
+		{
+			name:     "timestamp",
+			attrs:    map[string]interface{}{"deadline": "2025-01-01T00:00:00Z"},
+			expected: map[string]interface{}{"deadline": "2025-01-01T00:00:00Z", "deadline_epoch_ms": int64(1735689600000)},
+		},
+		{
+			name:     "nested fields",
+			attrs:    map[string]interface{}{"http": map[string]interface{}{"latency": "15ms"}},
+			expected: map[string]interface{}{"http": map[string]interface{}{"latency": "15ms", "latency_ns": int64(15000000)}},
+		},
+		{
+			name:     "existing fields are kept",
+			attrs:    map[string]interface{}{"latency": "2s", "latency_ns": "measured"},
+			expected: map[string]interface{}{"latency": "2s", "latency_ns": "measured"},
+		},
+		{
+			name:     "ordinary strings are left alone",
+			attrs:    map[string]interface{}{"count": "15", "size": "2m ago", "version": "v1.2s", "date": "2025-01-01", "user": "alice", "num": 15.0},
+			expected: map[string]interface{}{"count": "15", "size": "2m ago", "version": "v1.2s", "date": "2025-01-01", "user": "alice", "num": 15.0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			coerceTypes(tt.attrs)
+			if !reflect.DeepEqual(tt.attrs, tt.expected) {
+				t.Errorf("attrs = %v, expected %v", tt.attrs, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEmitLogCoerceTypes(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	body := `{"msg":"request served","latency":"1.2s"}`
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body}, &TransformConfig{CoerceTypes: true})
+	EmitLog(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(mockExporter.records))
+	}
+	latencyOf := func(r sdklog.Record) (log.Value, bool) {
+		var value log.Value
+		var found bool
+		r.WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == "latency_ns" {
+				value, found = kv.Value, true
+				return false
+			}
+			return true
+		})
+		return value, found
+	}
+	if value, ok := latencyOf(mockExporter.records[0]); !ok || value.Kind() != log.KindInt64 || value.AsInt64() != 1200000000 {
+		t.Errorf("expected latency_ns=1200000000, got %v", value)
+	}
+	if value, ok := latencyOf(mockExporter.records[1]); ok {
+		t.Errorf("expected no latency_ns by default, got %v", value)
+	}
+}
+
+func TestEmitTaggedLog(t *testing.T) {
+	config := &TransformConfig{TaggedLogs: true, TagNames: []string{"request_id"}}
+
+	tests := []struct {
+		name         string
+		body         string
+		expectedBody string
+		expectedAttr string
+		expectedVal  string
+	}{
+		{
+			name:         "tagged Rails line",
+			body:         "[af3c-12] Processing by UsersController#index",
+			expectedBody: "Processing by UsersController#index",
+			expectedAttr: "request_id",
+			expectedVal:  "af3c-12",
+		},
+		{
+			name:         "JSON wins over tags",
+			body:         `{"level":"info","message":"[not-a-tag] Completed","request_id":"json-id"}`,
+			expectedBody: "[not-a-tag] Completed",
+			expectedAttr: "request_id",
+			expectedVal:  "json-id",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			processor := sdklog.NewSimpleProcessor(mockExporter)
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+			logger := provider.Logger("test")
+
+			EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: tt.body}, config)
+			provider.ForceFlush(context.Background())
+
+			if len(mockExporter.records) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+			}
+			exportedRecord := mockExporter.records[0]
+			if exportedRecord.Body().String() != tt.expectedBody {
+				t.Errorf("expected body %q, got %q", tt.expectedBody, exportedRecord.Body().String())
+			}
+			var found bool
+			exportedRecord.WalkAttributes(func(kv log.KeyValue) bool {
+				if kv.Key == tt.expectedAttr && kv.Value.AsString() == tt.expectedVal {
+					found = true
+				}
+				return true
+			})
+			if !found {
+				t.Errorf("expected attribute %s=%s", tt.expectedAttr, tt.expectedVal)
+			}
+		})
+	}
+}
+
+func TestEmitLogWithMatches(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	EmitLog(context.Background(), logger, &LogRecord{
+		Timestamp: time.Now(),
+		Body:      "connection refused, retrying",
+		Matches:   []string{"connection", "retrying"},
+	})
+	EmitLog(context.Background(), logger, &LogRecord{
+		Timestamp: time.Now(),
+		Body:      "no include patterns",
+	})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(mockExporter.records))
+	}
+
+	matchesOf := func(r sdklog.Record) []string {
+		var matches []string
+		r.WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == "stern.matches" {
+				for _, v := range kv.Value.AsSlice() {
+					matches = append(matches, v.AsString())
+				}
+			}
+			return true
+		})
+		return matches
+	}
+
+	if got := matchesOf(mockExporter.records[0]); !reflect.DeepEqual(got, []string{"connection", "retrying"}) {
+		t.Errorf("expected stern.matches [connection retrying], got %v", got)
+	}
+	if got := matchesOf(mockExporter.records[1]); got != nil {
+		t.Errorf("expected no stern.matches attribute, got %v", got)
+	}
+}
+
+func TestEmitLogJSONDepthExceeded(t *testing.T) {
+	// 50 levels of nesting: {"a":{"a":...{"msg":"deep"}...}}
+	deep := strings.Repeat(`{"a":`, 49) + `{"msg":"deep"}` + strings.Repeat("}", 49)
+
+	if _, _, _, isStructured, depthExceeded := parseStructuredLogWithDepth(deep, DefaultMaxJSONDepth, defaultFieldKeys); isStructured || !depthExceeded {
+		t.Fatalf("expected the depth guard to trigger, got isStructured=%v depthExceeded=%v", isStructured, depthExceeded)
+	}
+	if _, _, _, isStructured, depthExceeded := parseStructuredLogWithDepth(deep, 64, defaultFieldKeys); !isStructured || depthExceeded {
+		t.Errorf("expected a 64 depth limit to parse the payload, got isStructured=%v depthExceeded=%v", isStructured, depthExceeded)
+	}
+	// Brackets inside strings do not count
+	if exceedsJSONDepth(`{"msg":"[[[{{{\"[[["}`, 1) {
+		t.Error("expected brackets in strings to be ignored")
+	}
+
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	EmitLog(context.Background(), logger, &LogRecord{
+		Timestamp: time.Now(),
+		Body:      deep,
+	})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+	}
+	record := mockExporter.records[0]
+	if record.Body().AsString() != deep {
+		t.Errorf("expected the payload to be kept as the body")
+	}
+
+	var exceeded bool
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		switch kv.Key {
+		case "stern.parse_depth_exceeded":
+			exceeded = kv.Value.AsBool()
+		case "a":
+			t.Error("expected no structured attributes")
+		}
+		return true
+	})
+	if !exceeded {
+		t.Error("expected stern.parse_depth_exceeded attribute")
+	}
+}
+
+func TestEmitLogWithOwners(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	records := []*LogRecord{
+		{Body: "job", Owners: []Owner{{Kind: "Job", Name: "migrate"}}},
+		{Body: "cronjob", Owners: []Owner{{Kind: "Job", Name: "backup-28000000"}, {Kind: "CronJob", Name: "backup"}}},
+		{Body: "deployment", PodUID: "275ecb36", Owners: []Owner{{Kind: "ReplicaSet", Name: "web-7d8f9c6b5d"}, {Kind: "Deployment", Name: "web"}}},
+		{Body: "standalone"},
+	}
+	for _, record := range records {
+		record.Timestamp = time.Now()
+		EmitLog(context.Background(), logger, record)
+	}
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 4 {
+		t.Fatalf("expected 4 records, got %d", len(mockExporter.records))
+	}
+
+	workloadOf := func(r sdklog.Record) map[string]string {
+		workload := map[string]string{}
+		r.WalkAttributes(func(kv log.KeyValue) bool {
+			switch kv.Key {
+			case "k8s.job.name", "k8s.cronjob.name", "k8s.replicaset.name", "k8s.deployment.name", "k8s.pod.uid":
+				workload[kv.Key] = kv.Value.AsString()
+			}
+			return true
+		})
+		return workload
+	}
+
+	expected := []map[string]string{
+		{"k8s.job.name": "migrate"},
+		{"k8s.job.name": "backup-28000000", "k8s.cronjob.name": "backup"},
+		{"k8s.replicaset.name": "web-7d8f9c6b5d", "k8s.deployment.name": "web", "k8s.pod.uid": "275ecb36"},
+		{},
+	}
+	for i, want := range expected {
+		if got := workloadOf(mockExporter.records[i]); !reflect.DeepEqual(want, got) {
+			t.Errorf("%d: expected %v, got %v", i, want, got)
+		}
+	}
+}
+
+func TestEmitLogWithLabelAttributes(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	config := &TransformConfig{LabelAttributes: map[string]string{"team": "service.team"}}
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{
+		Timestamp: time.Now(),
+		Body:      "hello",
+		Labels:    map[string]string{"team": "payments", "app": "checkout"},
+	}, config)
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+	}
+
+	attrs := map[string]string{}
+	mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.AsString()
+		return true
+	})
+	if attrs["service.team"] != "payments" {
+		t.Errorf("expected service.team=payments, got %q", attrs["service.team"])
+	}
+	if _, ok := attrs["k8s.pod.label.team"]; ok {
+		t.Error("expected the mapped label not to be emitted with the label prefix")
+	}
+	if attrs["k8s.pod.label.app"] != "checkout" {
+		t.Errorf("expected unmapped labels to keep the prefix, got %v", attrs)
+	}
+}
+
+func TestEmitLogWithAnnotationAttributes(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	config := &TransformConfig{
+		LabelAttributes:      map[string]string{"tenant": "tenant.id"},
+		AnnotationAttributes: map[string]string{"example.com/region": "tenant.region"},
+		ExcludeAnnotations:   []string{"*"},
+	}
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{
+		Timestamp:   time.Now(),
+		Body:        "hello",
+		Labels:      map[string]string{"tenant": "acme"},
+		Annotations: map[string]string{"example.com/region": "eu-west", "version": "1.0"},
+	}, config)
+	provider.ForceFlush(context.Background())
+
+	attrs := map[string]string{}
+	mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.AsString()
+		return true
+	})
+	if attrs["tenant.id"] != "acme" || attrs["tenant.region"] != "eu-west" {
+		t.Errorf("expected tenant.id=acme and tenant.region=eu-west, got %v", attrs)
+	}
+	for _, key := range []string{"k8s.pod.annotation.example.com/region", "k8s.pod.annotation.version"} {
+		if _, ok := attrs[key]; ok {
+			t.Errorf("expected no %s attribute", key)
+		}
+	}
+}
+
+func TestEmitLogWithoutLabelsAndAnnotations(t *testing.T) {
+	record := &LogRecord{
+		Timestamp:   time.Now(),
+		Body:        "hello",
+		Labels:      map[string]string{"app": "web", "tenant": "acme"},
+		Annotations: map[string]string{"kubectl.kubernetes.io/last-applied-configuration": strings.Repeat("x", 4096), "example.com/region": "eu-west"},
+	}
+	tests := []struct {
+		name     string
+		config   TransformConfig
+		expected []string
+	}{
+		{
+			name:     "default",
+			expected: []string{"k8s.pod.label.app", "k8s.pod.label.tenant", "k8s.pod.annotation.kubectl.kubernetes.io/last-applied-configuration", "k8s.pod.annotation.example.com/region"},
+		},
+		{
+			name:     "no annotations",
+			config:   TransformConfig{NoAnnotations: true},
+			expected: []string{"k8s.pod.label.app", "k8s.pod.label.tenant"},
+		},
+		{
+			name:     "no labels",
+			config:   TransformConfig{NoLabels: true},
+			expected: []string{"k8s.pod.annotation.kubectl.kubernetes.io/last-applied-configuration", "k8s.pod.annotation.example.com/region"},
+		},
+		{
+			name: "mapped ones are kept",
+			config: TransformConfig{
+				NoLabels:             true,
+				NoAnnotations:        true,
+				LabelAttributes:      map[string]string{"tenant": "tenant.id"},
+				AnnotationAttributes: map[string]string{"example.com/region": "tenant.region"},
+			},
+			expected: []string{"tenant.id", "tenant.region"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(mockExporter)))
+			EmitLogWithConfig(context.Background(), provider.Logger("test"), record, &tt.config)
+			provider.ForceFlush(context.Background())
+
+			var got []string
+			mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+				if strings.HasPrefix(kv.Key, "k8s.pod.label.") || strings.HasPrefix(kv.Key, "k8s.pod.annotation.") || strings.HasPrefix(kv.Key, "tenant.") {
+					got = append(got, kv.Key)
+				}
+				return true
+			})
+			slices.Sort(got)
+			expected := slices.Clone(tt.expected)
+			slices.Sort(expected)
+			if !reflect.DeepEqual(expected, got) {
+				t.Errorf("expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		s        string
+		expected bool
+	}{
+		{"app", "app", true},
+		{"app", "apps", false},
+		{"*", "", true},
+		{"app.kubernetes.io/*", "app.kubernetes.io/name", true},
+		{"app.kubernetes.io/*", "helm.sh/chart", false},
+		{"*argocd*", "argocd.argoproj.io/instance", true},
+		{"*-hash", "pod-template-hash", true},
+		{"*-hash", "pod-template-hash-x", false},
+		{"v?", "v1", true},
+		{"v?", "v10", false},
+		{"a*b*c", "axxbyybzc", true},
+		{"a*b*c", "axxbyy", false},
+	}
+
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.s); got != tt.expected {
+			t.Errorf("globMatch(%q, %q) = %v, expected %v", tt.pattern, tt.s, got, tt.expected)
+		}
+	}
+}
+
+func TestEmitLogWithLabelSelection(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	config := &TransformConfig{
+		LabelAttributes:    map[string]string{"team": "service.team"},
+		IncludeLabels:      []string{"app.kubernetes.io/*", "tier"},
+		ExcludeLabels:      []string{"app.kubernetes.io/managed-by"},
+		ExcludeAnnotations: []string{"kubectl.kubernetes.io/*", "*argocd*"},
+	}
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{
+		Timestamp: time.Now(),
+		Body:      "hello",
+		Labels: map[string]string{
+			"team":                         "payments",
+			"tier":                         "backend",
+			"app.kubernetes.io/name":       "checkout",
+			"app.kubernetes.io/managed-by": "Helm",
+			"pod-template-hash":            "7d8f9c",
+		},
+		Annotations: map[string]string{
+			"kubectl.kubernetes.io/last-applied-configuration": "{}",
+			"argocd.argoproj.io/tracking-id":                   "shop:apps/Deployment:shop/checkout",
+			"prometheus.io/scrape":                             "true",
+		},
+	}, config)
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+	}
+
+	var keys []string
+	mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		if strings.HasPrefix(kv.Key, "k8s.pod.") || kv.Key == "service.team" {
+			keys = append(keys, kv.Key)
+		}
+		return true
+	})
+	slices.Sort(keys)
+	expected := []string{
+		"k8s.pod.annotation.prometheus.io/scrape",
+		"k8s.pod.label.app.kubernetes.io/name",
+		"k8s.pod.label.tier",
+		"service.team",
+	}
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("attributes = %v, expected %v", keys, expected)
+	}
+}
+
+func TestEmitLogWithLabelPrefix(t *testing.T) {
+	kubeLabel, kubeAnnotation, empty := "kube.label.", "kube.annotation.", ""
+	tests := []struct {
+		name     string
+		config   *TransformConfig
+		expected map[string]string
+	}{
+		{
+			name:   "default",
+			config: &TransformConfig{},
+			expected: map[string]string{
+				"k8s.pod.label.app":            "checkout",
+				"k8s.pod.annotation.owner":     "payments",
+				"k8s.namespace.name":           "shop",
+				"k8s.pod.label.kubernetes/tag": "v1",
+			},
+		},
+		{
+			name:   "custom",
+			config: &TransformConfig{LabelPrefix: &kubeLabel, AnnotationPrefix: &kubeAnnotation},
+			expected: map[string]string{
+				"kube.label.app":            "checkout",
+				"kube.annotation.owner":     "payments",
+				"k8s.namespace.name":        "shop",
+				"kube.label.kubernetes/tag": "v1",
+			},
+		},
+		{
+			name:   "empty",
+			config: &TransformConfig{LabelPrefix: &empty, AnnotationPrefix: &empty},
+			expected: map[string]string{
+				"app":                "checkout",
+				"owner":              "payments",
+				"k8s.namespace.name": "shop",
+				"kubernetes/tag":     "v1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			processor := sdklog.NewSimpleProcessor(mockExporter)
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+			logger := provider.Logger("test")
+
+			EmitLogWithConfig(context.Background(), logger, &LogRecord{
+				Timestamp:   time.Now(),
+				Body:        "hello",
+				Namespace:   "shop",
+				Labels:      map[string]string{"app": "checkout", "kubernetes/tag": "v1"},
+				Annotations: map[string]string{"owner": "payments"},
+			}, tt.config)
+			provider.ForceFlush(context.Background())
+
+			attrs := map[string]string{}
+			mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+				attrs[kv.Key] = kv.Value.String()
+				return true
+			})
+			for key, want := range tt.expected {
+				if attrs[key] != want {
+					t.Errorf("expected %s=%q, got %q", key, want, attrs[key])
+				}
+			}
+		})
+	}
+}
+
+func TestEmitLogSeverityText(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	tests := []struct {
+		body     string
+		text     string
+		severity log.Severity
+	}{
+		{`{"level":"warn","msg":"slow"}`, "warn", log.SeverityWarn1},
+		{`{"level":"WARNING","msg":"slow"}`, "WARNING", log.SeverityWarn1},
+		{"level=Critical msg=down", "Critical", log.SeverityFatal1},
+		{`{"level":50,"msg":"failed"}`, "ERROR", log.SeverityError1},
+		{"plain text", "", log.SeverityUndefined},
+	}
+	for _, tt := range tests {
+		EmitLog(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: tt.body})
+	}
+	provider.ForceFlush(context.Background())
+
+	for i, tt := range tests {
+		record := mockExporter.records[i]
+		if record.SeverityText() != tt.text || record.Severity() != tt.severity {
+			t.Errorf("%s: expected severity %q (%v), got %q (%v)", tt.body, tt.text, tt.severity, record.SeverityText(), record.Severity())
+		}
+	}
+}
+
+func TestEmitLogSeverityFloor(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	config := &TransformConfig{SeverityFloor: "INFO"}
+	for _, body := range []string{
+		`{"level":"debug","msg":"cache miss"}`,
+		`{"level":"trace","msg":"entering"}`,
+		`{"level":"error","msg":"failed"}`,
+		"plain text",
+	} {
+		EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body}, config)
+	}
+	provider.ForceFlush(context.Background())
+
+	expected := []struct {
+		severity log.Severity
+		text     string
+	}{
+		{log.SeverityInfo1, "INFO"},
+		{log.SeverityInfo1, "INFO"},
+		{log.SeverityError1, "error"},
+		{log.SeverityUndefined, ""},
+	}
+	if len(mockExporter.records) != len(expected) {
+		t.Fatalf("expected %d records, got %d", len(expected), len(mockExporter.records))
+	}
+	for i, want := range expected {
+		record := mockExporter.records[i]
+		if got := record.Severity(); got != want.severity {
+			t.Errorf("%d: expected severity %v, got %v", i, want.severity, got)
+		}
+		if got := record.SeverityText(); got != want.text {
+			t.Errorf("%d: expected severity text %q, got %q", i, want.text, got)
+		}
+	}
+
+	if err := (TransformConfig{SeverityFloor: "info"}).validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := (TransformConfig{SeverityFloor: "verbose"}).validate(); err == nil {
+		t.Error("expected error for unsupported severity floor")
+	}
+}
+
+func TestEmitLogSeverityRules(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	rules, err := ParseSeverityRules([]string{"panic=fatal", "(?i)deprecated=warn"})
+	if err != nil {
+		t.Fatalf("ParseSeverityRules failed: %v", err)
+	}
+	config := &TransformConfig{SeverityRules: rules}
+	for _, body := range []string{
+		"panic: runtime error: index out of range",
+		`{"level":"info","msg":"recovered from panic"}`,
+		`{"level":"debug","msg":"cache miss"}`,
+		"plain text",
+	} {
+		EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body}, config)
+	}
+	provider.ForceFlush(context.Background())
+
+	expected := []log.Severity{log.SeverityFatal1, log.SeverityFatal1, log.SeverityDebug1, log.SeverityUndefined}
+	if len(mockExporter.records) != len(expected) {
+		t.Fatalf("expected %d records, got %d", len(expected), len(mockExporter.records))
+	}
+	for i, want := range expected {
+		if got := mockExporter.records[i].Severity(); got != want {
+			t.Errorf("%d: expected severity %v, got %v", i, want, got)
+		}
+	}
+
+	for _, spec := range []string{"panic", "=fatal", "(=fatal"} {
+		if _, err := ParseSeverityRules([]string{spec}); err == nil {
+			t.Errorf("expected error for rule %q", spec)
+		}
+	}
+	rules, err = ParseSeverityRules([]string{"a=b=error"})
+	if err != nil || rules[0].Pattern.String() != "a=b" || rules[0].Severity != "error" {
+		t.Errorf("unexpected rules %v: %v", rules, err)
+	}
+	rules, _ = ParseSeverityRules([]string{"panic=verbose"})
+	if err := (TransformConfig{SeverityRules: rules}).validate(); err == nil {
+		t.Error("expected error for unsupported rule severity")
+	}
+}
+
+func TestEmitLogDefaultSeverity(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	defaultRules, err := ParseSeverityRules([]string{"ERROR=error"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	config := &TransformConfig{
+		DefaultSeverityRules: defaultRules,
+		ContainerSeverities:  map[string]string{"legacy": "warn"},
+		DefaultSeverity:      "info",
+	}
+	tests := []struct {
+		container string
+		body      string
+		text      string
+		severity  log.Severity
+	}{
+		{"legacy", "ERROR connection lost", "error", log.SeverityError1},
+		{"legacy", "connection restored", "warn", log.SeverityWarn1},
+		{"app", "started", "info", log.SeverityInfo1},
+		// Lines carrying a level are left unchanged
+		{"legacy", `{"level":"debug","msg":"ERROR counters reset"}`, "debug", log.SeverityDebug1},
+	}
+	for _, tt := range tests {
+		EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: tt.body, ContainerName: tt.container}, config)
+	}
+	provider.ForceFlush(context.Background())
+
+	for i, tt := range tests {
+		record := mockExporter.records[i]
+		if record.SeverityText() != tt.text || record.Severity() != tt.severity {
+			t.Errorf("%s %s: expected severity %q (%v), got %q (%v)", tt.container, tt.body, tt.text, tt.severity, record.SeverityText(), record.Severity())
+		}
+	}
+
+	for _, invalid := range []TransformConfig{
+		{DefaultSeverity: "loud"},
+		{ContainerSeverities: map[string]string{"legacy": "loud"}},
+		{DefaultSeverityRules: []SeverityRule{{Pattern: defaultRules[0].Pattern, Severity: "loud"}}},
+	} {
+		if err := invalid.validate(); err == nil {
+			t.Errorf("expected an error for %+v", invalid)
+		}
+	}
+}
+
+func TestEmitLogMinSeverity(t *testing.T) {
+	rules, err := ParseSeverityRules([]string{"panic:=fatal"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	bodies := []string{
+		`{"level":"debug","msg":"cache miss"}`,
+		`{"level":"info","msg":"request served"}`,
+		`{"level":"warn","msg":"slow request"}`,
+		`{"level":"error","msg":"request failed"}`,
+		"panic: runtime error",
+		"plain text without a level",
+	}
+
+	tests := []struct {
+		name     string
+		config   TransformConfig
+		expected []string
+	}{
+		{
+			name:     "warnings and above, keeping plain text",
+			config:   TransformConfig{MinSeverity: "warn", SeverityRules: rules},
+			expected: []string{"slow request", "request failed", "panic: runtime error", "plain text without a level"},
+		},
+		{
+			name:     "dropping plain text",
+			config:   TransformConfig{MinSeverity: "WARN", SeverityRules: rules, DropUnleveled: true},
+			expected: []string{"slow request", "request failed", "panic: runtime error"},
+		},
+		{
+			name:     "after the floor",
+			config:   TransformConfig{MinSeverity: "info", SeverityFloor: "info"},
+			expected: []string{"cache miss", "request served", "slow request", "request failed", "panic: runtime error", "plain text without a level"},
+		},
+		{
+			name:     "drop unleveled alone",
+			config:   TransformConfig{DropUnleveled: true},
+			expected: []string{"cache miss", "request served", "slow request", "request failed", "panic: runtime error", "plain text without a level"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			processor := sdklog.NewSimpleProcessor(mockExporter)
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+			logger := provider.Logger("test")
+
+			for _, body := range bodies {
+				EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body}, &tt.config)
+			}
+			provider.ForceFlush(context.Background())
+
+			var got []string
+			for _, record := range mockExporter.records {
+				got = append(got, record.Body().AsString())
+			}
+			if !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+
+	// heartbeats have no severity but are never skipped
+	mockExporter := &mockLogRecordExporter{}
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(mockExporter)))
+	EmitLogWithConfig(context.Background(), provider.Logger("test"), &LogRecord{Timestamp: time.Now(), Body: "no logs for 1m0s", Heartbeat: true}, &TransformConfig{MinSeverity: "error", DropUnleveled: true})
+	provider.ForceFlush(context.Background())
+	if len(mockExporter.records) != 1 {
+		t.Errorf("expected the heartbeat to be emitted, got %d records", len(mockExporter.records))
+	}
+
+	if err := (TransformConfig{MinSeverity: "loud"}).validate(); err == nil {
+		t.Error("expected an error for an unsupported minimum severity")
+	}
+}
+
+func TestEmitLogWithQOSClassAndPriority(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	priority := int32(1000)
+	EmitLog(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: "guaranteed", QOSClass: "Guaranteed", Priority: &priority})
+	EmitLog(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: "unset"})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(mockExporter.records))
+	}
+
+	schedulingOf := func(r sdklog.Record) map[string]string {
+		scheduling := map[string]string{}
+		r.WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == "k8s.pod.qos_class" || kv.Key == "k8s.pod.priority" {
+				scheduling[kv.Key] = kv.Value.String()
+			}
+			return true
+		})
+		return scheduling
+	}
+
+	if got, want := schedulingOf(mockExporter.records[0]), map[string]string{"k8s.pod.qos_class": "Guaranteed", "k8s.pod.priority": "1000"}; !reflect.DeepEqual(want, got) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := schedulingOf(mockExporter.records[1]); len(got) != 0 {
+		t.Errorf("expected no scheduling attributes, got %v", got)
+	}
+}
+
+func TestEmitLogWithContainerStatus(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	restarts := int32(2)
+	record := &LogRecord{
+		Timestamp:    time.Now(),
+		Body:         "restarted",
+		Image:        "registry:5000/team/app:1.2@sha256:abc",
+		ImageID:      "registry:5000/team/app@sha256:abc",
+		RestartCount: &restarts,
+	}
+	EmitLogWithConfig(context.Background(), logger, record, &TransformConfig{ContainerStatus: true})
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: "pending"}, &TransformConfig{ContainerStatus: true})
+	EmitLog(context.Background(), logger, record)
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(mockExporter.records))
+	}
+
+	statusOf := func(r sdklog.Record) map[string]string {
+		status := map[string]string{}
+		r.WalkAttributes(func(kv log.KeyValue) bool {
+			if strings.HasPrefix(kv.Key, "container.image.") || kv.Key == "k8s.container.restart_count" {
+				status[kv.Key] = kv.Value.String()
+			}
+			return true
+		})
+		return status
+	}
+
+	want := map[string]string{
+		"container.image.name":        "registry:5000/team/app",
+		"container.image.tags":        "[1.2]",
+		"container.image.id":          "registry:5000/team/app@sha256:abc",
+		"k8s.container.restart_count": "2",
+	}
+	if got := statusOf(mockExporter.records[0]); !reflect.DeepEqual(want, got) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if got := statusOf(mockExporter.records[1]); len(got) != 0 {
+		t.Errorf("expected no container status attributes without a status, got %v", got)
+	}
+	if got := statusOf(mockExporter.records[2]); len(got) != 0 {
+		t.Errorf("expected no container status attributes by default, got %v", got)
+	}
+}
+
+func TestSplitImage(t *testing.T) {
+	tests := []struct {
+		image, name, tag string
+	}{
+		{"nginx", "nginx", ""},
+		{"nginx:1.27", "nginx", "1.27"},
+		{"registry:5000/app", "registry:5000/app", ""},
+		{"registry:5000/app:v1", "registry:5000/app", "v1"},
+		{"app@sha256:abc", "app", ""},
+		{"app:v1@sha256:abc", "app", "v1"},
+	}
+	for _, tt := range tests {
+		if name, tag := splitImage(tt.image); name != tt.name || tag != tt.tag {
+			t.Errorf("splitImage(%q): expected %q %q, got %q %q", tt.image, tt.name, tt.tag, name, tag)
+		}
+	}
+}
+
+func TestEmitLogWithTailedContainers(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	EmitLog(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: "counted", TailedContainers: 3, LineNumber: 7})
+	EmitLog(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: "uncounted"})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(mockExporter.records))
+	}
+	for i, want := range []string{"3", ""} {
+		var got string
+		mockExporter.records[i].WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == "stern.session.tailed_containers" {
+				got = kv.Value.String()
+			}
+			return true
+		})
+		if got != want {
+			t.Errorf("%d: expected stern.session.tailed_containers %q, got %q", i, want, got)
+		}
+	}
+
+	var lineNo string
+	mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		if kv.Key == "stern.line_no" {
+			lineNo = kv.Value.String()
+		}
+		return true
+	})
+	if lineNo != "7" {
+		t.Errorf("expected stern.line_no 7, got %q", lineNo)
+	}
+}
+
+func TestEmitLogPrevious(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	EmitLog(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: "crashed", Previous: true})
+	EmitLog(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: "current"})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(mockExporter.records))
+	}
+	for i, want := range []bool{true, false} {
+		var got bool
+		mockExporter.records[i].WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == "log.previous" {
+				got = kv.Value.AsBool()
+			}
+			return true
+		})
+		if got != want {
+			t.Errorf("%d: expected log.previous %t, got %t", i, want, got)
+		}
+	}
+}
+
+func TestEmitLogWithFieldObjects(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	body := `{"level":"warning","msg":"slow query","user":"alice","extra":{"user":"bob","duration_ms":1200,"db":{"name":"orders"}},"context":{"a":1}}`
+	EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body}, &TransformConfig{FieldObjects: []string{"extra", "attributes"}})
+	provider.ForceFlush(context.Background())
+
+	if len(mockExporter.records) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+	}
+	record := mockExporter.records[0]
+	if record.Body().AsString() != "slow query" || record.Severity() != log.SeverityWarn1 {
+		t.Errorf("expected top-level message and level extraction, got %q %v", record.Body().AsString(), record.Severity())
+	}
+
+	attrs := map[string]string{}
+	record.WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.String()
+		return true
+	})
+	expected := map[string]string{
+		"duration_ms": "1200",
+		"db":          `{"name":"orders"}`,
+		"user":        "alice",
+		"context":     `{"a":1}`,
+	}
+	for key, want := range expected {
+		if got := attrs[key]; got != want {
+			t.Errorf("expected %s=%s, got %q", key, want, got)
+		}
+	}
+	if _, ok := attrs["extra"]; ok {
+		t.Error("expected the extra object not to be emitted as a JSON attribute")
+	}
+}
+
+func TestEmitLogTraceContext(t *testing.T) {
+	tests := []struct {
+		name            string
+		body            string
+		expectedTraceID string
+		expectedSpanID  string
+		expectedAttrs   []string
+	}{
+		{
+			name:            "hex ids",
+			body:            `{"msg":"charged","trace_id":"4BF92F3577B34DA6A3CE929D0E0E4736","span_id":"00f067aa0ba902b7"}`,
+			expectedTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedSpanID:  "00f067aa0ba902b7",
+		},
+		{
+			name:            "camel case keys and a 64-bit trace id",
+			body:            `{"msg":"charged","traceID":"a3ce929d0e0e4736","spanID":"00f067aa0ba902b7"}`,
+			expectedTraceID: "0000000000000000a3ce929d0e0e4736",
+			expectedSpanID:  "00f067aa0ba902b7",
+		},
+		{
+			name:            "datadog ids",
+			body:            `{"msg":"charged","dd.trace_id":"1234567890123456789","dd.span_id":"987654321"}`,
+			expectedTraceID: "0000000000000000112210f47de98115",
+			expectedSpanID:  "000000003ade68b1",
+		},
+		{
+			name:            "malformed span id",
+			body:            `{"msg":"charged","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736","span_id":"not-a-span"}`,
+			expectedTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedAttrs:   []string{"span_id"},
+		},
+		{
+			name:          "malformed trace id",
+			body:          `{"msg":"charged","trace_id":"xyz","span_id":"00f067aa0ba902b7"}`,
+			expectedAttrs: []string{"trace_id", "span_id"},
+		},
+		{
+			name:          "all-zero trace id",
+			body:          `{"msg":"charged","trace_id":"00000000000000000000000000000000"}`,
+			expectedAttrs: []string{"trace_id"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			processor := sdklog.NewSimpleProcessor(mockExporter)
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+
+			EmitLog(context.Background(), provider.Logger("test"), &LogRecord{Timestamp: time.Now(), Body: tt.body})
+			provider.ForceFlush(context.Background())
+
+			if len(mockExporter.records) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+			}
+			record := mockExporter.records[0]
+
+			var traceID, spanID string
+			if record.TraceID().IsValid() {
+				traceID = record.TraceID().String()
+			}
+			if record.SpanID().IsValid() {
+				spanID = record.SpanID().String()
+			}
+			if traceID != tt.expectedTraceID {
+				t.Errorf("trace id = %q, expected %q", traceID, tt.expectedTraceID)
+			}
+			if spanID != tt.expectedSpanID {
+				t.Errorf("span id = %q, expected %q", spanID, tt.expectedSpanID)
+			}
+
+			attrs := make(map[string]bool)
+			record.WalkAttributes(func(kv log.KeyValue) bool {
+				attrs[kv.Key] = true
+				return true
+			})
+			for _, key := range []string{"trace_id", "traceID", "span_id", "spanID", "dd.trace_id", "dd.span_id"} {
+				if want := slices.Contains(tt.expectedAttrs, key); attrs[key] != want {
+					t.Errorf("attribute %q present = %v, expected %v", key, attrs[key], want)
+				}
+			}
+		})
+	}
+}
+
+func TestEmitLogWithFieldKeys(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	config := &TransformConfig{
+		MessageKeys:  []string{"event", "@message"},
+		SeverityKeys: []string{"log.level"},
+	}
+	for _, body := range []string{
+		`{"event":"user logged in","log.level":"warning","message":"ignored","level":"debug"}`,
+		`{"@message":"GC pause","log.level":"error"}`,
+		`{"msg":"default keys","level":"info"}`,
+		`log.level=error event="disk full"`,
+	} {
+		EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body}, config)
+	}
+	provider.ForceFlush(context.Background())
+
+	expected := []struct {
+		body     string
+		severity log.Severity
+	}{
+		{"user logged in", log.SeverityWarn1},
+		{"GC pause", log.SeverityError1},
+		{"default keys", log.SeverityInfo1},
+		{"disk full", log.SeverityError1},
+	}
+	if len(mockExporter.records) != len(expected) {
+		t.Fatalf("expected %d records, got %d", len(expected), len(mockExporter.records))
+	}
+	for i, want := range expected {
+		record := mockExporter.records[i]
+		if got := record.Body().AsString(); got != want.body {
+			t.Errorf("%d: expected body %q, got %q", i, want.body, got)
+		}
+		if got := record.Severity(); got != want.severity {
+			t.Errorf("%d: expected severity %v, got %v", i, want.severity, got)
+		}
+	}
+
+	// The default fields the configured ones won over are kept as attributes
+	attrs := make(map[string]string)
+	mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+		attrs[kv.Key] = kv.Value.AsString()
+		return true
+	})
+	if attrs["message"] != "ignored" || attrs["level"] != "debug" {
+		t.Errorf("expected the message and level fields as attributes, got %v", attrs)
+	}
+}
+
+func TestEmitLogWithFlattenDepth(t *testing.T) {
+	body := `{"msg":"request","resource":{"service.name":"api","service.version":"1.2.0","host":{"arch":"arm64"}},"tags":["a","b"],"empty":{}}`
+	tests := []struct {
+		name     string
+		depth    int
+		expected map[string]interface{}
+	}{
+		{
+			name:  "stringify by default",
+			depth: 0,
+			expected: map[string]interface{}{
+				"resource": `{"host":{"arch":"arm64"},"service.name":"api","service.version":"1.2.0"}`,
+				"tags":     `["a","b"]`,
+				"empty":    `{}`,
+			},
+		},
+		{
+			name:  "one level",
+			depth: 1,
+			expected: map[string]interface{}{
+				"resource.service.name":    "api",
+				"resource.service.version": "1.2.0",
+				"resource.host":            `{"arch":"arm64"}`,
+				"tags.0":                   "a",
+				"tags.1":                   "b",
+				"empty":                    `{}`,
+			},
+		},
+		{
+			name:  "all levels",
+			depth: 8,
+			expected: map[string]interface{}{
+				"resource.service.name":    "api",
+				"resource.service.version": "1.2.0",
+				"resource.host.arch":       "arm64",
+				"tags.0":                   "a",
+				"tags.1":                   "b",
+				"empty":                    `{}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			processor := sdklog.NewSimpleProcessor(mockExporter)
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+
+			EmitLogWithConfig(context.Background(), provider.Logger("test"), &LogRecord{Timestamp: time.Now(), Body: body}, &TransformConfig{FlattenDepth: tt.depth})
+			provider.ForceFlush(context.Background())
+
+			if len(mockExporter.records) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(mockExporter.records))
+			}
+			attrs := make(map[string]interface{})
+			mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+				if kv.Key != "service.name" {
+					attrs[kv.Key] = kv.Value.AsString()
+				}
+				return true
+			})
+			if !reflect.DeepEqual(attrs, tt.expected) {
+				t.Errorf("attrs = %v, expected %v", attrs, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEmitLogKeepRaw(t *testing.T) {
+	mockExporter := &mockLogRecordExporter{}
+	processor := sdklog.NewSimpleProcessor(mockExporter)
+	provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(processor))
+	logger := provider.Logger("test")
+
+	structured := `{"level":"info","msg":"started","port":8080}`
+	for _, config := range []*TransformConfig{{KeepRaw: true}, {}} {
+		for _, body := range []string{structured, "plain text"} {
+			EmitLogWithConfig(context.Background(), logger, &LogRecord{Timestamp: time.Now(), Body: body}, config)
+		}
+	}
+	provider.ForceFlush(context.Background())
+
+	expected := []string{structured, "", "", ""}
+	if len(mockExporter.records) != len(expected) {
+		t.Fatalf("expected %d records, got %d", len(expected), len(mockExporter.records))
+	}
+	for i, want := range expected {
+		var original string
+		mockExporter.records[i].WalkAttributes(func(kv log.KeyValue) bool {
+			if kv.Key == "log.record.original" {
+				original = kv.Value.AsString()
+			}
+			return true
+		})
+		if original != want {
+			t.Errorf("%d: expected log.record.original %q, got %q", i, want, original)
+		}
+	}
+}
+
+func TestEmitLogWithClusterName(t *testing.T) {
+	tests := []struct {
+		name        string
+		clusterName string
+		expected    string
+	}{
+		{name: "unset"},
+		{name: "set", clusterName: "production", expected: "production"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockExporter := &mockLogRecordExporter{}
+			provider := sdklog.NewLoggerProvider(sdklog.WithProcessor(sdklog.NewSimpleProcessor(mockExporter)))
+			record := &LogRecord{Timestamp: time.Now(), Body: "hello", Namespace: "default"}
+			EmitLogWithConfig(context.Background(), provider.Logger("test"), record, &TransformConfig{ClusterName: tt.clusterName})
+			provider.ForceFlush(context.Background())
+
+			var got string
+			mockExporter.records[0].WalkAttributes(func(kv log.KeyValue) bool {
+				if kv.Key == "k8s.cluster.name" {
+					got = kv.Value.AsString()
+				}
+				return true
+			})
+			if got != tt.expected {
+				t.Errorf("expected k8s.cluster.name %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
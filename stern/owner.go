@@ -0,0 +1,100 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package stern
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/stern/stern/stern/otel"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ownerResolver resolves the chain of controllers owning a pod. The
+// resolution is best-effort: owners that cannot be fetched are left out.
+type ownerResolver struct {
+	client kubernetes.Interface
+
+	mu   sync.Mutex
+	jobs map[string][]otel.Owner // the owners of a job by namespace/name
+}
+
+func newOwnerResolver(client kubernetes.Interface) *ownerResolver {
+	return &ownerResolver{
+		client: client,
+		jobs:   make(map[string][]otel.Owner),
+	}
+}
+
+// resolve returns the owners of the pod, nearest first
+func (r *ownerResolver) resolve(ctx context.Context, pod *corev1.Pod) []otel.Owner {
+	ref := metav1.GetControllerOf(pod)
+	if ref == nil {
+		return nil
+	}
+
+	owners := []otel.Owner{{Kind: ref.Kind, Name: ref.Name}}
+	switch ref.Kind {
+	case "Job":
+		owners = append(owners, r.jobOwners(ctx, pod.Namespace, ref.Name)...)
+	case "ReplicaSet":
+		if deployment, ok := replicaSetDeployment(pod, ref.Name); ok {
+			owners = append(owners, otel.Owner{Kind: "Deployment", Name: deployment})
+		}
+	}
+	return owners
+}
+
+// replicaSetDeployment returns the Deployment of the ReplicaSet owning the
+// pod, trimming the pod template hash the Deployment suffixes the name of
+// its ReplicaSets with, e.g. "web" for "web-7d8f9c6b5d". It saves fetching
+// the ReplicaSet, which stern may not be allowed to.
+func replicaSetDeployment(pod *corev1.Pod, replicaSet string) (string, bool) {
+	hash := pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+	if hash == "" {
+		return "", false
+	}
+	deployment, ok := strings.CutSuffix(replicaSet, "-"+hash)
+	return deployment, ok && deployment != ""
+}
+
+// jobOwners returns the CronJob owning the job, if any. Results, including
+// failed lookups, are cached since all the pods of a job share them.
+func (r *ownerResolver) jobOwners(ctx context.Context, namespace, name string) []otel.Owner {
+	key := namespace + "/" + name
+
+	r.mu.Lock()
+	owners, ok := r.jobs[key]
+	r.mu.Unlock()
+	if ok {
+		return owners
+	}
+
+	job, err := r.client.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		if ref := metav1.GetControllerOf(job); ref != nil && ref.Kind == "CronJob" {
+			owners = []otel.Owner{{Kind: ref.Kind, Name: ref.Name}}
+		}
+	}
+
+	r.mu.Lock()
+	r.jobs[key] = owners
+	r.mu.Unlock()
+	return owners
+}
@@ -0,0 +1,82 @@
+package stern
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stern/stern/stern/otel"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func controllerRef(kind, name string) []metav1.OwnerReference {
+	return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: ptr.To(true)}}
+}
+
+func TestOwnerResolverResolve(t *testing.T) {
+	clientset := fake.NewSimpleClientset(
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "migrate",
+		}},
+		&batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Namespace:       "ns1",
+			Name:            "backup-28000000",
+			OwnerReferences: controllerRef("CronJob", "backup"),
+		}},
+	)
+	resolver := newOwnerResolver(clientset)
+
+	tests := []struct {
+		name     string
+		owners   []metav1.OwnerReference
+		expected []otel.Owner
+	}{
+		{
+			name:     "job-owned pod",
+			owners:   controllerRef("Job", "migrate"),
+			expected: []otel.Owner{{Kind: "Job", Name: "migrate"}},
+		},
+		{
+			name:     "cronjob-owned pod",
+			owners:   controllerRef("Job", "backup-28000000"),
+			expected: []otel.Owner{{Kind: "Job", Name: "backup-28000000"}, {Kind: "CronJob", Name: "backup"}},
+		},
+		{
+			name:     "job not found",
+			owners:   controllerRef("Job", "gone"),
+			expected: []otel.Owner{{Kind: "Job", Name: "gone"}},
+		},
+		{
+			name:     "deployment-owned pod",
+			owners:   controllerRef("ReplicaSet", "web-7d8f9c6b5d"),
+			expected: []otel.Owner{{Kind: "ReplicaSet", Name: "web-7d8f9c6b5d"}, {Kind: "Deployment", Name: "web"}},
+		},
+		{
+			name:     "standalone replicaset",
+			owners:   controllerRef("ReplicaSet", "cache"),
+			expected: []otel.Owner{{Kind: "ReplicaSet", Name: "cache"}},
+		},
+		{
+			name: "standalone pod",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Namespace:       "ns1",
+				Name:            "pod1",
+				Labels:          map[string]string{"pod-template-hash": "7d8f9c6b5d"},
+				OwnerReferences: tt.owners,
+			}}
+			if got := resolver.resolve(context.Background(), pod); !reflect.DeepEqual(tt.expected, got) {
+				t.Errorf("expected %v, but actual %v", tt.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package stern
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"github.com/stern/stern/stern/otel"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// podOrdererRegistry shares a podOrderer between the tails of the same pod
+type podOrdererRegistry struct {
+	window time.Duration
+	emit   func(*otel.LogRecord)
+
+	mu       sync.Mutex
+	orderers map[string]*podOrderer
+}
+
+func newPodOrdererRegistry(window time.Duration, emit func(*otel.LogRecord)) *podOrdererRegistry {
+	return &podOrdererRegistry{
+		window:   window,
+		emit:     emit,
+		orderers: make(map[string]*podOrderer),
+	}
+}
+
+// acquire returns the orderer of the pod, creating it for the first tail.
+// Each call must be paired with a call to releaseRef.
+func (r *podOrdererRegistry) acquire(pod *corev1.Pod) *podOrderer {
+	key := pod.Namespace + "/" + pod.Name
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	o, ok := r.orderers[key]
+	if !ok {
+		o = newPodOrderer(r.window, r.emit)
+		o.registry = r
+		o.key = key
+		o.start()
+		r.orderers[key] = o
+	}
+	o.refs++
+	return o
+}
+
+// podOrderer merges the OTel records of the containers of a pod and emits
+// them in timestamp order. A record is held until a record at least window
+// newer has been seen or it has waited for window.
+type podOrderer struct {
+	window time.Duration
+	emit   func(*otel.LogRecord)
+
+	registry *podOrdererRegistry
+	key      string
+	refs     int
+
+	mu      sync.Mutex
+	pending orderedRecords
+	latest  time.Time
+	seq     uint64
+
+	done chan struct{}
+}
+
+func newPodOrderer(window time.Duration, emit func(*otel.LogRecord)) *podOrderer {
+	return &podOrderer{
+		window: window,
+		emit:   emit,
+		done:   make(chan struct{}),
+	}
+}
+
+// start periodically releases records that have waited for the window
+func (o *podOrderer) start() {
+	go func() {
+		ticker := time.NewTicker(o.window)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				o.release(time.Now())
+			case <-o.done:
+				return
+			}
+		}
+	}()
+}
+
+// add buffers the record and emits the records that are now in order
+func (o *podOrderer) add(record *otel.LogRecord) {
+	now := time.Now()
+
+	o.mu.Lock()
+	heap.Push(&o.pending, &orderedRecord{record: record, arrived: now, seq: o.seq})
+	o.seq++
+	if record.Timestamp.After(o.latest) {
+		o.latest = record.Timestamp
+	}
+	o.mu.Unlock()
+
+	o.release(now)
+}
+
+// release emits, in timestamp order, the records that can no longer be
+// preceded by a late record within the window
+func (o *podOrderer) release(now time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for o.pending.Len() > 0 {
+		next := o.pending[0]
+		if next.record.Timestamp.After(o.latest.Add(-o.window)) && now.Sub(next.arrived) < o.window {
+			return
+		}
+		heap.Pop(&o.pending)
+		o.emit(next.record)
+	}
+}
+
+// flush emits all buffered records in timestamp order
+func (o *podOrderer) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for o.pending.Len() > 0 {
+		o.emit(heap.Pop(&o.pending).(*orderedRecord).record)
+	}
+}
+
+// releaseRef drops a reference to the orderer, flushing it once the last tail
+// of the pod is gone
+func (o *podOrderer) releaseRef() {
+	if o.registry == nil {
+		return
+	}
+	r := o.registry
+
+	r.mu.Lock()
+	o.refs--
+	last := o.refs == 0
+	if last {
+		delete(r.orderers, o.key)
+	}
+	r.mu.Unlock()
+
+	if last {
+		close(o.done)
+		o.flush()
+	}
+}
+
+// orderedRecord is a buffered record; seq keeps arrival order for equal timestamps
+type orderedRecord struct {
+	record  *otel.LogRecord
+	arrived time.Time
+	seq     uint64
+}
+
+// orderedRecords is a min-heap of records by timestamp
+type orderedRecords []*orderedRecord
+
+func (h orderedRecords) Len() int { return len(h) }
+
+func (h orderedRecords) Less(i, j int) bool {
+	if h[i].record.Timestamp.Equal(h[j].record.Timestamp) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].record.Timestamp.Before(h[j].record.Timestamp)
+}
+
+func (h orderedRecords) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *orderedRecords) Push(x any) { *h = append(*h, x.(*orderedRecord)) }
+
+func (h *orderedRecords) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
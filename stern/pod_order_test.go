@@ -0,0 +1,121 @@
+package stern
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stern/stern/stern/otel"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPodOrdererOrdersContainers(t *testing.T) {
+	var emitted []*otel.LogRecord
+	o := newPodOrderer(time.Hour, func(record *otel.LogRecord) {
+		emitted = append(emitted, record)
+	})
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newRecord := func(container string, offset time.Duration) *otel.LogRecord {
+		return &otel.LogRecord{
+			Timestamp:     base.Add(offset),
+			Body:          container,
+			ContainerName: container,
+		}
+	}
+
+	// Two containers whose lines arrive interleaved out of timestamp order
+	o.add(newRecord("app", 2*time.Second))
+	o.add(newRecord("sidecar", 1*time.Second))
+	o.add(newRecord("app", 4*time.Second))
+	o.add(newRecord("sidecar", 3*time.Second))
+	o.add(newRecord("sidecar", 4*time.Second))
+
+	if len(emitted) != 0 {
+		t.Fatalf("expected records to be held within the window, got %d", len(emitted))
+	}
+
+	o.flush()
+
+	expected := []struct {
+		container string
+		offset    time.Duration
+	}{
+		{"sidecar", 1 * time.Second},
+		{"app", 2 * time.Second},
+		{"sidecar", 3 * time.Second},
+		{"app", 4 * time.Second},
+		{"sidecar", 4 * time.Second},
+	}
+	if len(emitted) != len(expected) {
+		t.Fatalf("expected %d records, got %d", len(expected), len(emitted))
+	}
+	for i, want := range expected {
+		got := emitted[i]
+		if got.ContainerName != want.container || !got.Timestamp.Equal(base.Add(want.offset)) {
+			t.Errorf("%d: expected %s at %v, got %s at %v", i, want.container, want.offset, got.ContainerName, got.Timestamp.Sub(base))
+		}
+	}
+}
+
+func TestPodOrdererReleasesOutsideWindow(t *testing.T) {
+	var emitted []*otel.LogRecord
+	o := newPodOrderer(time.Minute, func(record *otel.LogRecord) {
+		emitted = append(emitted, record)
+	})
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	o.add(&otel.LogRecord{Timestamp: base.Add(10 * time.Second), ContainerName: "app"})
+	o.add(&otel.LogRecord{Timestamp: base, ContainerName: "sidecar"})
+	if len(emitted) != 0 {
+		t.Fatalf("expected no records before the window elapses, got %d", len(emitted))
+	}
+
+	// A record a full window newer releases everything older than the window
+	o.add(&otel.LogRecord{Timestamp: base.Add(70 * time.Second), ContainerName: "app"})
+	if len(emitted) != 2 {
+		t.Fatalf("expected 2 released records, got %d", len(emitted))
+	}
+	if emitted[0].ContainerName != "sidecar" || emitted[1].ContainerName != "app" {
+		t.Errorf("expected sidecar then app, got %s then %s", emitted[0].ContainerName, emitted[1].ContainerName)
+	}
+
+	// Records that have waited for the window are released as well
+	o.release(time.Now().Add(time.Minute))
+	if len(emitted) != 3 {
+		t.Errorf("expected the last record to be released after waiting, got %d records", len(emitted))
+	}
+}
+
+func TestPodOrdererRegistry(t *testing.T) {
+	var emitted []*otel.LogRecord
+	registry := newPodOrdererRegistry(time.Hour, func(record *otel.LogRecord) {
+		emitted = append(emitted, record)
+	})
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "web"}}
+	other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "db"}}
+
+	app := registry.acquire(pod)
+	sidecar := registry.acquire(pod)
+	if app != sidecar {
+		t.Fatal("expected the containers of a pod to share an orderer")
+	}
+	if registry.acquire(other) == app {
+		t.Fatal("expected different pods to use different orderers")
+	}
+
+	app.add(&otel.LogRecord{Timestamp: time.Now(), ContainerName: "app"})
+
+	app.releaseRef()
+	if len(emitted) != 0 {
+		t.Fatalf("expected records to be held while a tail remains, got %d", len(emitted))
+	}
+	sidecar.releaseRef()
+	if len(emitted) != 1 {
+		t.Errorf("expected the last tail to flush the orderer, got %d records", len(emitted))
+	}
+	if registry.acquire(pod) == app {
+		t.Error("expected a new orderer once the pod's tails are gone")
+	}
+}
@@ -0,0 +1,102 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package stern
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// repeatCollapser collapses the repeats of a line of a container, e.g. a
+// reconcile error spammed by a controller, within window after its first
+// occurrence. The first occurrence passes, and the repeats are reported by
+// a single call to flush with their number, on the next different line,
+// once the window has passed and on close. The lines are counted to resume
+// before they are collapsed, so that the repeats are not read again after
+// a reconnect.
+type repeatCollapser struct {
+	window time.Duration
+	flush  func(ctx context.Context, message string, timestamp time.Time, count int64)
+	// locker, when set, is held around the flush once the window has passed,
+	// which runs on the goroutine of the timer, e.g. the lock serializing
+	// the output of the tail. Callers of collapse and close hold it already.
+	locker sync.Locker
+
+	mu        sync.Mutex
+	message   string    // the last line passed
+	started   time.Time // when message passed, zero when none did
+	count     int64     // the repeats of message collapsed so far
+	timestamp time.Time // the timestamp of the last repeat
+	timer     *time.Timer
+}
+
+// collapse reports whether the line repeats the last one within the window,
+// and so is collapsed. Otherwise the repeats of the last line are flushed
+// first, and the line passes.
+func (c *repeatCollapser) collapse(ctx context.Context, message string, timestamp time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.started.IsZero() && message == c.message && time.Since(c.started) < c.window {
+		c.count++
+		c.timestamp = timestamp
+		if c.timer == nil {
+			c.timer = time.AfterFunc(c.window-time.Since(c.started), c.expire)
+		}
+		return true
+	}
+
+	c.flushLocked(ctx)
+	c.message, c.started = message, time.Now()
+	return false
+}
+
+// expire flushes the repeats once the window has passed
+func (c *repeatCollapser) expire() {
+	if c.locker != nil {
+		c.locker.Lock()
+		defer c.locker.Unlock()
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// The timer of a flushed line may fire after the next line passed
+	if time.Since(c.started) < c.window {
+		return
+	}
+	c.flushLocked(context.Background())
+	// A repeat after the window passes again
+	c.started = time.Time{}
+}
+
+// close flushes the repeats and stops the window
+func (c *repeatCollapser) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flushLocked(context.Background())
+}
+
+func (c *repeatCollapser) flushLocked(ctx context.Context) {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if c.count == 0 {
+		return
+	}
+	count := c.count
+	c.count = 0
+	c.flush(ctx, c.message, c.timestamp, count)
+}
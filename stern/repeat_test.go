@@ -0,0 +1,173 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package stern
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/stern/stern/stern/otel"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRepeatCollapser(t *testing.T) {
+	var flushed []string
+	collapser := &repeatCollapser{
+		window: time.Hour,
+		flush: func(ctx context.Context, message string, timestamp time.Time, count int64) {
+			flushed = append(flushed, message+" x"+strings.Repeat("+", int(count)))
+		},
+	}
+
+	var passed []string
+	for _, line := range []string{"reconcile failed", "reconcile failed", "reconcile failed", "synced", "synced", "reconcile failed"} {
+		if !collapser.collapse(context.Background(), line, time.Now()) {
+			passed = append(passed, line)
+		}
+	}
+	collapser.close()
+
+	if expected := []string{"reconcile failed", "synced", "reconcile failed"}; strings.Join(passed, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v to pass, got %v", expected, passed)
+	}
+	if expected := []string{"reconcile failed x++", "synced x+"}; strings.Join(flushed, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected %v to be flushed, got %v", expected, flushed)
+	}
+}
+
+func TestRepeatCollapserWindow(t *testing.T) {
+	var mu sync.Mutex
+	var counts []int64
+	collapser := &repeatCollapser{
+		window: 50 * time.Millisecond,
+		flush: func(ctx context.Context, message string, timestamp time.Time, count int64) {
+			mu.Lock()
+			defer mu.Unlock()
+			counts = append(counts, count)
+		},
+	}
+	defer collapser.close()
+
+	ctx := context.Background()
+	collapser.collapse(ctx, "reconcile failed", time.Now())
+	collapser.collapse(ctx, "reconcile failed", time.Now())
+	time.Sleep(200 * time.Millisecond)
+
+	mu.Lock()
+	if len(counts) != 1 || counts[0] != 1 {
+		t.Errorf("expected the repeat to be flushed once the window passed, got %v", counts)
+	}
+	mu.Unlock()
+
+	// the window passed, so the line passes again
+	if collapser.collapse(ctx, "reconcile failed", time.Now()) {
+		t.Error("expected the line to pass after the window")
+	}
+}
+
+func TestConsumeLineRepeats(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tmpl := template.Must(template.New("").Parse(`{{printf "%s\n" .Message}}`))
+	out := new(bytes.Buffer)
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, out, io.Discard, &TailOptions{RepeatWindow: time.Hour}, false, nil, false)
+
+	ctx := context.Background()
+	tail.consumeLine(ctx, "2025-01-01T12:00:00.000000001Z reconcile failed")
+	tail.consumeLine(ctx, "2025-01-01T12:00:00.000000002Z reconcile failed")
+	tail.consumeLine(ctx, "2025-01-01T12:00:00.000000003Z reconcile failed")
+	tail.consumeLine(ctx, "2025-01-01T12:00:01.000000001Z synced")
+	tail.consumeLine(ctx, "2025-01-01T12:00:02.000000001Z synced")
+	tail.Close()
+
+	expected := "reconcile failed\n(last message repeated 2 times)\nsynced\n(last message repeated once)\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, but actual %q", expected, out.String())
+	}
+
+	// the collapsed lines are counted to resume
+	if resume := tail.GetResumeRequest(); resume.Timestamp != "2025-01-01T12:00:02Z" || resume.LinesToSkip != 1 {
+		t.Errorf("unexpected resume request %+v", resume)
+	}
+}
+
+func TestConsumeLineRepeatsOTel(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", nil, io.Discard, io.Discard, &TailOptions{RepeatWindow: time.Hour}, false, &otel.Exporter{}, true)
+	var emitted []*otel.LogRecord
+	tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {
+		emitted = append(emitted, record)
+	})
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		tail.consumeLine(ctx, "2025-01-01T12:00:00.000000001Z reconcile failed")
+	}
+	tail.consumeLine(ctx, "2025-01-01T12:00:01.000000001Z synced")
+
+	if len(emitted) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(emitted))
+	}
+	for i, want := range []struct {
+		body   string
+		repeat int64
+	}{{"reconcile failed", 0}, {"reconcile failed", 2}, {"synced", 0}} {
+		if emitted[i].Body != want.body || emitted[i].RepeatCount != want.repeat {
+			t.Errorf("%d: expected %q repeated %d times, got %q repeated %d times", i, want.body, want.repeat, emitted[i].Body, emitted[i].RepeatCount)
+		}
+	}
+}
+
+func TestConsumeLineRepeatsWindowConcurrent(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tmpl := template.Must(template.New("").Parse(`{{printf "%s\n" .Message}}`))
+	out := new(bytes.Buffer)
+	options := &TailOptions{
+		RepeatWindow:        time.Millisecond,
+		MultilineStart:      regexp.MustCompile(`^\S`),
+		MultilineTimeout:    time.Millisecond,
+		MonotonicTimestamps: true,
+		LineNumbers:         true,
+	}
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, out, io.Discard, options, false, &otel.Exporter{}, true)
+	tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {})
+
+	// The windows expire on the goroutines of the timers while the lines
+	// are consumed, run with -race
+	ctx := context.Background()
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 200; i++ {
+		tail.consumeLine(ctx, base.Add(time.Duration(i)*time.Millisecond).Format(time.RFC3339Nano)+" reconcile failed")
+		if i%10 == 0 {
+			time.Sleep(2 * time.Millisecond)
+		}
+	}
+	tail.Close()
+
+	if resume := tail.GetResumeRequest(); resume == nil || resume.LastEmitted.IsZero() {
+		t.Errorf("expected records to be emitted, got %+v", resume)
+	}
+	if !strings.Contains(out.String(), "(last message repeated") {
+		t.Errorf("expected the repeats to be reported, got %q", out.String())
+	}
+}
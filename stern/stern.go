@@ -29,6 +29,7 @@ import (
 	"sync/atomic"
 
 	"github.com/pkg/errors"
+	"github.com/stern/stern/stern/otel"
 
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/time/rate"
@@ -42,17 +43,64 @@ import (
 
 // Run starts the main run loop
 func Run(ctx context.Context, client kubernetes.Interface, config *Config) error {
+	// Tails of the follow mode, outliving the watch loops
+	var tails sync.WaitGroup
 	// Ensure OTel exporter is shut down gracefully
 	if config.OTelEnabled && config.OTelExporter != nil {
 		defer func() {
-			shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			// Drain once the tails, cancelled with ctx, emitted their last records
+			tails.Wait()
+			// A single deadline bounds the whole exit, the drain and the
+			// shutdown, so that an unreachable collector delays it only once
+			exitCtx, cancel := context.WithTimeout(context.Background(), config.OTelExporter.ShutdownTimeout())
 			defer cancel()
-			if err := config.OTelExporter.Shutdown(shutdownCtx); err != nil {
+			result, err := config.OTelExporter.Drain(exitCtx)
+			if err != nil {
+				fmt.Fprintf(config.ErrOut, "failed to drain OTel exporter: %v\n", err)
+			}
+			if result.Dropped > 0 {
+				fmt.Fprintf(config.ErrOut, "OTel exporter flushed %d of %d pending records\n", result.Flushed, result.Flushed+result.Dropped)
+			}
+			if err := config.OTelExporter.Shutdown(exitCtx); err != nil {
 				fmt.Fprintf(config.ErrOut, "failed to shutdown OTel exporter: %v\n", err)
 			}
+			// Report from a single snapshot so that the numbers agree
+			stats := config.OTelExporter.Stats()
+			if stats.Dropped > 0 {
+				fmt.Fprintf(config.ErrOut, "OTel exporter dropped %d of %d records on a full queue or once draining, consider raising --otel-max-queue-size\n", stats.Dropped, stats.Emitted)
+			}
+			if stats.Throttled > 0 {
+				fmt.Fprintf(config.ErrOut, "OTel collector throttled %d exports\n", stats.Throttled)
+			}
+			if stats.Fallback > 0 {
+				fmt.Fprintf(config.ErrOut, "OTel exporter wrote %d records to the fallback file\n", stats.Fallback)
+			}
+			if stats.Replayed > 0 {
+				fmt.Fprintf(config.ErrOut, "OTel exporter replayed %d records after failed exports\n", stats.Replayed)
+			}
+			if stats.Buffered+stats.Overflowed > 0 {
+				fmt.Fprintf(config.ErrOut, "OTel exporter lost %d records of the replay buffer, %d of them on a full buffer, consider raising --otel-replay-buffer\n", stats.Buffered+stats.Overflowed, stats.Overflowed)
+			}
 		}()
 	}
 
+	// Keep floods of diagnostic messages from obscuring the output
+	diagOut := config.ErrOut
+	if config.DiagnosticsRate > 0 {
+		limited := newRateLimitedWriter(config.ErrOut, config.DiagnosticsRate)
+		defer limited.Flush()
+		diagOut = limited
+	}
+
+	// Tell a filter matching nothing apart from a silent application
+	var stats *filterStats
+	if config.NoMatchInterval > 0 {
+		stats = &filterStats{}
+		statsCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		stats.start(statsCtx, diagOut, config.NoMatchInterval)
+	}
+
 	var namespaces []string
 	// A specific namespace is ignored if all-namespaces is provided
 	if config.AllNamespaces {
@@ -66,25 +114,85 @@ func Run(ctx context.Context, client kubernetes.Interface, config *Config) error
 
 	newTailOptions := func() *TailOptions {
 		return &TailOptions{
-			Timestamps:      config.Timestamps,
-			TimestampFormat: config.TimestampFormat,
-			Location:        config.Location,
-			SinceSeconds:    ptr.To[int64](int64(config.Since.Seconds())),
-			Exclude:         config.Exclude,
-			Include:         config.Include,
-			Highlight:       config.Highlight,
-			Namespace:       config.AllNamespaces || len(namespaces) > 1,
-			TailLines:       config.TailLines,
-			Follow:          config.Follow,
-			OnlyLogLines:    config.OnlyLogLines,
+			Timestamps:          config.Timestamps,
+			TimestampFormat:     config.TimestampFormat,
+			Location:            config.Location,
+			SinceSeconds:        ptr.To[int64](int64(config.Since.Seconds())),
+			Exclude:             config.Exclude,
+			Include:             config.Include,
+			Highlight:           config.Highlight,
+			Namespace:           config.AllNamespaces || len(namespaces) > 1,
+			TailLines:           config.TailLines,
+			Follow:              config.Follow,
+			Previous:            config.Previous,
+			OnlyLogLines:        config.OnlyLogLines,
+			EmitMatches:         config.OTelEmitMatches,
+			MonotonicTimestamps: config.OTelMonotonic,
+			SeverityColors:      config.SeverityColors,
+			HeartbeatInterval:   config.OTelHeartbeat,
+			MaxLinesToSkip:      config.MaxResumeLines,
+			StripTimestamps:     config.StripTimestamps,
+			RepeatWindow:        config.RepeatWindow,
+			LineNumbers:         config.OTelLineNumbers,
+			Quiet:               config.OTelQuiet,
+			OTelJSON:            config.OTelJSON,
+
+			MultilineStart:           config.OTelMultilineStart,
+			MultilineContinue:        config.OTelMultilineContinue,
+			MultilineTimeout:         config.OTelMultilineTimeout,
+			MultilinePreserveNewline: config.OTelMultilinePreserveNewline,
+		}
+	}
+	// Merge the OTel records of the containers of a pod in timestamp order
+	var orderers *podOrdererRegistry
+	if config.OTelEnabled && config.OTelExporter != nil && config.OTelOrderWindow > 0 {
+		orderers = newPodOrdererRegistry(config.OTelOrderWindow, func(record *otel.LogRecord) {
+			config.OTelExporter.Emit(context.Background(), record)
+		})
+	}
+	// Resolve the workloads owning the pods for the OTel attributes
+	var owners *ownerResolver
+	if config.OTelEnabled || config.OTelJSON != nil {
+		owners = newOwnerResolver(client)
+	}
+	// Count the tailed containers for the scope of the session, and the
+	// distinct ones for the summary since reconnects tail a container again
+	var tailCount *tailCounter
+	var tailedTargets sync.Map
+	if config.OTelEnabled {
+		tailCount = &tailCounter{}
+		if !config.OTelQuiet && !config.OnlyLogLines && !config.Stdin {
+			defer func() {
+				var tailed int
+				tailedTargets.Range(func(_, _ any) bool {
+					tailed++
+					return true
+				})
+				fmt.Fprintf(config.ErrOut, "tailed %d containers\n", tailed)
+			}()
 		}
 	}
 	newTail := func(t *Target) *Tail {
-		return NewTail(client.CoreV1(), t.Pod, t.Container, config.Template, config.Out, config.ErrOut, newTailOptions(), config.DiffContainer, config.OTelExporter, config.OTelEnabled)
+		tail := NewTail(client.CoreV1(), t.Pod, t.Container, config.Template, config.Out, config.ErrOut, newTailOptions(), config.DiffContainer, config.OTelExporter, config.OTelEnabled)
+		tail.diagOut = diagOut
+		tail.filterStats = stats
+		tail.tailCounter = tailCount
+		if tailCount != nil {
+			tailedTargets.Store(t.GetID(), struct{}{})
+		}
+		if orderers != nil {
+			tail.orderer = orderers.acquire(t.Pod)
+		}
+		if owners != nil {
+			tail.owners = owners.resolve(ctx, t.Pod)
+		}
+		return tail
 	}
 
 	if config.Stdin {
 		tail := NewFileTail(config.Template, os.Stdin, config.Out, config.ErrOut, newTailOptions())
+		tail.diagOut = diagOut
+		tail.filterStats = stats
 		return tail.Start()
 	}
 
@@ -157,7 +265,7 @@ func Run(ctx context.Context, client kubernetes.Interface, config *Config) error
 		var resumeRequest *ResumeRequest
 		for {
 			if err := limiter.Wait(ctx); err != nil {
-				fmt.Fprintf(config.ErrOut, "failed to retry: %v\n", err)
+				fmt.Fprintf(diagOut, "failed to retry: %v\n", err)
 				return
 			}
 			tail := newTail(target)
@@ -172,16 +280,20 @@ func Run(ctx context.Context, client kubernetes.Interface, config *Config) error
 				return
 			}
 			if !filter.isActive(target) {
-				fmt.Fprintf(config.ErrOut, "failed to tail: %v\n", err)
+				fmt.Fprintf(diagOut, "failed to tail: %v\n", err)
 				return
 			}
-			fmt.Fprintf(config.ErrOut, "failed to tail: %v, will retry\n", err)
+			fmt.Fprintf(diagOut, "failed to tail: %v, will retry\n", err)
 			if resumeReq := tail.GetResumeRequest(); resumeReq != nil {
 				resumeRequest = resumeReq
 			}
 		}
 	}
 
+	// Stop the tails however Run returns, since the OTel drain waits for them
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
 	cancelMap := sync.Map{}
 	eg, nctx := errgroup.WithContext(ctx)
 	var numRequests atomic.Int64
@@ -216,7 +328,9 @@ func Run(ctx context.Context, client kubernetes.Interface, config *Config) error
 					}
 					ctx, cancel := context.WithCancel(nctx)
 					cancelMap.Store(target.GetID(), cancel)
+					tails.Add(1)
 					go func() {
+						defer tails.Done()
 						tailTarget(ctx, target)
 						numRequests.Add(-1)
 						cancel()
@@ -25,7 +25,10 @@ import (
 	"fmt"
 	"hash/fnv"
 	"io"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"text/template"
 	"time"
 	"unicode"
@@ -58,24 +61,66 @@ type Tail struct {
 	last           struct {
 		timestamp string // RFC3339 timestamp (not RFC3339Nano)
 		lines     int    // the number of lines seen during this timestamp
+		nano      string // RFC3339Nano timestamp of the last line
+		nanoLines int    // the number of lines seen during the nano timestamp
+		lineNo    int64  // the number of the last line matching the filters
+
+		emitted    time.Time // the timestamp of the last record emitted to OTel
+		outOfOrder int       // the number of OTel records dropped for going backward
 	}
 	resumeRequest *ResumeRequest
 	out           io.Writer
 	errOut        io.Writer
+	diagOut       io.Writer // diagnostic messages, possibly rate-limited
 	otelExporter  *otel.Exporter
 	otelEnabled   bool
+	orderer       *podOrderer
+	owners        []otel.Owner
+	filterStats   *filterStats
+	qosClass      string // the pod's QoS class when the tail was built
+	priority      *int32 // the pod's priority when the tail was built
+	image         string // the container's image, empty until it has a status
+	imageID       string // the container's image ID, empty until it has a status
+	restartCount  *int32 // the container's restart count, nil until it has a status
+	tailCounter   *tailCounter
+	countState    atomic.Int32     // whether the tail is counted by tailCounter, one of the tailCount* states
+	multiline     *multilineJoiner // nil unless multiline joining is enabled
+	repeats       *repeatCollapser // nil unless repeats are collapsed
+
+	// mu serializes the lines consumed with the records flushed by the
+	// timers of multiline and repeats, which share last and out
+	mu sync.Mutex
+
+	heartbeatMu sync.Mutex
+	heartbeat   *time.Timer // nil unless heartbeats are running
+	lastSeen    time.Time   // when the last line arrived
 }
 
+// States of the count of a Tail by its tailCounter. A Tail closed before it
+// starts is never counted.
+const (
+	tailCountNone   int32 = iota // not started yet
+	tailCounted                  // started and not closed yet
+	tailCountClosed              // closed
+)
+
 type ResumeRequest struct {
 	Timestamp   string // RFC3339 timestamp (not RFC3339Nano)
 	LinesToSkip int    // the number of lines to skip during this timestamp
+	// NanoTimestamp, when set, keys the resume by the RFC3339Nano timestamp
+	// of the last line instead: the lines of Timestamp before it are skipped,
+	// then LinesToSkip lines of NanoTimestamp
+	NanoTimestamp string
+
+	LastEmitted time.Time // the timestamp of the last record emitted to OTel
+	OutOfOrder  int       // the number of OTel records dropped for going backward
 }
 
 // NewTail returns a new tail for a Kubernetes container inside a pod
 func NewTail(clientset corev1client.CoreV1Interface, pod *corev1.Pod, containerName string, tmpl *template.Template, out, errOut io.Writer, options *TailOptions, diffContainer bool, otelExporter *otel.Exporter, otelEnabled bool) *Tail {
 	podColor, containerColor := determineColor(pod.Name, containerName, diffContainer)
 
-	return &Tail{
+	t := &Tail{
 		clientset:      clientset,
 		Pod:            pod,
 		ContainerName:  containerName,
@@ -87,17 +132,60 @@ func NewTail(clientset corev1client.CoreV1Interface, pod *corev1.Pod, containerN
 
 		out:          out,
 		errOut:       errOut,
+		diagOut:      errOut,
 		otelExporter: otelExporter,
 		otelEnabled:  otelEnabled,
+		qosClass:     string(pod.Status.QOSClass),
+		priority:     pod.Spec.Priority,
+	}
+	if status := findContainerStatus(pod, containerName); status != nil {
+		t.image = status.Image
+		t.imageID = status.ImageID
+		t.restartCount = &status.RestartCount
+	}
+	if otelEnabled && (options.MultilineStart != nil || options.MultilineContinue != nil) {
+		t.multiline = &multilineJoiner{
+			start:           options.MultilineStart,
+			cont:            options.MultilineContinue,
+			timeout:         options.MultilineTimeout,
+			preserveNewline: options.MultilinePreserveNewline,
+			emit:            t.emitOTelRecord,
+			locker:          &t.mu,
+		}
+	}
+	if options.RepeatWindow > 0 {
+		t.repeats = &repeatCollapser{window: options.RepeatWindow, flush: t.printRepeats, locker: &t.mu}
 	}
+	return t
+}
+
+// findContainerStatus returns the status of the container of the pod, or nil
+// when the kubelet has not reported it yet
+func findContainerStatus(pod *corev1.Pod, containerName string) *corev1.ContainerStatus {
+	for _, statuses := range [][]corev1.ContainerStatus{
+		pod.Status.ContainerStatuses,
+		pod.Status.InitContainerStatuses,
+		pod.Status.EphemeralContainerStatuses,
+	} {
+		for i := range statuses {
+			if statuses[i].Name == containerName {
+				return &statuses[i]
+			}
+		}
+	}
+	return nil
 }
 
 func determineColor(podName, containerName string, diffContainer bool) (podColor, containerColor *color.Color) {
 	colors := colorList[colorIndex(podName)]
+	podColor, containerColor = colors[0], colors[1]
 	if diffContainer {
-		return colors[0], colorList[colorIndex(containerName)][1]
+		containerColor = colorList[colorIndex(containerName)][1]
+	}
+	if c := containerColorOverrideOf(containerName); c != nil {
+		containerColor = c
 	}
-	return colors[0], colors[1]
+	return podColor, containerColor
 }
 
 func colorIndex(name string) uint32 {
@@ -115,9 +203,14 @@ func (t *Tail) Start(ctx context.Context) error {
 	}()
 
 	t.printStarting()
+	t.startHeartbeat()
+	if t.countState.CompareAndSwap(tailCountNone, tailCounted) {
+		t.tailCounter.started()
+	}
 
 	req := t.clientset.Pods(t.Pod.Namespace).GetLogs(t.Pod.Name, &corev1.PodLogOptions{
 		Follow:       t.Options.Follow,
+		Previous:     t.Options.Previous,
 		Timestamps:   true,
 		Container:    t.ContainerName,
 		SinceSeconds: t.Options.SinceSeconds,
@@ -135,12 +228,17 @@ func (t *Tail) Start(ctx context.Context) error {
 }
 
 func (t *Tail) Resume(ctx context.Context, resumeRequest *ResumeRequest) error {
-	sinceTime, err := resumeRequest.sinceTime()
+	sinceTime, clamped, err := resumeRequest.sinceTime()
 	if err != nil {
-		fmt.Fprintf(t.errOut, "failed to resume: %s, fallback to Start()\n", err)
+		fmt.Fprintf(t.diagOut, "failed to resume: %s, fallback to Start()\n", err)
 		return t.Start(ctx)
 	}
+	if clamped {
+		fmt.Fprintf(t.diagOut, "resume timestamp %s is in the future, resuming from now\n", resumeRequest.Timestamp)
+	}
 	t.resumeRequest = resumeRequest
+	t.last.emitted = resumeRequest.LastEmitted
+	t.last.outOfOrder = resumeRequest.OutOfOrder
 	t.Options.SinceTime = sinceTime
 	t.Options.SinceSeconds = nil
 	t.Options.TailLines = nil
@@ -150,11 +248,32 @@ func (t *Tail) Resume(ctx context.Context, resumeRequest *ResumeRequest) error {
 // Close stops tailing
 func (t *Tail) Close() {
 	t.printStopping()
+	t.stopHeartbeat()
+	if t.countState.Swap(tailCountClosed) == tailCounted {
+		t.tailCounter.stopped()
+	}
+
+	t.mu.Lock()
+	if t.repeats != nil {
+		t.repeats.close()
+	}
+	if t.multiline != nil {
+		t.multiline.close()
+	}
+	t.mu.Unlock()
+	if t.orderer != nil {
+		t.orderer.releaseRef()
+	}
 
 	close(t.closed)
 }
 
 func (t *Tail) printStarting() {
+	// Confirm the containers tailed to OTel without the colored lines
+	if t.otelEnabled && !t.Options.OnlyLogLines && !t.Options.Quiet {
+		fmt.Fprintf(t.errOut, "tailing %s/%s/%s\n", t.Pod.Namespace, t.Pod.Name, t.ContainerName)
+		return
+	}
 	if !t.Options.OnlyLogLines && !t.otelEnabled {
 		g := color.New(color.FgHiGreen, color.Bold).SprintFunc()
 		p := t.podColor.SprintFunc()
@@ -193,7 +312,7 @@ func (t *Tail) ConsumeRequest(ctx context.Context, request rest.ResponseWrapper)
 	for {
 		line, err := r.ReadBytes('\n')
 		if len(line) != 0 {
-			t.consumeLine(strings.TrimSuffix(string(line), "\n"))
+			t.consumeLine(ctx, strings.TrimSuffix(string(line), "\n"))
 		}
 
 		if err != nil {
@@ -228,20 +347,25 @@ func (t *Tail) sprint(msg string) (string, error) {
 
 // Print prints a color coded log message with the pod and container names
 func (t *Tail) Print(msg string) {
+	t.printColored(msg, nil)
+}
+
+// printColored prints a log message like Print, coloring the whole line with c
+func (t *Tail) printColored(msg string, c *color.Color) {
 	buf, err := t.sprint(msg)
 	if err != nil {
-		fmt.Fprintf(t.errOut, "%s\n", err)
+		fmt.Fprintf(t.diagOut, "%s\n", err)
 		return
 	}
 
-	fmt.Fprint(t.out, t.Options.HighlightMatchedString(buf))
+	fmt.Fprint(t.out, colorLine(t.Options.HighlightMatchedString(buf), c))
 }
 
 // PrintWithoutHighlight prints a log message without applying any highlight.
 func (t *Tail) PrintWithoutHighlight(msg string) {
 	buf, err := t.sprint(msg)
 	if err != nil {
-		fmt.Fprintf(t.errOut, "%s\n", err)
+		fmt.Fprintf(t.diagOut, "%s\n", err)
 		return
 	}
 
@@ -249,42 +373,83 @@ func (t *Tail) PrintWithoutHighlight(msg string) {
 }
 
 func (t *Tail) GetResumeRequest() *ResumeRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
 	if t.last.timestamp == "" {
 		return nil
 	}
-	return &ResumeRequest{Timestamp: t.last.timestamp, LinesToSkip: t.last.lines}
+	resumeRequest := &ResumeRequest{
+		Timestamp:   t.last.timestamp,
+		LinesToSkip: t.last.lines,
+		LastEmitted: t.last.emitted,
+		OutOfOrder:  t.last.outOfOrder,
+	}
+	// Bound the lines counted during a burst by keying on the nano timestamp
+	if limit := t.Options.MaxLinesToSkip; limit > 0 && t.last.lines > limit {
+		resumeRequest.NanoTimestamp = t.last.nano
+		resumeRequest.LinesToSkip = t.last.nanoLines
+	}
+	return resumeRequest
 }
 
-func (t *Tail) consumeLine(line string) {
+func (t *Tail) consumeLine(ctx context.Context, line string) {
+	t.resetHeartbeat()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
 	rfc3339Nano, content, err := splitLogLine(line)
+	var timestamp time.Time
+	if err == nil {
+		timestamp, err = time.Parse(time.RFC3339Nano, rfc3339Nano)
+	}
 	if err != nil {
-		t.PrintWithoutHighlight(fmt.Sprintf("[%v] %s", err, line))
-		return
+		// The line was not prefixed by the kubelet, e.g. written to the
+		// stream by a sidecar: take the timestamp leading its content, or
+		// now. It is not counted to resume.
+		content = line
+		timestamp = contentTimestamp(line)
+		rfc3339Nano = timestamp.Format(time.RFC3339Nano)
+	} else {
+		content = stripTimestamps(content, t.Options.StripTimestamps)
+
+		// PodLogOptions.SinceTime is RFC3339, not RFC3339Nano.
+		// We convert it to RFC3339 to skip the lines seen during this timestamp when resuming.
+		rfc3339 := removeSubsecond(rfc3339Nano)
+		t.rememberLastTimestamp(rfc3339, rfc3339Nano)
+		if t.resumeRequest.shouldSkip(rfc3339, rfc3339Nano) {
+			return
+		}
 	}
 
-	// PodLogOptions.SinceTime is RFC3339, not RFC3339Nano.
-	// We convert it to RFC3339 to skip the lines seen during this timestamp when resuming.
-	rfc3339 := removeSubsecond(rfc3339Nano)
-	t.rememberLastTimestamp(rfc3339)
-	if t.resumeRequest.shouldSkip(rfc3339) {
+	matched := !t.Options.IsExclude(content) && t.Options.IsInclude(content)
+	t.filterStats.record(matched)
+	if !matched {
 		return
 	}
 
-	if t.Options.IsExclude(content) || !t.Options.IsInclude(content) {
+	if t.repeats != nil && t.repeats.collapse(ctx, content, timestamp) {
 		return
 	}
 
-	// Parse timestamp for OTel
-	timestamp, parseErr := time.Parse(time.RFC3339Nano, rfc3339Nano)
-	if parseErr != nil {
-		timestamp = time.Now()
-	}
+	t.last.lineNo++
 
-	// Emit to OpenTelemetry if enabled
+	// Emit to OpenTelemetry if enabled, in the location of the printed
+	// timestamps. The exported instant is the same.
+	timestamp = t.Options.inLocation(timestamp)
 	if t.otelEnabled && t.otelExporter != nil {
-		t.emitOTelLog(content, timestamp)
+		t.emitOTelLog(ctx, content, timestamp)
 	}
 
+	// Print the line in the shape of its OTel record
+	if t.Options.OTelJSON != nil {
+		t.printOTelJSON(content, timestamp)
+		return
+	}
+
+	// Determine the severity before the timestamp is prepended
+	severityColor := t.Options.SeverityColor(content)
+
 	if t.Options.Timestamps {
 		updatedTs, err := t.Options.UpdateTimezoneAndFormat(rfc3339Nano)
 		if err != nil {
@@ -294,29 +459,222 @@ func (t *Tail) consumeLine(line string) {
 		content = updatedTs + " " + content
 	}
 
-	// Only print to stdout if not in OTel-only mode
-	if !t.otelEnabled {
+	// Number the lines to correlate stdout with the OTel records
+	if t.Options.LineNumbers {
+		content = strconv.FormatInt(t.last.lineNo, 10) + " " + content
+	}
+
+	// Only print to stdout if not in OTel-only mode, unless debugging
+	if !t.otelEnabled || t.Options.LineNumbers {
+		t.printColored(content, severityColor)
+	}
+}
+
+// emitOTelLog sends a log record to OpenTelemetry, joining the lines of
+// multiline logs when enabled. ctx is the context of the tail, cancelled
+// when it is closed.
+func (t *Tail) emitOTelLog(ctx context.Context, message string, timestamp time.Time) {
+	record := t.newOTelRecord(message, timestamp)
+	if t.multiline != nil {
+		t.multiline.add(ctx, record)
+		return
+	}
+	t.emitOTelRecord(ctx, record)
+}
+
+// printRepeats reports the repeats of a line collapsed by t.repeats, as a
+// record with their count on the OTel outputs
+func (t *Tail) printRepeats(ctx context.Context, message string, timestamp time.Time, count int64) {
+	timestamp = t.Options.inLocation(timestamp)
+	if t.otelEnabled && t.otelExporter != nil {
+		// Keep the repeats after the joined record of the line, if any
+		if t.multiline != nil {
+			t.multiline.flush()
+		}
+		record := t.newOTelRecord(message, timestamp)
+		record.RepeatCount = count
+		t.emitOTelRecord(ctx, record)
+	}
+
+	if t.Options.OTelJSON != nil {
+		record := t.newOTelRecord(message, timestamp)
+		record.RepeatCount = count
+		t.printOTelRecordJSON(record)
+		return
+	}
+
+	content := fmt.Sprintf("(last message repeated %d times)", count)
+	if count == 1 {
+		content = "(last message repeated once)"
+	}
+	if t.Options.Timestamps {
+		if updatedTs, err := t.Options.UpdateTimezoneAndFormat(timestamp.Format(time.RFC3339Nano)); err == nil {
+			content = updatedTs + " " + content
+		}
+	}
+	if !t.otelEnabled || t.Options.LineNumbers {
 		t.Print(content)
 	}
 }
 
-// emitOTelLog sends a log record to OpenTelemetry
-func (t *Tail) emitOTelLog(message string, timestamp time.Time) {
+// printOTelJSON prints the OTel record of a line as a JSON object
+func (t *Tail) printOTelJSON(message string, timestamp time.Time) {
+	t.printOTelRecordJSON(t.newOTelRecord(message, timestamp))
+}
+
+// printOTelRecordJSON prints the OTel record as a JSON object
+func (t *Tail) printOTelRecordJSON(record *otel.LogRecord) {
+	if t.Options.EmitMatches {
+		record.Matches = t.Options.MatchedStrings(record.Body)
+	}
+	data, err := t.Options.OTelJSON.Encode(record)
+	if err != nil {
+		fmt.Fprintf(t.diagOut, "failed to encode OTel record: %s\n", err)
+		return
+	}
+	if data == nil {
+		return
+	}
+	fmt.Fprintf(t.out, "%s\n", data)
+}
+
+// newOTelRecord returns the OTel record of a line
+func (t *Tail) newOTelRecord(message string, timestamp time.Time) *otel.LogRecord {
 	record := &otel.LogRecord{
-		Timestamp:     timestamp,
-		Body:          message,
-		Namespace:     t.Pod.Namespace,
-		PodName:       t.Pod.Name,
-		ContainerName: t.ContainerName,
-		NodeName:      t.Pod.Spec.NodeName,
-		Labels:        t.Pod.Labels,
-		Annotations:   t.Pod.Annotations,
+		Timestamp:        timestamp,
+		Body:             message,
+		Namespace:        t.Pod.Namespace,
+		PodName:          t.Pod.Name,
+		PodUID:           string(t.Pod.UID),
+		ContainerName:    t.ContainerName,
+		NodeName:         t.Pod.Spec.NodeName,
+		Labels:           t.Pod.Labels,
+		Annotations:      t.Pod.Annotations,
+		LineIndex:        t.last.lines,
+		Owners:           t.owners,
+		QOSClass:         t.qosClass,
+		Priority:         t.priority,
+		TailedContainers: t.tailCounter.count(),
+		Image:            t.image,
+		ImageID:          t.imageID,
+		RestartCount:     t.restartCount,
+		Previous:         t.Options.Previous,
+	}
+	if t.Options.LineNumbers {
+		record.LineNumber = t.last.lineNo
+	}
+	return record
+}
+
+// emitOTelRecord sends the record of one or more lines to OpenTelemetry
+func (t *Tail) emitOTelRecord(ctx context.Context, record *otel.LogRecord) {
+	// Keep the records of the container monotonic, also across a resume
+	if t.Options.MonotonicTimestamps && record.Timestamp.Before(t.last.emitted) {
+		t.last.outOfOrder++
+		fmt.Fprintf(t.diagOut, "dropped out-of-order OTel record of %s/%s/%s at %s, before %s (%d dropped)\n",
+			t.Pod.Namespace, t.Pod.Name, t.ContainerName,
+			record.Timestamp.Format(time.RFC3339Nano), t.last.emitted.Format(time.RFC3339Nano), t.last.outOfOrder)
+		return
+	}
+	if record.Timestamp.After(t.last.emitted) {
+		t.last.emitted = record.Timestamp
+	}
+
+	if t.Options.EmitMatches {
+		record.Matches = t.Options.MatchedStrings(record.Body)
+	}
+
+	if t.orderer != nil {
+		t.orderer.add(record)
+		return
 	}
 
-	otel.EmitLog(context.Background(), t.otelExporter.Logger(), record)
+	t.otelExporter.Emit(ctx, record)
 }
 
-func (t *Tail) rememberLastTimestamp(timestamp string) {
+// startHeartbeat starts emitting heartbeats when the container is silent
+func (t *Tail) startHeartbeat() {
+	if !t.otelEnabled || t.otelExporter == nil || t.Options.HeartbeatInterval <= 0 {
+		return
+	}
+	t.heartbeatMu.Lock()
+	defer t.heartbeatMu.Unlock()
+	if t.heartbeat != nil {
+		return
+	}
+	t.lastSeen = time.Now()
+	t.heartbeat = time.AfterFunc(t.Options.HeartbeatInterval, t.emitHeartbeat)
+}
+
+// resetHeartbeat records a line and restarts the idle interval
+func (t *Tail) resetHeartbeat() {
+	t.heartbeatMu.Lock()
+	defer t.heartbeatMu.Unlock()
+	if t.heartbeat == nil {
+		return
+	}
+	t.lastSeen = time.Now()
+	t.heartbeat.Reset(t.Options.HeartbeatInterval)
+}
+
+// stopHeartbeat stops the heartbeats; none is emitted once it returns
+func (t *Tail) stopHeartbeat() {
+	t.heartbeatMu.Lock()
+	defer t.heartbeatMu.Unlock()
+	if t.heartbeat == nil {
+		return
+	}
+	t.heartbeat.Stop()
+	t.heartbeat = nil
+}
+
+// emitHeartbeat emits a stern.heartbeat record and waits for another interval
+func (t *Tail) emitHeartbeat() {
+	t.heartbeatMu.Lock()
+	defer t.heartbeatMu.Unlock()
+	if t.heartbeat == nil {
+		return
+	}
+
+	now := t.Options.inLocation(time.Now())
+	record := &otel.LogRecord{
+		Timestamp:        now,
+		Body:             fmt.Sprintf("no logs for %s", now.Sub(t.lastSeen).Round(time.Second)),
+		Namespace:        t.Pod.Namespace,
+		PodName:          t.Pod.Name,
+		PodUID:           string(t.Pod.UID),
+		ContainerName:    t.ContainerName,
+		NodeName:         t.Pod.Spec.NodeName,
+		Labels:           t.Pod.Labels,
+		Annotations:      t.Pod.Annotations,
+		Owners:           t.owners,
+		QOSClass:         t.qosClass,
+		Priority:         t.priority,
+		TailedContainers: t.tailCounter.count(),
+		Image:            t.image,
+		ImageID:          t.imageID,
+		RestartCount:     t.restartCount,
+		Heartbeat:        true,
+		LastSeen:         t.lastSeen,
+	}
+	// Heartbeats bypass the monotonic guard so that they do not hold back
+	// the lines that follow them
+	if t.orderer != nil {
+		t.orderer.add(record)
+	} else {
+		t.otelExporter.Emit(context.Background(), record)
+	}
+
+	t.heartbeat.Reset(t.Options.HeartbeatInterval)
+}
+
+func (t *Tail) rememberLastTimestamp(timestamp, nano string) {
+	if t.last.nano == nano {
+		t.last.nanoLines++
+	} else {
+		t.last.nano = nano
+		t.last.nanoLines = 1
+	}
 	if t.last.timestamp == timestamp {
 		t.last.lines++
 		return
@@ -325,17 +683,22 @@ func (t *Tail) rememberLastTimestamp(timestamp string) {
 	t.last.lines = 1
 }
 
-func (r *ResumeRequest) sinceTime() (*metav1.Time, error) {
+// sinceTime returns the time to resume from. A timestamp in the future,
+// e.g. because of clock skew between the node and stern, is clamped to now.
+func (r *ResumeRequest) sinceTime() (since *metav1.Time, clamped bool, err error) {
 	sinceTime, err := time.Parse(time.RFC3339, r.Timestamp)
 
 	if err != nil {
-		return nil, err
+		return nil, false, err
+	}
+	if now := time.Now(); sinceTime.After(now) {
+		sinceTime, clamped = now, true
 	}
 	metaTime := metav1.NewTime(sinceTime)
-	return &metaTime, nil
+	return &metaTime, clamped, nil
 }
 
-func (r *ResumeRequest) shouldSkip(timestamp string) bool {
+func (r *ResumeRequest) shouldSkip(timestamp, nano string) bool {
 	if r == nil {
 		return false
 	}
@@ -345,6 +708,15 @@ func (r *ResumeRequest) shouldSkip(timestamp string) bool {
 	if r.Timestamp != timestamp {
 		return false
 	}
+	if r.NanoTimestamp != "" && r.NanoTimestamp != nano {
+		// The lines of the second before the last one were all seen
+		last, err := time.Parse(time.RFC3339Nano, r.NanoTimestamp)
+		if err != nil {
+			return false
+		}
+		current, err := time.Parse(time.RFC3339Nano, nano)
+		return err == nil && current.Before(last)
+	}
 	if r.LinesToSkip <= 0 {
 		return false
 	}
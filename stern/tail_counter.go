@@ -0,0 +1,47 @@
+//   Copyright 2025 Robert B Gordon <rbg@openrbg.com>
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package stern
+
+import "sync/atomic"
+
+// tailCounter counts the containers tailed by the session, shared by all
+// the Tails so that their records carry the scope of the session
+type tailCounter struct {
+	active atomic.Int64
+}
+
+// started counts a Tail that started tailing
+func (c *tailCounter) started() {
+	if c == nil {
+		return
+	}
+	c.active.Add(1)
+}
+
+// stopped uncounts a Tail that stopped tailing
+func (c *tailCounter) stopped() {
+	if c == nil {
+		return
+	}
+	c.active.Add(-1)
+}
+
+// count returns the number of containers being tailed, 0 when not counting
+func (c *tailCounter) count() int64 {
+	if c == nil {
+		return 0
+	}
+	return c.active.Load()
+}
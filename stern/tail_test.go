@@ -3,17 +3,27 @@ package stern
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/fatih/color"
 	"io"
+	"net/http"
 	"reflect"
 	"regexp"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"text/template"
+	"time"
 
+	"github.com/stern/stern/stern/otel"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	fakerest "k8s.io/client-go/rest/fake"
 )
 
 func TestDetermineColor(t *testing.T) {
@@ -320,6 +330,29 @@ func TestPrintStarting(t *testing.T) {
 	}
 }
 
+func TestPrintStartingOTel(t *testing.T) {
+	tests := []struct {
+		options  *TailOptions
+		expected string
+	}{
+		{&TailOptions{}, "tailing my-namespace/my-pod/my-container\n"},
+		{&TailOptions{Quiet: true}, ""},
+		{&TailOptions{OnlyLogLines: true}, ""},
+	}
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	for i, tt := range tests {
+		errOut := new(bytes.Buffer)
+		tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", nil, io.Discard, errOut, tt.options, false, &otel.Exporter{}, true)
+		tail.printStarting()
+		tail.printStopping()
+
+		if errOut.String() != tt.expected {
+			t.Errorf("%d: expected %q, but actual %q", i, tt.expected, errOut)
+		}
+	}
+}
+
 func TestPrintStopping(t *testing.T) {
 	tests := []struct {
 		options  *TailOptions
@@ -393,7 +426,7 @@ func TestResumeRequestShouldSkip(t *testing.T) {
 	for _, tt := range tests {
 		var actual []bool
 		for _, ts := range tt.timestamps {
-			actual = append(actual, tt.rr.shouldSkip(ts))
+			actual = append(actual, tt.rr.shouldSkip(ts, ts))
 		}
 		if !reflect.DeepEqual(tt.expected, actual) {
 			t.Errorf("expected %v, but actual %v", tt.expected, actual)
@@ -401,6 +434,117 @@ func TestResumeRequestShouldSkip(t *testing.T) {
 	}
 }
 
+func TestResumeRequestMaxLinesToSkip(t *testing.T) {
+	burst := []string{
+		"2023-02-13T21:20:30.000000001Z line 1",
+		"2023-02-13T21:20:30.000000002Z line 2",
+		"2023-02-13T21:20:30.000000003Z line 3",
+		"2023-02-13T21:20:30.000000004Z line 4",
+		"2023-02-13T21:20:30.000000004Z line 5",
+		"2023-02-13T21:20:30.000000005Z line 6",
+		"2023-02-13T21:20:31.000000001Z line 7",
+	}
+	tmpl := template.Must(template.New("").Parse(`{{printf "%s\n" .Message}}`))
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+
+	tests := []struct {
+		name             string
+		seen             int
+		maxLinesToSkip   int
+		expectedResume   ResumeRequest
+		expectedReplayed string
+	}{
+		{
+			name:             "unbounded",
+			seen:             5,
+			expectedResume:   ResumeRequest{Timestamp: "2023-02-13T21:20:30Z", LinesToSkip: 5},
+			expectedReplayed: "line 6\nline 7\n",
+		},
+		{
+			name:             "burst exceeding the bound",
+			seen:             5,
+			maxLinesToSkip:   2,
+			expectedResume:   ResumeRequest{Timestamp: "2023-02-13T21:20:30Z", LinesToSkip: 2, NanoTimestamp: "2023-02-13T21:20:30.000000004Z"},
+			expectedReplayed: "line 6\nline 7\n",
+		},
+		{
+			name:             "burst exceeding the bound within a nano timestamp",
+			seen:             4,
+			maxLinesToSkip:   2,
+			expectedResume:   ResumeRequest{Timestamp: "2023-02-13T21:20:30Z", LinesToSkip: 1, NanoTimestamp: "2023-02-13T21:20:30.000000004Z"},
+			expectedReplayed: "line 5\nline 6\nline 7\n",
+		},
+		{
+			name:             "burst within the bound",
+			seen:             2,
+			maxLinesToSkip:   2,
+			expectedResume:   ResumeRequest{Timestamp: "2023-02-13T21:20:30Z", LinesToSkip: 2},
+			expectedReplayed: "line 3\nline 4\nline 5\nline 6\nline 7\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := &TailOptions{MaxLinesToSkip: tt.maxLinesToSkip}
+			tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, io.Discard, io.Discard, options, false, nil, false)
+			for _, line := range burst[:tt.seen] {
+				tail.consumeLine(context.Background(), line)
+			}
+			resumeRequest := tail.GetResumeRequest()
+			if !reflect.DeepEqual(*resumeRequest, tt.expectedResume) {
+				t.Fatalf("expected resume request %+v, got %+v", tt.expectedResume, *resumeRequest)
+			}
+
+			// the resumed tail receives the whole second again
+			out := new(bytes.Buffer)
+			resumed := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, out, io.Discard, options, false, nil, false)
+			resumed.resumeRequest = resumeRequest
+			for _, line := range burst {
+				resumed.consumeLine(context.Background(), line)
+			}
+			if out.String() != tt.expectedReplayed {
+				t.Errorf("expected %q, got %q", tt.expectedReplayed, out.String())
+			}
+		})
+	}
+}
+
+func TestResumeRequestSinceTime(t *testing.T) {
+	past := &ResumeRequest{Timestamp: "2023-02-13T21:20:30Z"}
+	since, clamped, err := past.sinceTime()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if clamped || !since.Time.Equal(time.Date(2023, 2, 13, 21, 20, 30, 0, time.UTC)) {
+		t.Errorf("expected the past timestamp to be kept, got %s (clamped: %v)", since, clamped)
+	}
+
+	before := time.Now()
+	future := &ResumeRequest{Timestamp: before.Add(time.Hour).UTC().Format(time.RFC3339)}
+	since, clamped, err = future.sinceTime()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !clamped || since.Time.Before(before) || since.Time.After(time.Now()) {
+		t.Errorf("expected the future timestamp to be clamped to now, got %s (clamped: %v)", since, clamped)
+	}
+
+	// Resume warns about the clamped timestamp
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	errOut := new(bytes.Buffer)
+	tmpl := template.Must(template.New("").Parse(`{{printf "%s\n" .Message}}`))
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, io.Discard, errOut, &TailOptions{OnlyLogLines: true}, false, nil, false)
+	if err := tail.Resume(context.Background(), future); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if tail.Options.SinceTime == nil || tail.Options.SinceTime.Time.After(time.Now()) {
+		t.Errorf("expected the resume to start from now, got %v", tail.Options.SinceTime)
+	}
+	if !strings.Contains(errOut.String(), "is in the future, resuming from now") {
+		t.Errorf("expected a warning about the future timestamp, got %q", errOut.String())
+	}
+}
+
 func TestRemoveSubsecond(t *testing.T) {
 	tests := []struct {
 		ts       string
@@ -446,3 +590,495 @@ func TestRemoveSubsecond(t *testing.T) {
 		}
 	}
 }
+
+// logsCoreV1 serves data as the logs of every pod
+type logsCoreV1 struct {
+	corev1client.CoreV1Interface
+	data string
+}
+
+func (c *logsCoreV1) Pods(namespace string) corev1client.PodInterface {
+	return &logsPods{PodInterface: c.CoreV1Interface.Pods(namespace), data: c.data}
+}
+
+type logsPods struct {
+	corev1client.PodInterface
+	data string
+}
+
+func (p *logsPods) GetLogs(name string, opts *corev1.PodLogOptions) *rest.Request {
+	client := &fakerest.RESTClient{
+		Resp: &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(p.data))},
+	}
+	return client.Request()
+}
+
+func TestMonotonicTimestampsAcrossResume(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "my-namespace",
+			Name:      "my-pod",
+		},
+	}
+
+	var emitted []*otel.LogRecord
+	newOTelTail := func(logs string, errOut io.Writer) *Tail {
+		clientset := &logsCoreV1{CoreV1Interface: fake.NewSimpleClientset().CoreV1(), data: logs}
+		tail := NewTail(clientset, pod, "my-container", nil, io.Discard, errOut, &TailOptions{MonotonicTimestamps: true}, false, &otel.Exporter{}, true)
+		// a zero window emits the records as they arrive
+		tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {
+			emitted = append(emitted, record)
+		})
+		return tail
+	}
+
+	before := `2023-02-13T21:20:30.000000001Z line 1
+2023-02-13T21:20:30.000000003Z line 2
+`
+	// the resumed stream repeats the second of the last line, with a line of
+	// the other stream before the last emitted one
+	after := `2023-02-13T21:20:30.000000001Z line 1
+2023-02-13T21:20:30.000000003Z line 2
+2023-02-13T21:20:30.000000002Z line 2.5
+2023-02-13T21:20:31.000000001Z line 3
+`
+
+	tail := newOTelTail(before, io.Discard)
+	if err := tail.Start(context.TODO()); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	tail.Close()
+	resumeReq := tail.GetResumeRequest()
+	if resumeReq == nil || resumeReq.LastEmitted.IsZero() {
+		t.Fatalf("expected the resume request to carry the last emitted timestamp, got %+v", resumeReq)
+	}
+
+	errOut := new(bytes.Buffer)
+	resumed := newOTelTail(after, errOut)
+	if err := resumed.Resume(context.TODO(), resumeReq); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+	resumed.Close()
+
+	var bodies []string
+	for i, record := range emitted {
+		bodies = append(bodies, record.Body)
+		if i > 0 && record.Timestamp.Before(emitted[i-1].Timestamp) {
+			t.Errorf("record %q emitted before %q", record.Body, emitted[i-1].Body)
+		}
+	}
+	if expected := []string{"line 1", "line 2", "line 3"}; !reflect.DeepEqual(expected, bodies) {
+		t.Errorf("expected %v, but actual %v", expected, bodies)
+	}
+	if got := resumed.GetResumeRequest().OutOfOrder; got != 1 {
+		t.Errorf("expected 1 dropped record, got %d", got)
+	}
+	if !strings.Contains(errOut.String(), "dropped out-of-order OTel record of my-namespace/my-pod/my-container") {
+		t.Errorf("expected a warning, got %q", errOut.String())
+	}
+}
+
+func TestSeverityColors(t *testing.T) {
+	logLines := `2023-02-13T21:20:30.000000001Z {"level":"error","msg":"failed"}
+2023-02-13T21:20:30.000000002Z {"level":"info","msg":"served"}
+2023-02-13T21:20:31.000000001Z plain text`
+	tmpl := template.Must(template.New("").Parse(`{{printf "%s\n" .Message}}`))
+
+	severityColors, err := ParseSeverityColors(map[string]string{"ERROR": "31"})
+	if err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	orig := color.NoColor
+	color.NoColor = false
+	defer func() { color.NoColor = orig }()
+
+	out := new(bytes.Buffer)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, out, io.Discard, &TailOptions{SeverityColors: severityColors}, false, nil, false)
+	if err := tail.ConsumeRequest(context.TODO(), &responseWrapperMock{data: bytes.NewBufferString(logLines)}); err != nil {
+		t.Fatalf("unexpected err %v", err)
+	}
+
+	expected := "\x1b[31m" + `{"level":"error","msg":"failed"}` + "\x1b[0m\n" +
+		`{"level":"info","msg":"served"}` + "\n" +
+		"plain text\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, but actual %q", expected, out.String())
+	}
+
+	if _, err := ParseSeverityColors(map[string]string{"verbose": "31"}); err == nil {
+		t.Error("expected error for unsupported severity")
+	}
+}
+
+func TestConsumeLineWithoutTimestamp(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tmpl := template.Must(template.New("").Parse(`{{printf "%s\n" .Message}}`))
+	out := new(bytes.Buffer)
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, out, io.Discard, &TailOptions{LineNumbers: true}, false, &otel.Exporter{}, true)
+
+	var emitted []*otel.LogRecord
+	// a zero window emits the records as they arrive
+	tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {
+		emitted = append(emitted, record)
+	})
+
+	tail.consumeLine(context.Background(), "[2025-01-01 12:00:00,5] sidecar started")
+	tail.consumeLine(context.Background(), "ready")
+
+	if expected := "1 [2025-01-01 12:00:00,5] sidecar started\n2 ready\n"; out.String() != expected {
+		t.Errorf("expected %q, but actual %q", expected, out.String())
+	}
+	if len(emitted) != 2 {
+		t.Fatalf("expected 2 records, got %d", len(emitted))
+	}
+	if want := time.Date(2025, 1, 1, 12, 0, 0, 500000000, time.UTC); !emitted[0].Timestamp.Equal(want) || emitted[0].Body != "[2025-01-01 12:00:00,5] sidecar started" {
+		t.Errorf("expected the timestamp of the content, got %s %q", emitted[0].Timestamp, emitted[0].Body)
+	}
+	if emitted[1].Timestamp.IsZero() || emitted[1].Body != "ready" {
+		t.Errorf("expected the current time, got %s %q", emitted[1].Timestamp, emitted[1].Body)
+	}
+	if tail.GetResumeRequest() != nil {
+		t.Error("expected the lines without a timestamp not to be counted to resume")
+	}
+}
+
+func TestConsumeLineStripTimestamps(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tmpl := template.Must(template.New("").Parse(`{{printf "%s\n" .Message}}`))
+	out := new(bytes.Buffer)
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, out, io.Discard, &TailOptions{StripTimestamps: 1}, false, nil, false)
+
+	tail.consumeLine(context.Background(), "2025-01-01T12:00:00.000000001Z 2025-01-01T11:59:59Z sidecar started")
+	tail.consumeLine(context.Background(), "2025-01-01T12:00:00.000000002Z ready")
+
+	if expected := "sidecar started\nready\n"; out.String() != expected {
+		t.Errorf("expected %q, but actual %q", expected, out.String())
+	}
+}
+
+func TestOTelJSON(t *testing.T) {
+	encoder, err := otel.NewJSONEncoder(&otel.TransformConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := new(bytes.Buffer)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", nil, out, io.Discard, &TailOptions{OTelJSON: encoder}, false, nil, false)
+
+	tail.consumeLine(context.Background(), `2023-02-13T21:20:30.000000001Z {"level":"warn","msg":"slow query","duration_ms":1200}`)
+
+	var record struct {
+		Timestamp  string                 `json:"timestamp"`
+		Body       string                 `json:"body"`
+		Attributes map[string]interface{} `json:"attributes"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON object, got %q: %v", out, err)
+	}
+	if record.Timestamp != "2023-02-13T21:20:30.000000001Z" || record.Body != "slow query" {
+		t.Errorf("unexpected record %+v", record)
+	}
+	if record.Attributes["k8s.pod.name"] != "my-pod" || record.Attributes["duration_ms"] != float64(1200) {
+		t.Errorf("unexpected attributes %v", record.Attributes)
+	}
+}
+
+func TestOTelTimezone(t *testing.T) {
+	location := time.FixedZone("EST", -5*60*60)
+	encoder, err := otel.NewJSONEncoder(&otel.TransformConfig{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := new(bytes.Buffer)
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", nil, out, io.Discard, &TailOptions{OTelJSON: encoder, Location: location}, false, &otel.Exporter{}, true)
+	var emitted []*otel.LogRecord
+	tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {
+		emitted = append(emitted, record)
+	})
+
+	tail.consumeLine(context.Background(), "2023-02-13T21:20:30.000000001Z ready")
+
+	if len(emitted) != 1 {
+		t.Fatalf("expected 1 record, got %d", len(emitted))
+	}
+	expected := time.Date(2023, 2, 13, 21, 20, 30, 1, time.UTC)
+	if got := emitted[0].Timestamp; !got.Equal(expected) || got.Location() != location {
+		t.Errorf("expected %v in %v, got %v", expected, location, got)
+	}
+
+	var record struct {
+		Timestamp         string `json:"timestamp"`
+		ObservedTimestamp string `json:"observedTimestamp"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("expected a JSON object, got %q: %v", out, err)
+	}
+	if record.Timestamp != "2023-02-13T16:20:30.000000001-05:00" {
+		t.Errorf("expected the timestamp in the location, got %q", record.Timestamp)
+	}
+	if !strings.HasSuffix(record.ObservedTimestamp, "-05:00") {
+		t.Errorf("expected the observed timestamp in the location, got %q", record.ObservedTimestamp)
+	}
+}
+
+func TestLineNumbers(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tmpl := template.Must(template.New("").Parse(`{{printf "%s\n" .Message}}`))
+	out := new(bytes.Buffer)
+	options := &TailOptions{LineNumbers: true, Exclude: []*regexp.Regexp{regexp.MustCompile("noise")}}
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", tmpl, out, io.Discard, options, false, &otel.Exporter{}, true)
+
+	var emitted []*otel.LogRecord
+	// a zero window emits the records as they arrive
+	tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {
+		emitted = append(emitted, record)
+	})
+
+	tail.consumeLine(context.Background(), "2023-02-13T21:20:30.000000001Z line 1")
+	tail.consumeLine(context.Background(), "2023-02-13T21:20:30.000000002Z noise")
+	tail.consumeLine(context.Background(), "2023-02-13T21:20:31.000000001Z line 2")
+
+	stdout := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(stdout) != 2 || len(emitted) != 2 {
+		t.Fatalf("expected 2 lines on both streams, got %q and %d records", stdout, len(emitted))
+	}
+	for i, record := range emitted {
+		number, message, _ := strings.Cut(stdout[i], " ")
+		if number != strconv.FormatInt(record.LineNumber, 10) {
+			t.Errorf("%d: expected line number %d on stdout, got %q", i, record.LineNumber, stdout[i])
+		}
+		if message != record.Body {
+			t.Errorf("%d: expected %q on stdout, got %q", i, record.Body, message)
+		}
+		if record.LineNumber != int64(i+1) {
+			t.Errorf("%d: expected line number %d, got %d", i, i+1, record.LineNumber)
+		}
+	}
+}
+
+func TestHeartbeat(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", nil, io.Discard, io.Discard, &TailOptions{HeartbeatInterval: 50 * time.Millisecond}, false, &otel.Exporter{}, true)
+
+	heartbeats := make(chan *otel.LogRecord, 10)
+	// a zero window emits the records as they arrive
+	tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {
+		heartbeats <- record
+	})
+
+	start := time.Now()
+	tail.startHeartbeat()
+	defer tail.stopHeartbeat()
+
+	select {
+	case record := <-heartbeats:
+		if !record.Heartbeat {
+			t.Errorf("expected a heartbeat record, got %q", record.Body)
+		}
+		if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+			t.Errorf("expected the heartbeat after the idle interval, got it after %s", elapsed)
+		}
+		if record.LastSeen.Before(start) || record.LastSeen.After(record.Timestamp) {
+			t.Errorf("expected the last seen time between the start and the heartbeat, got %s", record.LastSeen)
+		}
+		if record.PodName != "my-pod" || record.ContainerName != "my-container" {
+			t.Errorf("expected the heartbeat of my-pod/my-container, got %s/%s", record.PodName, record.ContainerName)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected a heartbeat after the idle interval")
+	}
+
+	tail.stopHeartbeat()
+	// drain a heartbeat that raced with the stop
+	for len(heartbeats) > 0 {
+		<-heartbeats
+	}
+	select {
+	case <-heartbeats:
+		t.Error("expected no heartbeat once stopped")
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestHeartbeatResetsOnLine(t *testing.T) {
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+	tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, "my-container", nil, io.Discard, io.Discard, &TailOptions{HeartbeatInterval: 200 * time.Millisecond}, false, &otel.Exporter{}, true)
+
+	var mu sync.Mutex
+	var heartbeats int
+	tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {
+		if record.Heartbeat {
+			mu.Lock()
+			heartbeats++
+			mu.Unlock()
+		}
+	})
+
+	tail.startHeartbeat()
+	defer tail.stopHeartbeat()
+
+	// lines arriving within the interval keep the container from being idle
+	for i := 0; i < 6; i++ {
+		time.Sleep(50 * time.Millisecond)
+		tail.consumeLine(context.Background(), fmt.Sprintf("2023-02-13T21:20:30.00000000%dZ line %d", i, i))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if heartbeats != 0 {
+		t.Errorf("expected no heartbeat while lines arrive, got %d", heartbeats)
+	}
+}
+
+func TestQOSClassAndPriority(t *testing.T) {
+	priority := int32(1000)
+	tests := []struct {
+		name             string
+		pod              *corev1.Pod
+		expectedQOSClass string
+		expectedPriority *int32
+	}{
+		{
+			name: "guaranteed pod",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"},
+				Spec:       corev1.PodSpec{Priority: &priority},
+				Status:     corev1.PodStatus{QOSClass: corev1.PodQOSGuaranteed},
+			},
+			expectedQOSClass: "Guaranteed",
+			expectedPriority: &priority,
+		},
+		{
+			name: "pod without QoS class",
+			pod:  &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tail := NewTail(fake.NewSimpleClientset().CoreV1(), tt.pod, "my-container", nil, io.Discard, io.Discard, &TailOptions{}, false, &otel.Exporter{}, true)
+			var emitted []*otel.LogRecord
+			// a zero window emits the records as they arrive
+			tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {
+				emitted = append(emitted, record)
+			})
+
+			// later changes to the pod do not affect the tail
+			tt.pod.Status.QOSClass = corev1.PodQOSBestEffort
+
+			tail.consumeLine(context.Background(), "2023-02-13T21:20:30.000000001Z line 1")
+			if len(emitted) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(emitted))
+			}
+			if emitted[0].QOSClass != tt.expectedQOSClass {
+				t.Errorf("expected QoS class %q, got %q", tt.expectedQOSClass, emitted[0].QOSClass)
+			}
+			if !reflect.DeepEqual(emitted[0].Priority, tt.expectedPriority) {
+				t.Errorf("expected priority %v, got %v", tt.expectedPriority, emitted[0].Priority)
+			}
+		})
+	}
+}
+
+func TestContainerStatus(t *testing.T) {
+	appRestarts, initRestarts := int32(3), int32(1)
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"},
+		Status: corev1.PodStatus{
+			InitContainerStatuses: []corev1.ContainerStatus{{Name: "init", Image: "busybox:1.36", RestartCount: 1}},
+			ContainerStatuses:     []corev1.ContainerStatus{{Name: "app", Image: "app:v2", ImageID: "app@sha256:abc", RestartCount: 3}},
+		},
+	}
+
+	for _, tt := range []struct {
+		container        string
+		expectedImage    string
+		expectedRestarts *int32
+	}{
+		{container: "app", expectedImage: "app:v2", expectedRestarts: &appRestarts},
+		{container: "init", expectedImage: "busybox:1.36", expectedRestarts: &initRestarts},
+		// the kubelet has not reported the status yet
+		{container: "pending"},
+	} {
+		t.Run(tt.container, func(t *testing.T) {
+			tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, tt.container, nil, io.Discard, io.Discard, &TailOptions{}, false, &otel.Exporter{}, true)
+			var emitted []*otel.LogRecord
+			tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {
+				emitted = append(emitted, record)
+			})
+
+			tail.consumeLine(context.Background(), "2023-02-13T21:20:30.000000001Z line 1")
+			if len(emitted) != 1 {
+				t.Fatalf("expected 1 record, got %d", len(emitted))
+			}
+			if emitted[0].Image != tt.expectedImage {
+				t.Errorf("expected image %q, got %q", tt.expectedImage, emitted[0].Image)
+			}
+			if !reflect.DeepEqual(emitted[0].RestartCount, tt.expectedRestarts) {
+				t.Errorf("expected restart count %v, got %v", tt.expectedRestarts, emitted[0].RestartCount)
+			}
+		})
+	}
+}
+
+func TestTailCounter(t *testing.T) {
+	counter := &tailCounter{}
+	var emitted []*otel.LogRecord
+	newCountedTail := func(container string) *Tail {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "my-namespace", Name: "my-pod"}}
+		tail := NewTail(fake.NewSimpleClientset().CoreV1(), pod, container, nil, io.Discard, io.Discard, &TailOptions{}, false, &otel.Exporter{}, true)
+		tail.tailCounter = counter
+		// a zero window emits the records as they arrive
+		tail.orderer = newPodOrderer(0, func(record *otel.LogRecord) {
+			emitted = append(emitted, record)
+		})
+		return tail
+	}
+
+	tail1, tail2 := newCountedTail("container-1"), newCountedTail("container-2")
+	if err := tail1.Start(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := counter.count(); got != 1 {
+		t.Errorf("expected 1 tailed container, got %d", got)
+	}
+	if err := tail2.Start(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := counter.count(); got != 2 {
+		t.Errorf("expected 2 tailed containers, got %d", got)
+	}
+
+	tail2.consumeLine(context.Background(), "2023-02-13T21:20:30.000000001Z line 1")
+	if len(emitted) == 0 || emitted[len(emitted)-1].TailedContainers != 2 {
+		t.Errorf("expected the record to carry 2 tailed containers")
+	}
+
+	tail1.Close()
+	if got := counter.count(); got != 1 {
+		t.Errorf("expected 1 tailed container after a stop, got %d", got)
+	}
+	tail2.Close()
+	if got := counter.count(); got != 0 {
+		t.Errorf("expected no tailed container after all stops, got %d", got)
+	}
+
+	// a tail closed without starting is not uncounted
+	newCountedTail("container-3").Close()
+	if got := counter.count(); got != 0 {
+		t.Errorf("expected no tailed container, got %d", got)
+	}
+
+	// nor counted when it starts after it was closed
+	tail4 := newCountedTail("container-4")
+	tail4.Close()
+	if err := tail4.Start(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := counter.count(); got != 0 {
+		t.Errorf("expected no tailed container, got %d", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/fatih/color"
+	"github.com/stern/stern/stern/otel"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -52,8 +53,52 @@ type TailOptions struct {
 	Namespace    bool
 	TailLines    *int64
 	Follow       bool
+	Previous     bool
 	OnlyLogLines bool
 
+	// EmitMatches attaches the substrings matched by Include to OTel records
+	EmitMatches bool
+	// MonotonicTimestamps drops OTel records older than the last one emitted
+	// for the container
+	MonotonicTimestamps bool
+	// SeverityColors colors whole lines by the level of structured logs
+	SeverityColors map[string]*color.Color
+	// HeartbeatInterval emits a stern.heartbeat OTel record when the
+	// container has been silent for the interval. 0 disables heartbeats.
+	HeartbeatInterval time.Duration
+	// MaxLinesToSkip bounds the lines of the last second counted to resume,
+	// keying the resume by the nano timestamp of the last line beyond it.
+	// 0 counts all the lines of the second.
+	MaxLinesToSkip int
+	// LineNumbers numbers the lines matching the filters, on stdout and as
+	// the stern.line_no OTel attribute, to correlate both streams. Lines are
+	// printed on stdout even when OTel is enabled.
+	LineNumbers bool
+	// StripTimestamps removes up to this many timestamps leading the content
+	// of the lines after the kubelet timestamp, e.g. the one of a sidecar
+	// teeing the logs. A token is only removed when it parses as one.
+	StripTimestamps int
+	// RepeatWindow collapses the repeats of a line within the window after
+	// its first occurrence into a "(last message repeated N times)" line,
+	// or an OTel record with log.repeat_count. 0 disables it.
+	RepeatWindow time.Duration
+	// MultilineStart matches the lines starting an OTel record, the other
+	// lines continuing it, e.g. stack frames. MultilineContinue matches the
+	// lines continuing the record instead. Nil for both disables joining.
+	MultilineStart    *regexp.Regexp
+	MultilineContinue *regexp.Regexp
+	// MultilineTimeout emits a joined record when no line continued it for
+	// the timeout. 0 waits for the next record or the end of the tail.
+	MultilineTimeout time.Duration
+	// MultilinePreserveNewline keeps the trailing newlines of joined bodies
+	MultilinePreserveNewline bool
+	// OTelJSON prints each line as the JSON object of its OTel record in
+	// place of the template, when set
+	OTelJSON *otel.JSONEncoder
+	// Quiet prints nothing on errOut for the containers tailed when OTel is
+	// enabled. Otherwise a line summarizes each container as it starts.
+	Quiet bool
+
 	// regexp for highlighting the matched string
 	reHightlight *regexp.Regexp
 }
@@ -111,6 +156,115 @@ func (o TailOptions) HighlightMatchedString(msg string) string {
 	return msg
 }
 
+// MatchedStrings returns the unique substrings of msg matched by the include
+// patterns, in order of appearance
+func (o TailOptions) MatchedStrings(msg string) []string {
+	if len(o.Include) == 0 {
+		return nil
+	}
+
+	type span struct {
+		start int
+		text  string
+	}
+	var spans []span
+	seen := make(map[string]struct{})
+	for _, rin := range o.Include {
+		for _, loc := range rin.FindAllStringIndex(msg, -1) {
+			text := msg[loc[0]:loc[1]]
+			if _, ok := seen[text]; ok || text == "" {
+				continue
+			}
+			seen[text] = struct{}{}
+			spans = append(spans, span{start: loc[0], text: text})
+		}
+	}
+	sort.SliceStable(spans, func(i, j int) bool {
+		return spans[i].start < spans[j].start
+	})
+
+	matches := make([]string, len(spans))
+	for i, s := range spans {
+		matches[i] = s.text
+	}
+	return matches
+}
+
+// SeverityColor returns the color configured for the level of msg, or nil
+func (o TailOptions) SeverityColor(msg string) *color.Color {
+	if len(o.SeverityColors) == 0 {
+		return nil
+	}
+	return o.SeverityColors[severityLevelOf(otel.SeverityOf(msg))]
+}
+
+// colorLine applies c to the line, keeping the trailing newline uncolored
+func colorLine(line string, c *color.Color) string {
+	if c == nil {
+		return line
+	}
+	if body, ok := strings.CutSuffix(line, "\n"); ok {
+		return c.Sprint(body) + "\n"
+	}
+	return c.Sprint(line)
+}
+
+// contentTimestampLayouts are the layouts of the timestamps leading the
+// lines without a kubelet timestamp. Fractional seconds are parsed too.
+var contentTimestampLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+}
+
+// contentTimestamp returns the timestamp leading the line, in one or two
+// fields and possibly bracketed, e.g. "[2025-01-01 12:00:00,123] message",
+// or now when none parses. Timestamps without a zone are in UTC.
+func contentTimestamp(line string) time.Time {
+	fields := strings.SplitN(line, " ", 3)
+	candidates := []string{fields[0]}
+	if len(fields) > 1 {
+		candidates = append(candidates, fields[0]+" "+fields[1])
+	}
+	for _, candidate := range candidates {
+		candidate = strings.TrimSuffix(strings.TrimPrefix(candidate, "["), "]")
+		for _, layout := range contentTimestampLayouts {
+			if ts, err := time.Parse(layout, candidate); err == nil {
+				return ts
+			}
+		}
+	}
+	return time.Now()
+}
+
+// stripTimestamps removes up to n RFC3339 timestamps leading the content,
+// possibly bracketed, e.g. "2025-01-01T12:00:00Z message". It stops at the
+// first token that does not parse as a timestamp.
+func stripTimestamps(content string, n int) string {
+	for ; n > 0; n-- {
+		token, rest, ok := strings.Cut(content, " ")
+		if !ok {
+			break
+		}
+		token = strings.TrimSuffix(strings.TrimPrefix(token, "["), "]")
+		if _, err := time.Parse(time.RFC3339, token); err != nil {
+			break
+		}
+		content = rest
+	}
+	return content
+}
+
+// inLocation returns the timestamp in the location of the printed
+// timestamps, if any, so that the OTel records agree with them
+func (o TailOptions) inLocation(timestamp time.Time) time.Time {
+	if o.Location == nil {
+		return timestamp
+	}
+	return timestamp.In(o.Location)
+}
+
 func (o TailOptions) UpdateTimezoneAndFormat(timestamp string) (string, error) {
 	t, err := time.ParseInLocation(time.RFC3339Nano, timestamp, time.UTC)
 	if err != nil {
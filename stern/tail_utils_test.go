@@ -115,6 +115,54 @@ func TestUpdateTimezoneAndFormat(t *testing.T) {
 	}
 }
 
+func TestContentTimestamp(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected time.Time
+	}{
+		{"2025-01-01T12:00:00.5+02:00 started", time.Date(2025, 1, 1, 10, 0, 0, 500000000, time.UTC)},
+		{"2025-01-01T12:00:00Z", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"2025-01-01 12:00:00,123 INFO started", time.Date(2025, 1, 1, 12, 0, 0, 123000000, time.UTC)},
+		{"[2025-01-01 12:00:00] started", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+		{"[2025-01-01T12:00:00] started", time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := contentTimestamp(tt.line); !got.Equal(tt.expected) {
+			t.Errorf("%q: expected %s, got %s", tt.line, tt.expected, got)
+		}
+	}
+
+	before := time.Now()
+	if got := contentTimestamp("no timestamp here"); got.Before(before) {
+		t.Errorf("expected now, got %s", got)
+	}
+}
+
+func TestStripTimestamps(t *testing.T) {
+	tests := []struct {
+		content  string
+		n        int
+		expected string
+	}{
+		{"2025-01-01T12:00:00.5Z started", 1, "started"},
+		{"[2025-01-01T12:00:00+02:00] started", 1, "started"},
+		{"2025-01-01T12:00:00Z 2025-01-01T12:00:01Z started", 1, "2025-01-01T12:00:01Z started"},
+		{"2025-01-01T12:00:00Z 2025-01-01T12:00:01Z started", 2, "started"},
+		{"2025-01-01T12:00:00Z started", 0, "2025-01-01T12:00:00Z started"},
+		// Only the tokens parsing as timestamps are removed
+		{"2025-01-01T12:00:00Z started", 3, "started"},
+		{"INFO 2025-01-01T12:00:00Z started", 1, "INFO 2025-01-01T12:00:00Z started"},
+		{"2025-01-01T12:00:00Z", 1, "2025-01-01T12:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		if got := stripTimestamps(tt.content, tt.n); got != tt.expected {
+			t.Errorf("%q (%d): expected %q, got %q", tt.content, tt.n, tt.expected, got)
+		}
+	}
+}
+
 func TestHighlighIncludedString(t *testing.T) {
 	tests := []struct {
 		msg      string
@@ -316,3 +364,37 @@ func TestHighlightMatchedString(t *testing.T) {
 		}
 	}
 }
+
+func TestMatchedStrings(t *testing.T) {
+	msg := "error: connection refused, retrying after error"
+
+	tests := []struct {
+		include  []*regexp.Regexp
+		expected []string
+	}{
+		{
+			include:  nil,
+			expected: nil,
+		},
+		{
+			include:  []*regexp.Regexp{regexp.MustCompile(`error`)},
+			expected: []string{"error"},
+		},
+		{
+			include:  []*regexp.Regexp{regexp.MustCompile(`retry\w*`), regexp.MustCompile(`conn\w+`)},
+			expected: []string{"connection", "retrying"},
+		},
+		{
+			include:  []*regexp.Regexp{regexp.MustCompile(`timeout`)},
+			expected: []string{},
+		},
+	}
+
+	for i, tt := range tests {
+		o := TailOptions{Include: tt.include}
+		actual := o.MatchedStrings(msg)
+		if fmt.Sprint(actual) != fmt.Sprint(tt.expected) {
+			t.Errorf("%d: expected %v, but actual %v", i, tt.expected, actual)
+		}
+	}
+}